@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/skanehira/docui/docker"
+)
+
+// runCleanup evaluate the policy and remove matched resources without the ui, it is intended for cron.
+// it returns the exit code.
+func runCleanup(engine docker.Engine, policyFile string, dryRun bool, out, errOut io.Writer) int {
+	if policyFile == "" {
+		fmt.Fprintln(errOut, "-cleanup requires -policy")
+		return 2
+	}
+
+	policy, err := docker.LoadCleanupPolicy(policyFile)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	resources, err := docker.ListCleanupResources(engine)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	items, err := policy.Evaluate(resources, time.Now())
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tNAME\tCREATED\tRULE\tRESULT")
+
+	failed := 0
+	for _, item := range items {
+		result := "would remove"
+		if !dryRun {
+			result = "removed"
+			if err := docker.RemoveCleanupItem(engine, item); err != nil {
+				result = "error: " + err.Error()
+				failed++
+			}
+		}
+
+		created := "-"
+		if !item.Created.IsZero() {
+			created = item.Created.Format("2006/01/02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Resource, item.Name, created, item.Rule, result)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		fmt.Fprintf(errOut, "cannot remove %d of %d resources\n", failed, len(items))
+		return 1
+	}
+	return 0
+}
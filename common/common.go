@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/go-units"
 )
 
 var cutNewlineReplacer = strings.NewReplacer("\r", "", "\n", "")
@@ -35,7 +36,7 @@ func SortKeys(keys []string) []string {
 // GetOSenv get os environment.
 func GetOSenv(env string) string {
 	keyval := strings.SplitN(env, "=", 2)
-	if keyval[1][:1] == "$" {
+	if len(keyval) == 2 && strings.HasPrefix(keyval[1], "$") {
 		keyval[1] = os.Getenv(keyval[1][1:])
 		return strings.Join(keyval, "=")
 	}
@@ -49,12 +50,22 @@ func ParseDateToString(unixtime int64) string {
 	return t.Format("2006/01/02 15:04:05")
 }
 
+// ParseAgeToString parse date to elapsed time string, e.g. "3 weeks ago".
+func ParseAgeToString(unixtime int64) string {
+	return units.HumanDuration(time.Since(time.Unix(unixtime, 0))) + " ago"
+}
+
 // ParseSizeToString parse size to string.
 func ParseSizeToString(size int64) string {
 	mb := float64(size) / 1024 / 1024
 	return fmt.Sprintf("%.1fMB", mb)
 }
 
+// ParseBytesToString parse bytes to human readable string.
+func ParseBytesToString(size uint64) string {
+	return units.BytesSize(float64(size))
+}
+
 // ParsePortToString parse port to string.
 func ParsePortToString(ports []types.Port) string {
 	var port string
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"errors"
+	"strings"
+)
+
+// SplitShellWords split s into words like POSIX shell.
+// single quotes, double quotes and backslash escapes are supported,
+// but variables and globs are not expanded.
+func SplitShellWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			i++
+			if i == len(runes) {
+				return nil, errors.New("unterminated backslash")
+			}
+			// backslash newline is line continuation
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case r == '\'':
+			i++
+			for ; i < len(runes) && runes[i] != '\''; i++ {
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("unterminated single quote")
+			}
+			inWord = true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				// backslash escapes only these in double quotes
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// shellSafe characters which don't need quoting
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,"
+
+// QuoteShellWord quote the word if needed to be used in shell
+func QuoteShellWord(word string) string {
+	if word == "" {
+		return "''"
+	}
+
+	if strings.Trim(word, shellSafe) == "" {
+		return word
+	}
+
+	return "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+}
+
+// JoinShellWords join words into a shell command line
+func JoinShellWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = QuoteShellWord(w)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		err  bool
+	}{
+		{in: `sh -c "echo hello world"`, want: []string{"sh", "-c", "echo hello world"}},
+		{in: `echo 'it''s' "a \"b\" \n" c\ d`, want: []string{"echo", "its", `a "b" \n`, "c d"}},
+		{in: "  ls \\\n  -l ''", want: []string{"ls", "-l", ""}},
+		{in: "", want: nil},
+		{in: `echo "unterminated`, err: true},
+		{in: `echo 'unterminated`, err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := SplitShellWords(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Expected error for %q. Got %q.", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %q. Got %q (%v).", tt.want, got, err)
+		}
+	}
+}
+
+func TestJoinShellWords(t *testing.T) {
+	words := []string{"sh", "-c", "echo it's $HOME", "a=b", ""}
+	got := JoinShellWords(words)
+	if want := `sh -c 'echo it'\''s $HOME' a=b ''`; got != want {
+		t.Errorf("Expected %s. Got %s.", want, got)
+	}
+
+	split, err := SplitShellWords(got)
+	if err != nil || !reflect.DeepEqual(split, words) {
+		t.Errorf("Expected round trip %q. Got %q (%v).", words, split, err)
+	}
+}
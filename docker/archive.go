@@ -0,0 +1,235 @@
+package docker
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// progressCounter count copied bytes and report them at most once per progressInterval
+type progressCounter struct {
+	total    int64
+	last     time.Time
+	progress func(int64)
+}
+
+func newProgressCounter(progress func(int64)) *progressCounter {
+	if progress == nil {
+		progress = func(int64) {}
+	}
+	return &progressCounter{progress: progress}
+}
+
+func (c *progressCounter) Write(p []byte) (int, error) {
+	c.total += int64(len(p))
+	if now := time.Now(); now.Sub(c.last) >= progressInterval {
+		c.last = now
+		c.progress(c.total)
+	}
+	return len(p), nil
+}
+
+// done report the total copied bytes
+func (c *progressCounter) done() {
+	c.progress(c.total)
+}
+
+// writeTar write src file or directory into tar stream w.
+// names in the archive start with the base name of src.
+func writeTar(w io.Writer, src string, progress func(int64)) error {
+	src = filepath.Clean(src)
+	tw := tar.NewWriter(w)
+	counter := newProgressCounter(progress)
+
+	if err := walkTar(tw, filepath.Dir(src), src, nil, counter); err != nil {
+		return err
+	}
+
+	counter.done()
+	return tw.Close()
+}
+
+// walkTar write files under src into tw, names are relative to base.
+// include decides whether the file is written, and it can return filepath.SkipDir.
+func walkTar(tw *tar.Writer, base, src string, include func(rel string, info os.FileInfo) (bool, error), counter *progressCounter) error {
+	return filepath.Walk(src, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(base, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		if include != nil {
+			ok, err := include(filepath.ToSlash(rel), info)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(io.MultiWriter(tw, counter), f)
+		return err
+	})
+}
+
+// extractTar extract tar stream r like docker cp.
+// when dst is an existing directory, files are extracted into it,
+// otherwise the top of the archive is renamed to dst.
+func extractTar(r io.Reader, dst string, progress func(int64)) error {
+	counter := newProgressCounter(progress)
+
+	dir, rename := dst, ""
+	if info, err := os.Stat(dst); err != nil || !info.IsDir() {
+		dir, rename = filepath.Dir(dst), filepath.Base(dst)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := safeJoin(dir, renameTop(hdr.Name, rename))
+		if err != nil {
+			return err
+		}
+
+		mode := hdr.FileInfo().Mode()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode.Perm()|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			// the file is replaced instead of writing through the symlink
+			if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				os.Remove(target)
+			}
+			if err := writeFile(target, tr, mode.Perm(), counter); err != nil {
+				return err
+			}
+			os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+		case tar.TypeSymlink:
+			os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			source, err := safeJoin(dir, renameTop(hdr.Linkname, rename))
+			if err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+		default:
+			// devices and fifos can't be created without privileges
+			continue
+		}
+	}
+
+	counter.done()
+	return nil
+}
+
+// renameTop replace the first element of name in the archive with rename
+func renameTop(name, rename string) string {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if rename == "" {
+		return name
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	parts[0] = rename
+	return strings.Join(parts, "/")
+}
+
+func writeFile(target string, r io.Reader, perm os.FileMode, counter *progressCounter) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(io.MultiWriter(f, counter), r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// safeJoin join name to dir, and refuse names which point outside of dir.
+// names under symlinks are refused too, because an earlier entry of the archive
+// can make a symlink like "dir/link -> /etc" to write "dir/link/passwd" outside of dir.
+func safeJoin(dir, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path in archive: %s", name)
+	}
+
+	parent := dir
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+		info, err := os.Lstat(parent)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("invalid path in archive: %s is under a symlink", name)
+		}
+	}
+	return target, nil
+}
@@ -0,0 +1,186 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTarRoundTrip(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	ioutil.WriteFile(filepath.Join(src, "a.txt"), []byte("hello"), 0600)
+	ioutil.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("world"), 0644)
+
+	var buf bytes.Buffer
+	var copied int64
+	if err := writeTar(&buf, src, func(n int64) { copied = n }); err != nil {
+		t.Fatal(err)
+	}
+	if copied != 10 {
+		t.Errorf("Expected 10 bytes. Got %d.", copied)
+	}
+
+	// dst doesn't exist, so the top directory is renamed
+	dst := filepath.Join(tmp, "dst")
+	if err := extractTar(bytes.NewReader(buf.Bytes()), dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dst, "sub", "b.txt"))
+	if err != nil || string(data) != "world" {
+		t.Errorf("Expected world. Got %q (%v).", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "a.txt")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600. Got %v (%v).", info, err)
+	}
+
+	// dst is an existing directory, so files are extracted into it
+	if err := extractTar(bytes.NewReader(buf.Bytes()), dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "src", "a.txt")); err != nil {
+		t.Errorf("Expected extracted into directory. Got %v.", err)
+	}
+}
+
+func TestExtractTarRefuseTraversal(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "x/../../evil", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+	tw.Write([]byte("x"))
+	tw.Close()
+
+	if err := extractTar(&buf, tmp, nil); err == nil {
+		t.Error("Expected error for path outside of dst.")
+	}
+}
+
+func TestExtractTarRefuseSymlinkTraversal(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	outside := filepath.Join(tmp, "outside")
+	dst := filepath.Join(tmp, "dst")
+	for _, dir := range []string{outside, dst} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(outside, "passwd"), []byte("root"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"through directory", []*tar.Header{
+			{Name: "dir/", Mode: 0755, Typeflag: tar.TypeDir},
+			{Name: "dir/link", Linkname: outside, Typeflag: tar.TypeSymlink},
+			{Name: "dir/link/passwd", Mode: 0644, Size: 1, Typeflag: tar.TypeReg},
+		}},
+		{"relative link", []*tar.Header{
+			{Name: "up", Linkname: "../outside", Typeflag: tar.TypeSymlink},
+			{Name: "up/passwd", Mode: 0644, Size: 1, Typeflag: tar.TypeReg},
+		}},
+		{"hard link", []*tar.Header{
+			{Name: "up", Linkname: "../outside", Typeflag: tar.TypeSymlink},
+			{Name: "passwd", Linkname: "up/passwd", Typeflag: tar.TypeLink},
+		}},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, hdr := range tt.headers {
+			tw.WriteHeader(hdr)
+			if hdr.Size > 0 {
+				tw.Write([]byte("x"))
+			}
+		}
+		tw.Close()
+
+		if err := extractTar(&buf, dst, nil); err == nil {
+			t.Errorf("%s: expected error for path under symlink.", tt.name)
+		}
+		if b, _ := ioutil.ReadFile(filepath.Join(outside, "passwd")); string(b) != "root" {
+			t.Errorf("%s: expected file outside of dst is not changed. Got %q.", tt.name, b)
+		}
+	}
+
+	// a symlink of the file is replaced
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "file", Linkname: filepath.Join(outside, "passwd"), Typeflag: tar.TypeSymlink})
+	tw.WriteHeader(&tar.Header{Name: "file", Mode: 0644, Size: 1, Typeflag: tar.TypeReg})
+	tw.Write([]byte("x"))
+	tw.Close()
+	if err := extractTar(&buf, dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(outside, "passwd")); string(b) != "root" {
+		t.Errorf("Expected file outside of dst is not changed. Got %q.", b)
+	}
+}
+
+func TestListDir(t *testing.T) {
+	fake := NewFake()
+	fake.Files["abc"] = map[string]string{
+		"/etc/hosts":         "127.0.0.1 localhost",
+		"/etc/nginx/a.conf":  "server {}",
+		"/etc/nginx/b/c.txt": "c",
+		"/root/.profile":     "",
+	}
+
+	entries, err := fake.ListDir(context.TODO(), "abc", "/etc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "nginx" || !entries[0].IsDir() || entries[1].Name != "hosts" {
+		t.Errorf("Expected nginx/ and hosts. Got %+v.", entries)
+	}
+
+	entries, err = fake.ListDir(context.TODO(), "abc", "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "etc" || entries[1].Name != "root" {
+		t.Errorf("Expected etc and root. Got %+v.", entries)
+	}
+
+	data, truncated, err := fake.ReadFile(context.TODO(), "abc", "/etc/hosts", 9)
+	if err != nil || string(data) != "127.0.0.1" || !truncated {
+		t.Errorf("Expected truncated content. Got %q %v (%v).", data, truncated, err)
+	}
+}
+
+func TestParseFindNames(t *testing.T) {
+	out := "/etc/hosts\x00/etc/two\nlines\x00/etc/nginx\x00"
+	want := []string{"hosts", "two\nlines", "nginx"}
+	if got := parseFindNames(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q. Got %q.", want, got)
+	}
+	if got := parseFindNames(""); len(got) != 0 {
+		t.Errorf("Expected no names. Got %q.", got)
+	}
+}
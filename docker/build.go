@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/builder/dockerignore"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// outsideDockerfile name of the Dockerfile in the context when it is outside of the context directory
+const outsideDockerfile = ".docui.Dockerfile"
+
+// BuildOptions image build options
+type BuildOptions struct {
+	ContextDir string
+	// Dockerfile path of Dockerfile, relative path is from ContextDir
+	Dockerfile string
+	Tags       []string
+	BuildArgs  map[string]string
+	Target     string
+	NoCache    bool
+	Labels     map[string]string
+}
+
+// dockerfilePath return path of the Dockerfile on the host
+func (opt BuildOptions) dockerfilePath() string {
+	dockerfile := opt.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(opt.ContextDir, dockerfile)
+	}
+	return filepath.Clean(dockerfile)
+}
+
+// BuildImage build the image, and call output for each line of the build output
+func (d *Docker) BuildImage(ctx context.Context, opt BuildOptions, output func(string)) error {
+	dockerfile, err := contextDockerfile(opt)
+	if err != nil {
+		return err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeBuildContext(writer, opt))
+	}()
+	defer reader.Close()
+
+	buildArgs := make(map[string]*string)
+	for k, v := range opt.BuildArgs {
+		v := v
+		buildArgs[k] = &v
+	}
+
+	resp, err := d.ImageBuild(ctx, reader, types.ImageBuildOptions{
+		Tags:        opt.Tags,
+		Dockerfile:  dockerfile,
+		BuildArgs:   buildArgs,
+		Target:      opt.Target,
+		NoCache:     opt.NoCache,
+		Labels:      opt.Labels,
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := decodeBuildStream(resp.Body, output); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// contextDockerfile return the Dockerfile name in the build context
+func contextDockerfile(opt BuildOptions) (string, error) {
+	info, err := os.Stat(opt.ContextDir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", opt.ContextDir)
+	}
+
+	rel, err := filepath.Rel(opt.ContextDir, opt.dockerfilePath())
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return outsideDockerfile, nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// writeBuildContext write the context directory into tar stream w honoring .dockerignore.
+// the Dockerfile and .dockerignore are always included like docker build.
+func writeBuildContext(w io.Writer, opt BuildOptions) error {
+	dockerfile, err := contextDockerfile(opt)
+	if err != nil {
+		return err
+	}
+
+	var excludes []string
+	if f, err := os.Open(filepath.Join(opt.ContextDir, ".dockerignore")); err == nil {
+		excludes, err = dockerignore.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	matcher, err := fileutils.NewPatternMatcher(excludes)
+	if err != nil {
+		return err
+	}
+
+	include := func(rel string, info os.FileInfo) (bool, error) {
+		if rel == dockerfile || rel == ".dockerignore" {
+			return true, nil
+		}
+
+		skip, err := matcher.Matches(rel)
+		if err != nil {
+			return false, err
+		}
+		if !skip {
+			return true, nil
+		}
+
+		// files in the directory can be included again by exclusion patterns
+		if info.IsDir() && !matcher.Exclusions() {
+			return false, filepath.SkipDir
+		}
+		return false, nil
+	}
+
+	tw := tar.NewWriter(w)
+	counter := newProgressCounter(nil)
+	if err := walkTar(tw, opt.ContextDir, opt.ContextDir, include, counter); err != nil {
+		return err
+	}
+
+	if dockerfile == outsideDockerfile {
+		data, err := ioutil.ReadFile(opt.dockerfilePath())
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: outsideDockerfile, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// decodeBuildStream decode image build json messages and call output for each line.
+// error message in the stream is returned as error.
+func decodeBuildStream(r io.Reader, output func(string)) error {
+	dec := json.NewDecoder(r)
+	var partial string
+
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if msg.ErrorMessage != "" {
+			return errors.New(msg.ErrorMessage)
+		}
+
+		switch {
+		case msg.Stream != "":
+			// a line can be split into some messages
+			lines := strings.Split(partial+msg.Stream, "\n")
+			partial = lines[len(lines)-1]
+			for _, line := range lines[:len(lines)-1] {
+				output(strings.TrimSuffix(line, "\r"))
+			}
+		case msg.Status != "":
+			// download progress of base images is too noisy
+			if msg.Progress != nil && msg.Progress.Total > 0 {
+				continue
+			}
+			line := msg.Status
+			if msg.ID != "" {
+				line = msg.ID + ": " + line
+			}
+			output(line)
+		}
+	}
+
+	if partial != "" {
+		output(partial)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriteBuildContext(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	files := map[string]string{
+		"Dockerfile":          "FROM alpine",
+		".dockerignore":       "node_modules\n*.log\nDockerfile\n",
+		"main.go":             "package main",
+		"debug.log":           "log",
+		"node_modules/a.js":   "a",
+		"docs/README.md":      "docs",
+		"../other.Dockerfile": "FROM busybox",
+	}
+	contextDir := filepath.Join(tmp, "app")
+	for name, content := range files {
+		path := filepath.Join(contextDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+
+	names := func(opt BuildOptions) []string {
+		var buf bytes.Buffer
+		if err := writeBuildContext(&buf, opt); err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		tr := tar.NewReader(&buf)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, hdr.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	want := []string{".dockerignore", "Dockerfile", "docs/", "docs/README.md", "main.go"}
+	if got := names(BuildOptions{ContextDir: contextDir}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v. Got %v.", want, got)
+	}
+
+	// Dockerfile outside of the context is added, and the ignored one is not included
+	want = []string{".dockerignore", outsideDockerfile, "docs/", "docs/README.md", "main.go"}
+	got := names(BuildOptions{ContextDir: contextDir, Dockerfile: filepath.Join(tmp, "other.Dockerfile")})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v. Got %v.", want, got)
+	}
+}
+
+func TestDecodeBuildStream(t *testing.T) {
+	stream := `{"stream":"Step 1/2 : FROM alpine\n"}
+{"status":"Pulling from library/alpine","id":"latest"}
+{"status":"Downloading","progressDetail":{"current":10,"total":100},"id":"abc"}
+{"stream":" ---> 965ea09ff2eb\n"}
+{"stream":"Step 2/2 : RUN exit 1"}
+{"stream":"\n"}
+{"errorDetail":{"code":1,"message":"The command '/bin/sh -c exit 1' returned a non-zero code: 1"},"error":"The command '/bin/sh -c exit 1' returned a non-zero code: 1"}
+`
+
+	var lines []string
+	err := decodeBuildStream(strings.NewReader(stream), func(line string) {
+		lines = append(lines, line)
+	})
+
+	want := []string{
+		"Step 1/2 : FROM alpine",
+		"latest: Pulling from library/alpine",
+		" ---> 965ea09ff2eb",
+		"Step 2/2 : RUN exit 1",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q. Got %q.", want, lines)
+	}
+	if err == nil || !strings.Contains(err.Error(), "non-zero code") {
+		t.Errorf("Expected build error. Got %v.", err)
+	}
+}
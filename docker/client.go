@@ -6,9 +6,6 @@ import (
 	"github.com/docker/docker/client"
 )
 
-// Client docker client
-var Client *Docker
-
 // Docker docker client
 type Docker struct {
 	*client.Client
@@ -62,7 +59,5 @@ func NewDocker(config *ClientConfig) *Docker {
 		panic(err)
 	}
 
-	Client = &Docker{client}
-
-	return Client
+	return &Docker{client}
 }
@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/skanehira/docui/common"
+)
+
+// VolumeWriters return names of running containers which mount the volume read-write
+func VolumeWriters(containers []types.Container, volume string) []string {
+	var names []string
+	for _, c := range containers {
+		if c.State != "running" {
+			continue
+		}
+		for _, m := range c.Mounts {
+			if m.Type == mount.TypeVolume && m.Name == volume && m.RW {
+				names = append(names, strings.TrimPrefix(c.Names[0], "/"))
+				break
+			}
+		}
+	}
+	return names
+}
+
+// CloneVolume create dst volume with the same driver, options and labels as src,
+// and copy the data with "cp -a" in the helper container to keep ownership and permissions.
+// dst is removed when the copy fails or ctx is canceled.
+func (d *Docker) CloneVolume(ctx context.Context, src, dst string, progress func(string)) (err error) {
+	source, err := d.InspectVolume(src)
+	if err != nil {
+		return err
+	}
+	if _, err := d.InspectVolume(dst); err == nil {
+		return fmt.Errorf("volume %s already exists", dst)
+	}
+
+	if err := d.ensureImage(ctx, VolumeHelperImage); err != nil {
+		return err
+	}
+
+	progress("create volume " + dst)
+	_, err = d.VolumeCreate(ctx, volumetypes.VolumeCreateBody{
+		Name:       dst,
+		Driver:     source.Driver,
+		DriverOpts: source.Options,
+		Labels:     source.Labels,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rerr := d.VolumeRemove(context.Background(), dst, true); rerr != nil {
+			common.Logger.Errorf("cannot remove volume %s %s", dst, rerr)
+		}
+	}()
+
+	resp, err := d.ContainerCreate(ctx,
+		&container.Config{
+			Image:  VolumeHelperImage,
+			Cmd:    []string{"cp", "-a", "/source/.", "/target/"},
+			Labels: map[string]string{volumeHelperLabel: src},
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{
+				{Type: mount.TypeVolume, Source: src, Target: "/source", ReadOnly: true},
+				{Type: mount.TypeVolume, Source: dst, Target: "/target"},
+			},
+		}, nil, "")
+	if err != nil {
+		return err
+	}
+	// the helper is removed before dst
+	defer d.removeVolumeHelper(resp.ID)
+
+	progress("copy " + src + " to " + dst)
+	waitC, errC := d.ContainerWait(ctx, resp.ID, container.WaitConditionNextExit)
+	if err := d.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return err
+	}
+
+	select {
+	case result := <-waitC:
+		if result.StatusCode != 0 {
+			return fmt.Errorf("copy exited with %d: %s", result.StatusCode, d.helperOutput(resp.ID))
+		}
+	case err := <-errC:
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// helperOutput return the output of the helper container for the error message
+func (d *Docker) helperOutput(id string) string {
+	out, err := d.ContainerLogs(context.Background(), id, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Tail: "5"})
+	if err != nil {
+		return err.Error()
+	}
+	defer out.Close()
+
+	var buf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&buf, &buf, out); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSpace(buf.String())
+}
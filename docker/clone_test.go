@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestVolumeWriters(t *testing.T) {
+	containers := []types.Container{
+		{Names: []string{"/db"}, State: "running", Mounts: []types.MountPoint{{Type: mount.TypeVolume, Name: "pgdata", RW: true}}},
+		{Names: []string{"/backup"}, State: "running", Mounts: []types.MountPoint{{Type: mount.TypeVolume, Name: "pgdata", RW: false}}},
+		{Names: []string{"/old"}, State: "exited", Mounts: []types.MountPoint{{Type: mount.TypeVolume, Name: "pgdata", RW: true}}},
+		{Names: []string{"/web"}, State: "running", Mounts: []types.MountPoint{{Type: mount.TypeVolume, Name: "cache", RW: true}}},
+	}
+
+	if got := VolumeWriters(containers, "pgdata"); !reflect.DeepEqual(got, []string{"db"}) {
+		t.Errorf("Expected [db]. Got %v.", got)
+	}
+}
+
+func TestFakeCloneVolume(t *testing.T) {
+	f := NewFake()
+	f.VolumeItems = []*types.Volume{{Name: "pgdata", Driver: "local", Labels: map[string]string{"app": "db"}, Options: map[string]string{"type": "tmpfs"}}}
+	f.VolumeFiles["pgdata"] = map[string]string{"/PG_VERSION": "11"}
+
+	if err := f.CloneVolume(context.Background(), "pgdata", "pgdata-clone", func(string) {}); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	clone := f.VolumeItems[1]
+	if clone.Name != "pgdata-clone" || clone.Labels["app"] != "db" || clone.Options["type"] != "tmpfs" {
+		t.Errorf("Expected the clone has the same labels and options. Got %+v.", clone)
+	}
+	if f.VolumeFiles["pgdata-clone"]["/PG_VERSION"] != "11" {
+		t.Errorf("Expected the data is copied. Got %v.", f.VolumeFiles["pgdata-clone"])
+	}
+
+	if err := f.CloneVolume(context.Background(), "pgdata", "pgdata-clone", func(string) {}); err == nil {
+		t.Error("Expected error for existing volume.")
+	}
+}
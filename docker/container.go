@@ -2,15 +2,16 @@ package docker
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
-	"github.com/docker/go-connections/nat"
+	"github.com/docker/docker/pkg/term"
 	"github.com/skanehira/docui/common"
 	"github.com/skanehira/docui/docker/streamer"
 )
@@ -34,90 +35,20 @@ func (d *Docker) InspectContainer(name string) (types.ContainerJSON, error) {
 	return container, err
 }
 
-// CreateContainer create container
-func (d *Docker) CreateContainer(opt CreateContainerOptions) error {
-	_, err := d.ContainerCreate(context.TODO(), opt.Config, opt.HostConfig, opt.NetworkConfig, opt.Name)
-	return err
+// CreateContainer create container and return its id
+func (d *Docker) CreateContainer(opt CreateContainerOptions) (string, error) {
+	resp, err := d.ContainerCreate(context.TODO(), opt.Config, opt.HostConfig, opt.NetworkConfig, opt.Name)
+	return resp.ID, err
 }
 
-// NewContainerOptions generate container options to create container
-func (d *Docker) NewContainerOptions(config map[string]string, isAttach bool) (CreateContainerOptions, error) {
-
-	options := CreateContainerOptions{
-		Config:     &container.Config{},
-		HostConfig: &container.HostConfig{},
-	}
-
-	options.Config.Image = config["Image"]
-	options.Name = config["Name"]
-
-	image, _, err := d.ImageInspectWithRaw(context.TODO(), options.Config.Image)
-
-	if user := config["User"]; user != "" {
-		options.Config.User = user
-	}
-
+// NewContainerOptions generate container options to create container from spec
+func (d *Docker) NewContainerOptions(spec ContainerSpec) (CreateContainerOptions, error) {
+	image, err := d.InspectImage(spec.Image)
 	if err != nil {
-		return options, err
+		return CreateContainerOptions{}, err
 	}
 
-	options.Config.Env = image.Config.Env
-
-	port := config["Port"]
-	hostPort := config["HostPort"]
-	ip := config["HostIP"]
-
-	if ip == "" {
-		ip = "0.0.0.0"
-	}
-
-	if port != "" && hostPort != "" {
-		options.HostConfig.PortBindings = nat.PortMap{
-			nat.Port(port + "/tcp"): {
-				{
-					HostIP:   ip,
-					HostPort: hostPort,
-				},
-			},
-		}
-	}
-
-	if cmd := config["Cmd"]; cmd != "" {
-		cmds := strings.Split(cmd, ",")
-		for _, c := range cmds {
-			options.Config.Cmd = append(options.Config.Cmd, c)
-		}
-	}
-
-	if env := config["Env"]; env != "" {
-		for _, v := range strings.Split(env, ",") {
-			v = common.GetOSenv(v)
-			options.Config.Env = append(options.Config.Env, v)
-		}
-	}
-
-	hostVolume := config["HostVolume"]
-	volume := config["Volume"]
-	if hostVolume != "" && volume != "" {
-		options.HostConfig.Mounts = []mount.Mount{
-			{
-				Target: volume,
-				Source: hostVolume,
-				Type:   mount.Type(config["VolumeType"]),
-			},
-		}
-	}
-
-	options.Config.AttachStdout = true
-	options.Config.AttachStderr = true
-
-	if isAttach {
-		options.Config.Tty = true
-		options.Config.AttachStdin = true
-		options.Config.OpenStdin = true
-	}
-
-	return options, nil
+	return newContainerOptions(image, spec)
 }
 
 // CommitContainer commit container
@@ -131,9 +62,13 @@ func (d *Docker) RemoveContainer(name string) error {
 	return d.ContainerRemove(context.TODO(), name, types.ContainerRemoveOptions{})
 }
 
-// KillContainer kill container
-func (d *Docker) KillContainer(name string) error {
-	return d.ContainerKill(context.TODO(), name, "KILL")
+// KillContainer send the signal like KILL, SIGTERM or 15 to the main process of the container
+func (d *Docker) KillContainer(name, signal string) error {
+	signal, err := ParseSignal(signal)
+	if err != nil {
+		return err
+	}
+	return d.ContainerKill(context.TODO(), name, signal)
 }
 
 // RenameContainer rename container
@@ -146,9 +81,24 @@ func (d *Docker) StartContainer(id string) error {
 	return d.ContainerStart(context.TODO(), id, types.ContainerStartOptions{})
 }
 
-// StopContainer stop container with id
-func (d *Docker) StopContainer(id string) error {
-	return d.ContainerStop(context.TODO(), id, nil)
+// StopContainer stop container with id, it is killed after the timeout. nil timeout is the default of the container.
+func (d *Docker) StopContainer(id string, timeout *time.Duration) error {
+	return d.ContainerStop(context.TODO(), id, timeout)
+}
+
+// RestartContainer restart container with id, the timeout is the same as StopContainer
+func (d *Docker) RestartContainer(id string, timeout *time.Duration) error {
+	return d.ContainerRestart(context.TODO(), id, timeout)
+}
+
+// PauseContainer pause container with id
+func (d *Docker) PauseContainer(id string) error {
+	return d.ContainerPause(context.TODO(), id)
+}
+
+// UnpauseContainer unpause container with id
+func (d *Docker) UnpauseContainer(id string) error {
+	return d.ContainerUnpause(context.TODO(), id)
 }
 
 // ExportContainer export container
@@ -172,42 +122,80 @@ func (d *Docker) ExportContainer(name, path string) error {
 	return nil
 }
 
-// CreateExec container exec create
-func (d *Docker) CreateExec(container, cmd string) (types.IDResponse, error) {
-	return d.ContainerExecCreate(context.TODO(), container, types.ExecConfig{
-		Tty:          true,
-		AttachStdin:  true,
-		AttachStderr: true,
-		AttachStdout: true,
-		Cmd:          []string{cmd},
-	})
-}
+// DefaultDetachKeys key sequence to detach from the container like docker attach
+const DefaultDetachKeys = "ctrl-p,ctrl-q"
 
-// AttachExecContainer attach container
-func (d *Docker) AttachExecContainer(id, cmd string) error {
-	exec, err := d.CreateExec(id, cmd)
+// ValidateDetachKeys validate key sequence to detach, e.g. "ctrl-p,ctrl-q"
+func ValidateDetachKeys(keys string) error {
+	_, err := term.ToBytes(keys)
+	return err
+}
 
+// AttachContainer attach to the main process of the container.
+// stdout and stderr are demultiplexed when the container has no tty.
+func (d *Docker) AttachContainer(id, detachKeys string) error {
+	c, err := d.InspectContainer(id)
 	if err != nil {
-		common.Logger.Error(err)
 		return err
 	}
+	if c.State == nil || !c.State.Running {
+		return fmt.Errorf("container %s is not running", strings.TrimPrefix(c.Name, "/"))
+	}
 
 	ctx := context.TODO()
 
-	resp, err := d.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: true})
+	resp, err := d.ContainerAttach(ctx, id, types.ContainerAttachOptions{
+		Stream:     true,
+		Stdin:      c.Config.OpenStdin,
+		Stdout:     true,
+		Stderr:     true,
+		DetachKeys: detachKeys,
+	})
 	if err != nil {
 		common.Logger.Error(err)
 		return err
 	}
 	defer resp.Close()
 
-	f := func(ctx context.Context, id string, options types.ResizeOptions) error {
-		return d.ContainerExecResize(ctx, id, options)
+	s := streamer.New(c.Config.Tty)
+	if err := s.SetDetachKeys(detachKeys); err != nil {
+		return err
 	}
+	// like docker attach --sig-proxy, Ctrl+C without tty is sent to the container
+	s.SetSignalProxy(func(signal string) {
+		if err := d.KillContainer(id, signal); err != nil {
+			common.Logger.Errorf("cannot send %s to container %s", signal, err)
+		}
+	})
 
-	s := streamer.New()
-	if err := s.Stream(ctx, exec.ID, resp, streamer.ResizeContainer(f)); err != nil {
-		return err
+	f := func(ctx context.Context, id string, options types.ResizeOptions) error {
+		return d.ContainerResize(ctx, id, options)
 	}
-	return nil
+
+	return s.Stream(ctx, id, resp, streamer.ResizeContainer(f))
+}
+
+// ExecOptions options to exec command in the container
+type ExecOptions struct {
+	Cmd        []string
+	User       string
+	WorkingDir string
+	Env        []string
+	Privileged bool
+	Tty        bool
+}
+
+// CreateExec container exec create
+func (d *Docker) CreateExec(container string, opt ExecOptions) (types.IDResponse, error) {
+	return d.ContainerExecCreate(context.TODO(), container, types.ExecConfig{
+		User:         opt.User,
+		Privileged:   opt.Privileged,
+		Tty:          opt.Tty,
+		AttachStdin:  true,
+		AttachStderr: true,
+		AttachStdout: true,
+		Env:          opt.Env,
+		WorkingDir:   opt.WorkingDir,
+		Cmd:          opt.Cmd,
+	})
 }
@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// kinds of container filesystem changes
+const (
+	ChangeModify uint8 = iota
+	ChangeAdd
+	ChangeDelete
+)
+
+// DiffContainer list changes on the container filesystem
+func (d *Docker) DiffContainer(ctx context.Context, id string) ([]container.ContainerChangeResponseItem, error) {
+	return d.ContainerDiff(ctx, id)
+}
+
+// ExportChanges write added and changed files of the container into a tar archive at path.
+// deleted files are not included.
+func (d *Docker) ExportChanges(ctx context.Context, id, path string, progress func(int64)) error {
+	changes, err := d.ContainerDiff(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return writeChangesFile(path, changes, progress, func(p string) (*tar.Header, io.ReadCloser, error) {
+		stat, err := d.ContainerStatPath(ctx, id, p)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// copying directory includes all files in it, so only the entry is written
+		if stat.Mode.IsDir() {
+			return &tar.Header{
+				Typeflag: tar.TypeDir,
+				Mode:     int64(stat.Mode.Perm()),
+				ModTime:  stat.Mtime,
+			}, ioutil.NopCloser(strings.NewReader("")), nil
+		}
+
+		reader, _, err := d.CopyFromContainer(ctx, id, p)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tr := tar.NewReader(reader)
+		hdr, err := tr.Next()
+		if err != nil {
+			reader.Close()
+			return nil, nil, err
+		}
+
+		return hdr, &tarEntryReader{Reader: tr, closer: reader}, nil
+	})
+}
+
+// tarEntryReader read the content of the current entry in the archive
+type tarEntryReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *tarEntryReader) Close() error {
+	return r.closer.Close()
+}
+
+// writeChangesFile write the changes into the new file at path by writeChanges.
+// the file is removed when it fails, so it can be retried with the same path.
+func writeChangesFile(path string, changes []container.ContainerChangeResponseItem, progress func(int64), open func(p string) (*tar.Header, io.ReadCloser, error)) (err error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		// do not leave the broken archive
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	return writeChanges(file, changes, progress, open)
+}
+
+// writeChanges write the entries of added and changed paths into tar stream w.
+// open return the header and the content of the path.
+func writeChanges(w io.Writer, changes []container.ContainerChangeResponseItem, progress func(int64), open func(p string) (*tar.Header, io.ReadCloser, error)) error {
+	var paths []string
+	for _, c := range changes {
+		if c.Kind != ChangeDelete {
+			paths = append(paths, c.Path)
+		}
+	}
+	sort.Strings(paths)
+
+	counter := newProgressCounter(progress)
+	tw := tar.NewWriter(w)
+
+	for _, p := range paths {
+		hdr, content, err := open(p)
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %s", p, err)
+		}
+
+		hdr.Name = strings.TrimPrefix(p, "/")
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			content.Close()
+			return err
+		}
+
+		_, err = io.Copy(io.MultiWriter(tw, counter), content)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	counter.done()
+	return tw.Close()
+}
@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestExportChanges(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	fake := NewFake()
+	fake.Files["abc"] = map[string]string{
+		"/etc/app.conf": "debug=true",
+		"/etc/hosts":    "127.0.0.1 localhost",
+	}
+	fake.Changes["abc"] = []container.ContainerChangeResponseItem{
+		{Kind: ChangeModify, Path: "/etc"},
+		{Kind: ChangeAdd, Path: "/etc/app.conf"},
+		{Kind: ChangeDelete, Path: "/etc/motd"},
+	}
+
+	path := filepath.Join(tmp, "changes.tar")
+	if err := fake.ExportChanges(context.TODO(), "abc", path, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var names []string
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+
+	want := []string{"etc/", "etc/app.conf"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v. Got %v.", want, names)
+	}
+
+	// existing file is not overwritten
+	if err := fake.ExportChanges(context.TODO(), "abc", path, nil); err == nil {
+		t.Error("Expected error for existing file.")
+	}
+}
+
+func TestWriteChangesFileRemovedOnError(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, "changes.tar")
+	changes := []container.ContainerChangeResponseItem{{Kind: ChangeAdd, Path: "/app.conf"}}
+	err = writeChangesFile(path, changes, nil, func(p string) (*tar.Header, io.ReadCloser, error) {
+		return nil, nil, errors.New("No such container")
+	})
+	if err == nil {
+		t.Fatal("Expected error.")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the broken archive is removed. Got %v.", err)
+	}
+
+	// it can be retried with the same path
+	err = writeChangesFile(path, changes, nil, func(p string) (*tar.Header, io.ReadCloser, error) {
+		return &tar.Header{Typeflag: tar.TypeReg, Mode: 0644, Size: 1}, ioutil.NopCloser(strings.NewReader("a")), nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error. Got %s.", err)
+	}
+}
@@ -0,0 +1,379 @@
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/skanehira/docui/common"
+)
+
+// RunCommand parsed docker run command line
+type RunCommand struct {
+	Spec ContainerSpec
+	// Start the container after creating it, false when docker create
+	Start bool
+	// Detach -d, the container is always run in the background
+	Detach bool
+	// Unsupported flags which are ignored, e.g. "--hostname web"
+	Unsupported []string
+}
+
+// runFlag option of docker run
+type runFlag struct {
+	// value the flag takes a value
+	value bool
+	// apply set the value to the command, nil is unsupported flag
+	apply func(cmd *RunCommand, value string) error
+}
+
+// runFlags supported and known unsupported flags of docker run
+var runFlags = map[string]runFlag{
+	"name": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.Name = v
+		return nil
+	}},
+	"publish": {true, func(cmd *RunCommand, v string) error {
+		p, err := ParsePort(v)
+		cmd.Spec.Ports = append(cmd.Spec.Ports, p)
+		return err
+	}},
+	"volume": {true, func(cmd *RunCommand, v string) error {
+		m, err := ParseVolume(v)
+		cmd.Spec.Mounts = append(cmd.Spec.Mounts, m)
+		return err
+	}},
+	"mount": {true, func(cmd *RunCommand, v string) error {
+		m, err := ParseMount(v)
+		cmd.Spec.Mounts = append(cmd.Spec.Mounts, m)
+		return err
+	}},
+	"tmpfs": {true, func(cmd *RunCommand, v string) error {
+		target := strings.SplitN(v, ":", 2)
+		if len(target) == 2 {
+			cmd.Unsupported = append(cmd.Unsupported, "--tmpfs options "+target[1])
+		}
+		m := MountSpec{Type: mount.TypeTmpfs, Target: target[0]}
+		cmd.Spec.Mounts = append(cmd.Spec.Mounts, m)
+		return m.validate()
+	}},
+	"env": {true, func(cmd *RunCommand, v string) error {
+		if env, ok := hostEnv(v); ok {
+			cmd.Spec.Env = append(cmd.Spec.Env, env)
+		}
+		return nil
+	}},
+	"env-file": {true, func(cmd *RunCommand, v string) error {
+		lines, err := readLineFile(v)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if env, ok := hostEnv(line); ok {
+				cmd.Spec.Env = append(cmd.Spec.Env, env)
+			}
+		}
+		return nil
+	}},
+	"label": {true, func(cmd *RunCommand, v string) error {
+		return addLabel(&cmd.Spec, v)
+	}},
+	"label-file": {true, func(cmd *RunCommand, v string) error {
+		lines, err := readLineFile(v)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := addLabel(&cmd.Spec, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	"workdir": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.WorkingDir = v
+		return nil
+	}},
+	"user": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.User = v
+		return nil
+	}},
+	"entrypoint": {true, func(cmd *RunCommand, v string) error {
+		// docker run doesn't split the entrypoint
+		cmd.Spec.Entrypoint = []string{v}
+		if v == "" {
+			cmd.Spec.Entrypoint = nil
+		}
+		return nil
+	}},
+	"network": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.Network = v
+		return nil
+	}},
+	"network-alias": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.Aliases = append(cmd.Spec.Aliases, v)
+		return nil
+	}},
+	"restart": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.RestartPolicy = v
+		_, err := ParseRestartPolicy(v)
+		return err
+	}},
+	"memory": {true, func(cmd *RunCommand, v string) (err error) {
+		cmd.Spec.Memory, err = ParseMemory(v)
+		return err
+	}},
+	"cpus": {true, func(cmd *RunCommand, v string) error {
+		cpus, err := strconv.ParseFloat(v, 64)
+		if err != nil || cpus < 0 {
+			return fmt.Errorf("invalid cpus %s", v)
+		}
+		cmd.Spec.CPUs = cpus
+		return nil
+	}},
+	"cap-add": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.CapAdd = append(cmd.Spec.CapAdd, v)
+		return nil
+	}},
+	"cap-drop": {true, func(cmd *RunCommand, v string) error {
+		cmd.Spec.CapDrop = append(cmd.Spec.CapDrop, v)
+		return nil
+	}},
+	"rm": {false, func(cmd *RunCommand, v string) (err error) {
+		cmd.Spec.AutoRemove, err = strconv.ParseBool(v)
+		return err
+	}},
+	"detach": {false, func(cmd *RunCommand, v string) (err error) {
+		cmd.Detach, err = strconv.ParseBool(v)
+		return err
+	}},
+	"interactive": {false, func(cmd *RunCommand, v string) (err error) {
+		cmd.Spec.OpenStdin, err = strconv.ParseBool(v)
+		return err
+	}},
+	"tty": {false, func(cmd *RunCommand, v string) (err error) {
+		cmd.Spec.Tty, err = strconv.ParseBool(v)
+		return err
+	}},
+	"privileged": {false, func(cmd *RunCommand, v string) (err error) {
+		cmd.Spec.Privileged, err = strconv.ParseBool(v)
+		return err
+	}},
+
+	// unsupported flags
+	"add-host": {value: true}, "attach": {value: true}, "blkio-weight": {value: true},
+	"cgroup-parent": {value: true}, "cgroupns": {value: true}, "cidfile": {value: true},
+	"cpu-period": {value: true}, "cpu-quota": {value: true}, "cpu-shares": {value: true},
+	"cpuset-cpus": {value: true}, "cpuset-mems": {value: true}, "detach-keys": {value: true},
+	"device": {value: true}, "dns": {value: true}, "dns-option": {value: true},
+	"dns-search": {value: true}, "domainname": {value: true}, "expose": {value: true},
+	"gpus": {value: true}, "group-add": {value: true}, "health-cmd": {value: true},
+	"health-interval": {value: true}, "health-retries": {value: true}, "health-start-period": {value: true},
+	"health-timeout": {value: true}, "hostname": {value: true}, "ip": {value: true},
+	"ip6": {value: true}, "ipc": {value: true}, "isolation": {value: true},
+	"kernel-memory": {value: true}, "link": {value: true}, "log-driver": {value: true},
+	"log-opt": {value: true}, "mac-address": {value: true}, "memory-reservation": {value: true},
+	"memory-swap": {value: true}, "memory-swappiness": {value: true}, "oom-score-adj": {value: true},
+	"pid": {value: true}, "pids-limit": {value: true}, "platform": {value: true},
+	"pull": {value: true}, "runtime": {value: true}, "security-opt": {value: true},
+	"shm-size": {value: true}, "stop-signal": {value: true}, "stop-timeout": {value: true},
+	"storage-opt": {value: true}, "sysctl": {value: true}, "ulimit": {value: true},
+	"userns": {value: true}, "uts": {value: true}, "volume-driver": {value: true}, "volumes-from": {value: true},
+	"disable-content-trust": {}, "init": {}, "no-healthcheck": {}, "oom-kill-disable": {},
+	"publish-all": {}, "quiet": {}, "read-only": {}, "sig-proxy": {},
+}
+
+// runFlagAliases short flags and aliases of docker run
+var runFlagAliases = map[string]string{
+	"a":         "attach",
+	"c":         "cpu-shares",
+	"d":         "detach",
+	"e":         "env",
+	"h":         "hostname",
+	"i":         "interactive",
+	"l":         "label",
+	"m":         "memory",
+	"p":         "publish",
+	"P":         "publish-all",
+	"q":         "quiet",
+	"t":         "tty",
+	"u":         "user",
+	"v":         "volume",
+	"w":         "workdir",
+	"net":       "network",
+	"net-alias": "network-alias",
+}
+
+// ParseRunCommand parse docker run or docker create command line
+func ParseRunCommand(line string) (RunCommand, error) {
+	var cmd RunCommand
+
+	args, err := common.SplitShellWords(line)
+	if err != nil {
+		return cmd, err
+	}
+
+	if len(args) > 0 && args[0] == "docker" {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == "container" {
+		args = args[1:]
+	}
+	if len(args) == 0 || (args[0] != "run" && args[0] != "create") {
+		return cmd, fmt.Errorf("not docker run command")
+	}
+	cmd.Start = args[0] == "run"
+	args = args[1:]
+
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			args = args[1:]
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			break
+		}
+		args = args[1:]
+
+		if strings.HasPrefix(arg, "--") {
+			args, err = cmd.parseLongFlag(arg[2:], args)
+		} else {
+			args, err = cmd.parseShortFlags(arg[1:], args)
+		}
+		if err != nil {
+			return cmd, err
+		}
+	}
+
+	if len(args) == 0 {
+		return cmd, fmt.Errorf("image is required")
+	}
+	cmd.Spec.Image = args[0]
+	if len(args) > 1 {
+		cmd.Spec.Cmd = args[1:]
+	}
+
+	if len(cmd.Spec.Aliases) > 0 && cmd.Spec.Network == "" {
+		return cmd, fmt.Errorf("--network-alias needs --network")
+	}
+
+	return cmd, nil
+}
+
+// parseLongFlag parse flag like "--name web" or "--name=web"
+func (cmd *RunCommand) parseLongFlag(arg string, args []string) ([]string, error) {
+	name, value := arg, ""
+	hasValue := false
+	if i := strings.Index(arg, "="); i >= 0 {
+		name, value, hasValue = arg[:i], arg[i+1:], true
+	}
+
+	return cmd.applyFlag("--"+name, name, value, hasValue, args)
+}
+
+// parseShortFlags parse flags like "-it", "-p 80:80" or "-p80:80"
+func (cmd *RunCommand) parseShortFlags(arg string, args []string) ([]string, error) {
+	for i := 0; i < len(arg); i++ {
+		name := string(arg[i])
+		flag, ok := runFlags[runFlagAliases[name]]
+		if !ok {
+			return args, fmt.Errorf("unknown flag -%s", name)
+		}
+
+		// the rest of the argument is the value, e.g. "-p80:80" or "-e=A=1"
+		if flag.value && i+1 < len(arg) {
+			return cmd.applyFlag("-"+name, name, strings.TrimPrefix(arg[i+1:], "="), true, args)
+		}
+
+		var err error
+		if args, err = cmd.applyFlag("-"+name, name, "", false, args); err != nil {
+			return args, err
+		}
+	}
+	return args, nil
+}
+
+// applyFlag apply the flag, and return remaining args.
+// the value is taken from args when the flag needs value and it isn't given.
+func (cmd *RunCommand) applyFlag(display, name, value string, hasValue bool, args []string) ([]string, error) {
+	if alias, ok := runFlagAliases[name]; ok {
+		name = alias
+	}
+
+	flag, ok := runFlags[name]
+	if !ok {
+		return args, fmt.Errorf("unknown flag %s", display)
+	}
+
+	switch {
+	case flag.value && !hasValue:
+		if len(args) == 0 {
+			return args, fmt.Errorf("flag %s needs an argument", display)
+		}
+		value, args = args[0], args[1:]
+	case !flag.value && !hasValue:
+		value = "true"
+	}
+
+	if flag.apply == nil {
+		unsupported := display
+		if flag.value || value != "true" {
+			unsupported += " " + value
+		}
+		cmd.Unsupported = append(cmd.Unsupported, unsupported)
+		return args, nil
+	}
+
+	if err := flag.apply(cmd, value); err != nil {
+		return args, fmt.Errorf("invalid %s %s: %s", display, value, err)
+	}
+	return args, nil
+}
+
+// hostEnv return env like docker run -e.
+// when value is omitted, the environment variable of the host is used if it is set.
+func hostEnv(env string) (string, bool) {
+	if strings.Contains(env, "=") {
+		return env, true
+	}
+	if v, ok := os.LookupEnv(env); ok {
+		return env + "=" + v, true
+	}
+	return "", false
+}
+
+func addLabel(spec *ContainerSpec, label string) error {
+	key, value, err := ParseLabel(label)
+	if err != nil {
+		return err
+	}
+	if spec.Labels == nil {
+		spec.Labels = make(map[string]string)
+	}
+	spec.Labels[key] = value
+	return nil
+}
+
+// readLineFile read lines of --env-file or --label-file, empty lines and comments are skipped
+func readLineFile(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestParseRunCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docui-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	envFile := filepath.Join(dir, "app.env")
+	if err := ioutil.WriteFile(envFile, []byte("# comment\nDB=postgres\n\nDOCUI_TEST_TOKEN\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("DOCUI_TEST_TOKEN", "secret")
+	defer os.Unsetenv("DOCUI_TEST_TOKEN")
+
+	line := `docker run -dit --rm --name web -p 8080:80 -p127.0.0.1:53:53/udp \
+  -v /srv:/usr/share/nginx/html:ro --mount type=volume,source=data,target=/data \
+  -e "GREETING=hello world" -e DOCUI_TEST_UNSET --env-file ` + envFile + ` \
+  --network=front --net-alias www --restart on-failure:3 -m 512m --cpus 1.5 \
+  --entrypoint /docker-entrypoint.sh --hostname web1 --read-only \
+  nginx:latest nginx -g 'daemon off;'`
+
+	got, err := ParseRunCommand(line)
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	want := RunCommand{
+		Start:  true,
+		Detach: true,
+		Spec: ContainerSpec{
+			Name:       "web",
+			Image:      "nginx:latest",
+			Cmd:        []string{"nginx", "-g", "daemon off;"},
+			Entrypoint: []string{"/docker-entrypoint.sh"},
+			Env:        []string{"GREETING=hello world", "DB=postgres", "DOCUI_TEST_TOKEN=secret"},
+			Ports: []PortBinding{
+				{HostPort: "8080", ContainerPort: "80"},
+				{HostIP: "127.0.0.1", HostPort: "53", ContainerPort: "53", Protocol: "udp"},
+			},
+			Mounts: []MountSpec{
+				{Type: mount.TypeBind, Source: "/srv", Target: "/usr/share/nginx/html", ReadOnly: true},
+				{Type: mount.TypeVolume, Source: "data", Target: "/data"},
+			},
+			RestartPolicy: "on-failure:3",
+			Network:       "front",
+			Aliases:       []string{"www"},
+			Memory:        512 << 20,
+			CPUs:          1.5,
+			Tty:           true,
+			OpenStdin:     true,
+			AutoRemove:    true,
+		},
+		Unsupported: []string{"--hostname web1", "--read-only"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, got)
+	}
+}
+
+func TestParseRunCommandCreate(t *testing.T) {
+	got, err := ParseRunCommand("docker container create --label tier=web -- alpine")
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if got.Start {
+		t.Errorf("Expected docker create not to start the container.")
+	}
+	if got.Spec.Image != "alpine" || got.Spec.Labels["tier"] != "web" {
+		t.Errorf("Unexpected spec %+v.", got.Spec)
+	}
+}
+
+func TestParseRunCommandError(t *testing.T) {
+	tests := []string{
+		"docker ps",
+		"docker run",
+		"docker run -d",
+		"docker run --foo nginx",
+		"docker run -x nginx",
+		"docker run -p 80/icmp nginx",
+		"docker run --name",
+		"docker run --net-alias www nginx",
+		"docker run --env-file /nonexistent/docui.env nginx",
+		`docker run -e "A=1 nginx`,
+	}
+
+	for _, line := range tests {
+		if _, err := ParseRunCommand(line); err == nil {
+			t.Errorf("Expected error for %s.", line)
+		}
+	}
+}
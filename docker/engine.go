@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	volumetypes "github.com/docker/docker/api/types/volume"
+)
+
+// Engine docker engine operations used by docui
+type Engine interface {
+	// system
+	Info(ctx context.Context) (types.Info, error)
+	ServerVersion(ctx context.Context) (types.Version, error)
+	DaemonHost() string
+	WatchEvents(ctx context.Context, handler func(events.Message)) error
+	DiskUsage(ctx context.Context) (types.DiskUsage, error)
+	RemoveBuildCache(ctx context.Context, id string) error
+
+	// container
+	Containers(opt types.ContainerListOptions) ([]types.Container, error)
+	InspectContainer(name string) (types.ContainerJSON, error)
+	CreateContainer(opt CreateContainerOptions) (string, error)
+	NewContainerOptions(spec ContainerSpec) (CreateContainerOptions, error)
+	CommitContainer(name string, opt types.ContainerCommitOptions) error
+	RemoveContainer(name string) error
+	KillContainer(name, signal string) error
+	RenameContainer(id, newName string) error
+	StartContainer(id string) error
+	StopContainer(id string, timeout *time.Duration) error
+	RestartContainer(id string, timeout *time.Duration) error
+	PauseContainer(id string) error
+	UnpauseContainer(id string) error
+	ExportContainer(name, path string) error
+	CreateExec(container string, opt ExecOptions) (types.IDResponse, error)
+	AttachContainer(id, detachKeys string) error
+	StartExecSession(container string, opt ExecOptions) (ExecSession, error)
+	ExecCommand(ctx context.Context, id string, opt ExecOptions) (ExecResult, error)
+	ContainerLogStream(ctx context.Context, name string, opt LogOptions) (io.ReadCloser, error)
+	StreamLogs(ctx context.Context, name string, opt LogOptions, handler func(LogLine)) error
+	WatchStats(ctx context.Context, id string, handler func(Stats)) error
+	TopContainer(ctx context.Context, id, psArgs string) (container.ContainerTopOKBody, error)
+	SignalProcess(ctx context.Context, id string, pid int, signal string) error
+	ContainerPID(ctx context.Context, id string, pid int) (int, error)
+	ListDir(ctx context.Context, id, dir string) ([]FileEntry, error)
+	ReadFile(ctx context.Context, id, file string, limit int64) ([]byte, bool, error)
+	DownloadFromContainer(ctx context.Context, id, src, dst string, progress func(int64)) error
+	UploadToContainer(ctx context.Context, id, src, dstDir string, progress func(int64)) error
+	DiffContainer(ctx context.Context, id string) ([]container.ContainerChangeResponseItem, error)
+	ExportChanges(ctx context.Context, id, path string, progress func(int64)) error
+
+	// image
+	Images(opt types.ImageListOptions) ([]types.ImageSummary, error)
+	InspectImage(name string) (types.ImageInspect, error)
+	HistoryImage(ctx context.Context, name string) ([]image.HistoryResponseItem, error)
+	BuildImage(ctx context.Context, opt BuildOptions, output func(string)) error
+	PullImage(ctx context.Context, name string, progress func(PullProgress)) error
+	RemoveImage(name string) error
+	RemoveDanglingImages() error
+	SaveImage(ids []string, path string) error
+	LoadImage(path string) error
+	ImportImage(name, tag, path string) error
+	SearchImage(name string) ([]registry.SearchResult, error)
+
+	// volume
+	Volumes() ([]*types.Volume, error)
+	InspectVolume(name string) (types.Volume, error)
+	RemoveVolume(name string) error
+	PruneVolumes() error
+	CreateVolume(opt volumetypes.VolumeCreateBody) error
+	NewCreateVolumeOptions(data map[string]string) volumetypes.VolumeCreateBody
+	CreateVolumeHelper(ctx context.Context, volume string, readOnly bool) (string, error)
+	RemoveVolumeHelpers(ctx context.Context) error
+	BackupVolume(ctx context.Context, volume, file string, compress bool, progress func(int64)) error
+	RestoreVolume(ctx context.Context, volume, file string, progress func(int64)) error
+	CloneVolume(ctx context.Context, src, dst string, progress func(string)) error
+
+	// network
+	Networks(opt types.NetworkListOptions) ([]types.NetworkResource, error)
+	InspectNetwork(name string) (types.NetworkResource, error)
+	RemoveNetwork(name string) error
+	CreateNetwork(name string, opt types.NetworkCreate) (string, error)
+	ConnectNetwork(name, container string, settings *network.EndpointSettings) error
+	DisconnectNetwork(name, container string, force bool) error
+}
+
+var _ Engine = &Docker{}
@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/skanehira/docui/common"
+)
+
+var (
+	// eventsRetryInterval interval to reconnect to the events stream.
+	// a stream which is alive longer than this is regarded as connected.
+	eventsRetryInterval = 2 * time.Second
+	// eventsMaxRetry max number of consecutive reconnect failures
+	eventsMaxRetry = 5
+)
+
+type subscribeEvents func(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error)
+
+// WatchEvents subscribe to container, image, volume and network events and call handler for each event.
+// When the stream is broken, it reconnects and resumes from the last received event.
+// It returns nil when ctx is done, or error when the events endpoint is unavailable.
+func (d *Docker) WatchEvents(ctx context.Context, handler func(events.Message)) error {
+	return watchEvents(ctx, d.Events, handler)
+}
+
+func watchEvents(ctx context.Context, subscribe subscribeEvents, handler func(events.Message)) error {
+	since := time.Now()
+	everAlive := false
+	failures := 0
+
+	for {
+		start := time.Now()
+		received, err := streamEvents(ctx, subscribe, since, func(msg events.Message) {
+			if msg.TimeNano != 0 {
+				since = time.Unix(0, msg.TimeNano)
+			} else if msg.Time != 0 {
+				since = time.Unix(msg.Time, 0)
+			}
+			handler(msg)
+		})
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		alive := received || time.Since(start) >= eventsRetryInterval
+		if alive {
+			everAlive = true
+			failures = 0
+		} else {
+			if !everAlive {
+				return err
+			}
+			failures++
+			if failures > eventsMaxRetry {
+				return err
+			}
+		}
+
+		common.Logger.Warnf("docker events stream is broken, reconnecting: %s", err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(eventsRetryInterval):
+		}
+	}
+}
+
+// streamEvents read events until the stream is broken, and return whether events were received
+func streamEvents(ctx context.Context, subscribe subscribeEvents, since time.Time, handler func(events.Message)) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	options := types.EventsOptions{
+		Since: fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
+		Filters: filters.NewArgs(
+			filters.Arg("type", events.ContainerEventType),
+			filters.Arg("type", events.ImageEventType),
+			filters.Arg("type", events.VolumeEventType),
+			filters.Arg("type", events.NetworkEventType),
+		),
+	}
+
+	received := false
+	messages, errs := subscribe(ctx, options)
+
+	for {
+		select {
+		case msg := <-messages:
+			received = true
+			handler(msg)
+		case err := <-errs:
+			return received, err
+		}
+	}
+}
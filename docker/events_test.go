@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/skanehira/docui/common"
+)
+
+// scriptedEvents returns subscribeEvents which sends msgs and then err for each subscription
+func scriptedEvents(streams [][]events.Message, errs []error, sinces *[]string) subscribeEvents {
+	call := 0
+	return func(ctx context.Context, options types.EventsOptions) (<-chan events.Message, <-chan error) {
+		*sinces = append(*sinces, options.Since)
+		msgCh := make(chan events.Message)
+		errCh := make(chan error, 1)
+
+		if call >= len(streams) {
+			go func() {
+				<-ctx.Done()
+				errCh <- ctx.Err()
+			}()
+			return msgCh, errCh
+		}
+
+		msgs, err := streams[call], errs[call]
+		call++
+		go func() {
+			for _, msg := range msgs {
+				msgCh <- msg
+			}
+			errCh <- err
+		}()
+		return msgCh, errCh
+	}
+}
+
+func TestWatchEventsResume(t *testing.T) {
+	common.NewLogger("error", "")
+	eventsRetryInterval = 10 * time.Millisecond
+
+	var sinces []string
+	subscribe := scriptedEvents(
+		[][]events.Message{
+			{{Type: "container", Action: "start", TimeNano: 1500000000123456789}},
+			{{Type: "image", Action: "pull", TimeNano: 1500000001000000000}},
+		},
+		[]error{io.EOF, io.EOF},
+		&sinces,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []string
+	err := watchEvents(ctx, subscribe, func(msg events.Message) {
+		got = append(got, msg.Type)
+		if len(got) == 2 {
+			cancel()
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 events. Got %v.", got)
+	}
+	if len(sinces) < 2 || sinces[1] != "1500000000.123456789" {
+		t.Errorf("Expected resume from last event. Got %v.", sinces)
+	}
+}
+
+func TestWatchEventsUnavailable(t *testing.T) {
+	common.NewLogger("error", "")
+	eventsRetryInterval = time.Second
+
+	var sinces []string
+	unavailable := errors.New("page not found")
+	subscribe := scriptedEvents([][]events.Message{nil}, []error{unavailable}, &sinces)
+
+	err := watchEvents(context.Background(), subscribe, func(msg events.Message) {})
+	if err != unavailable {
+		t.Errorf("Expected %s. Got %v.", unavailable, err)
+	}
+}
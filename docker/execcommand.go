@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ExecResult output and exit code of the command
+type ExecResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// ExecCommand run the command in the container without tty, and wait for it to exit
+func (d *Docker) ExecCommand(ctx context.Context, id string, opt ExecOptions) (ExecResult, error) {
+	exec, err := d.ContainerExecCreate(ctx, id, types.ExecConfig{
+		User:         opt.User,
+		Privileged:   opt.Privileged,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          opt.Env,
+		WorkingDir:   opt.WorkingDir,
+		Cmd:          opt.Cmd,
+	})
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	resp, err := d.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return ExecResult{}, err
+	}
+	defer resp.Close()
+
+	// the connection is closed to stop reading when ctx is canceled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			resp.Close()
+		case <-done:
+		}
+	}()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		if ctx.Err() != nil {
+			return ExecResult{}, ctx.Err()
+		}
+		return ExecResult{}, err
+	}
+
+	inspect, err := d.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return ExecResult{}, err
+	}
+
+	return ExecResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: inspect.ExitCode,
+	}, nil
+}
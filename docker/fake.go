@@ -0,0 +1,1474 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	dockernetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Fake in-memory docker engine.
+// It is intended for tests: set the state fields, and put an error
+// into Errs with a method name as the key to make that method fail.
+type Fake struct {
+	mu sync.Mutex
+
+	InfoResult     types.Info
+	VersionResult  types.Version
+	Host           string
+	ContainerItems []types.Container
+	ContainerJSONs map[string]types.ContainerJSON
+	ImageItems     []types.ImageSummary
+	ImageInspects  map[string]types.ImageInspect
+	// ImageHistories image histories, key is image id
+	ImageHistories map[string][]image.HistoryResponseItem
+	SearchResults  []registry.SearchResult
+	PullLayers     []LayerProgress
+	// BuildOutput lines of the build output
+	BuildOutput  []string
+	VolumeItems  []*types.Volume
+	NetworkItems []types.NetworkResource
+	// Logs container logs, key is container id or name
+	Logs map[string]string
+	// StatsItems container stats samples, key is container id
+	StatsItems map[string][]Stats
+	// Processes container processes, key is container id
+	Processes map[string]container.ContainerTopOKBody
+	// ProcDir directory like /proc to read PIDs in containers, they are matched by exec when it is empty
+	ProcDir string
+	// Files container files, key is container id and then absolute file path.
+	// directories are implied by the paths.
+	Files map[string]map[string]string
+	// VolumeFiles files in volumes, key is volume name and then absolute file path in the volume
+	VolumeFiles map[string]map[string]string
+	// ExecResults results of ExecCommand, key is container id
+	ExecResults map[string]ExecResult
+	// BuildCacheItems build cache records of DiskUsage
+	BuildCacheItems []*types.BuildCache
+	// Changes container filesystem changes, key is container id
+	Changes map[string][]container.ContainerChangeResponseItem
+	// Errs errors returned by methods, key is method name
+	Errs map[string]error
+	// Calls called methods with arguments, e.g. "RemoveContainer abc"
+	Calls []string
+
+	events chan events.Message
+}
+
+var _ Engine = &Fake{}
+
+// NewFake create new fake engine
+func NewFake() *Fake {
+	return &Fake{
+		Host:           "unix:///var/run/docker.sock",
+		ContainerJSONs: make(map[string]types.ContainerJSON),
+		ImageInspects:  make(map[string]types.ImageInspect),
+		ImageHistories: make(map[string][]image.HistoryResponseItem),
+		Logs:           make(map[string]string),
+		StatsItems:     make(map[string][]Stats),
+		Processes:      make(map[string]container.ContainerTopOKBody),
+		Files:          make(map[string]map[string]string),
+		VolumeFiles:    make(map[string]map[string]string),
+		ExecResults:    make(map[string]ExecResult),
+		Changes:        make(map[string][]container.ContainerChangeResponseItem),
+		Errs:           make(map[string]error),
+		events:         make(chan events.Message, 100),
+	}
+}
+
+// SetError make method return err. nil clears the error.
+func (f *Fake) SetError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.Errs, method)
+		return
+	}
+	f.Errs[method] = err
+}
+
+// CallsOf return the recorded calls of method
+func (f *Fake) CallsOf(method string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []string
+	for _, c := range f.Calls {
+		if c == method || strings.HasPrefix(c, method+" ") {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// call record method call and return scripted error.
+// caller must hold f.mu.
+func (f *Fake) call(method string, args ...string) error {
+	f.Calls = append(f.Calls, strings.TrimSpace(method+" "+strings.Join(args, " ")))
+	return f.Errs[method]
+}
+
+func (f *Fake) containerIndex(name string) int {
+	for i, c := range f.ContainerItems {
+		if strings.HasPrefix(c.ID, name) {
+			return i
+		}
+		for _, n := range c.Names {
+			if strings.TrimPrefix(n, "/") == strings.TrimPrefix(name, "/") {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (f *Fake) imageIndex(name string) int {
+	for i, img := range f.ImageItems {
+		if img.ID == name || strings.HasPrefix(strings.TrimPrefix(img.ID, "sha256:"), name) {
+			return i
+		}
+		for _, repoTag := range img.RepoTags {
+			if repoTag == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (f *Fake) setContainerState(id, state, status string) error {
+	i := f.containerIndex(id)
+	if i < 0 {
+		return fmt.Errorf("No such container: %s", id)
+	}
+	f.ContainerItems[i].State = state
+	f.ContainerItems[i].Status = status
+	return nil
+}
+
+// Info get docker info
+func (f *Fake) Info(ctx context.Context) (types.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Info"); err != nil {
+		return types.Info{}, err
+	}
+	return f.InfoResult, nil
+}
+
+// ServerVersion get docker server version
+func (f *Fake) ServerVersion(ctx context.Context) (types.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ServerVersion"); err != nil {
+		return types.Version{}, err
+	}
+	return f.VersionResult, nil
+}
+
+// DaemonHost get docker host
+func (f *Fake) DaemonHost() string {
+	return f.Host
+}
+
+// WatchEvents call handler with events sent by SendEvent until ctx is done
+func (f *Fake) WatchEvents(ctx context.Context, handler func(events.Message)) error {
+	f.mu.Lock()
+	err := f.call("WatchEvents")
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case msg := <-f.events:
+			handler(msg)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// SendEvent send event to the watcher
+func (f *Fake) SendEvent(msg events.Message) {
+	f.events <- msg
+}
+
+// Containers get containers
+func (f *Fake) Containers(opt types.ContainerListOptions) ([]types.Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Containers"); err != nil {
+		return nil, err
+	}
+
+	containers := make([]types.Container, 0, len(f.ContainerItems))
+	for _, c := range f.ContainerItems {
+		if !opt.All && c.State != "running" {
+			continue
+		}
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+// InspectContainer inspect container
+func (f *Fake) InspectContainer(name string) (types.ContainerJSON, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("InspectContainer", name); err != nil {
+		return types.ContainerJSON{}, err
+	}
+
+	if c, ok := f.ContainerJSONs[name]; ok {
+		return c, nil
+	}
+
+	i := f.containerIndex(name)
+	if i < 0 {
+		return types.ContainerJSON{}, fmt.Errorf("No such container: %s", name)
+	}
+
+	c := f.ContainerItems[i]
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    c.ID,
+			Name:  c.Names[0],
+			Image: c.Image,
+			State: &types.ContainerState{
+				Status:  c.State,
+				Running: c.State == "running",
+			},
+		},
+		Mounts: c.Mounts,
+	}, nil
+}
+
+// CreateContainer create container
+func (f *Fake) CreateContainer(opt CreateContainerOptions) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateContainer", opt.Name); err != nil {
+		return "", err
+	}
+	if opt.Name != "" && f.containerIndex(opt.Name) >= 0 {
+		return "", fmt.Errorf("Conflict. The container name %q is already in use", "/"+opt.Name)
+	}
+
+	id := fmt.Sprintf("%064d", len(f.ContainerItems)+1)
+	var mounts []types.MountPoint
+	if opt.HostConfig != nil {
+		for _, m := range opt.HostConfig.Mounts {
+			point := types.MountPoint{Type: m.Type, Source: m.Source, Destination: m.Target, RW: !m.ReadOnly}
+			if m.Type == mount.TypeVolume {
+				point.Source, point.Name = "", m.Source
+				if point.Name == "" {
+					// anonymous volume
+					point.Name = fmt.Sprintf("%064d", len(mounts)+1)
+				}
+			}
+			mounts = append(mounts, point)
+		}
+	}
+	f.ContainerItems = append(f.ContainerItems, types.Container{
+		ID:     id,
+		Names:  []string{"/" + opt.Name},
+		Image:  opt.Config.Image,
+		State:  "created",
+		Status: "Created",
+		Mounts: mounts,
+	})
+	return id, nil
+}
+
+// NewContainerOptions generate container options to create container from spec
+func (f *Fake) NewContainerOptions(spec ContainerSpec) (CreateContainerOptions, error) {
+	image, err := f.InspectImage(spec.Image)
+	if err != nil {
+		return CreateContainerOptions{}, err
+	}
+	return newContainerOptions(image, spec)
+}
+
+// CommitContainer commit container
+func (f *Fake) CommitContainer(name string, opt types.ContainerCommitOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CommitContainer", name, opt.Reference); err != nil {
+		return err
+	}
+
+	if f.containerIndex(name) < 0 {
+		return fmt.Errorf("No such container: %s", name)
+	}
+
+	f.ImageItems = append(f.ImageItems, types.ImageSummary{
+		ID:       fmt.Sprintf("sha256:%064d", len(f.ImageItems)+1),
+		RepoTags: []string{opt.Reference},
+	})
+	return nil
+}
+
+// RemoveContainer remove container
+func (f *Fake) RemoveContainer(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveContainer", name); err != nil {
+		return err
+	}
+
+	i := f.containerIndex(name)
+	if i < 0 {
+		return fmt.Errorf("No such container: %s", name)
+	}
+	f.ContainerItems = append(f.ContainerItems[:i], f.ContainerItems[i+1:]...)
+	return nil
+}
+
+// KillContainer send the signal, the container exits with KILL
+func (f *Fake) KillContainer(name, signal string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("KillContainer", name, signal); err != nil {
+		return err
+	}
+
+	signal, err := ParseSignal(signal)
+	if err != nil {
+		return err
+	}
+	if signal == "KILL" || signal == "9" {
+		return f.setContainerState(name, "exited", "Exited (137)")
+	}
+	if i := f.containerIndex(name); i < 0 {
+		return fmt.Errorf("No such container: %s", name)
+	}
+	return nil
+}
+
+// RenameContainer rename container
+func (f *Fake) RenameContainer(id, newName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RenameContainer", id, newName); err != nil {
+		return err
+	}
+
+	i := f.containerIndex(id)
+	if i < 0 {
+		return fmt.Errorf("No such container: %s", id)
+	}
+	f.ContainerItems[i].Names = []string{"/" + newName}
+	return nil
+}
+
+// StartContainer start container with id
+func (f *Fake) StartContainer(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("StartContainer", id); err != nil {
+		return err
+	}
+	return f.setContainerState(id, "running", "Up Less than a second")
+}
+
+// timeoutArgs arguments of Calls, nil timeout is not recorded
+func timeoutArgs(id string, timeout *time.Duration) []string {
+	if timeout == nil {
+		return []string{id}
+	}
+	return []string{id, timeout.String()}
+}
+
+// StopContainer stop container with id
+func (f *Fake) StopContainer(id string, timeout *time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("StopContainer", timeoutArgs(id, timeout)...); err != nil {
+		return err
+	}
+	return f.setContainerState(id, "exited", "Exited (0)")
+}
+
+// RestartContainer restart container with id
+func (f *Fake) RestartContainer(id string, timeout *time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RestartContainer", timeoutArgs(id, timeout)...); err != nil {
+		return err
+	}
+	return f.setContainerState(id, "running", "Up Less than a second")
+}
+
+// PauseContainer pause the running container
+func (f *Fake) PauseContainer(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("PauseContainer", id); err != nil {
+		return err
+	}
+	if i := f.containerIndex(id); i >= 0 && f.ContainerItems[i].State != "running" {
+		return fmt.Errorf("Container %s is not running", id)
+	}
+	return f.setContainerState(id, "paused", "Up Less than a second (Paused)")
+}
+
+// UnpauseContainer unpause the paused container
+func (f *Fake) UnpauseContainer(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("UnpauseContainer", id); err != nil {
+		return err
+	}
+	if i := f.containerIndex(id); i >= 0 && f.ContainerItems[i].State != "paused" {
+		return fmt.Errorf("Container %s is not paused", id)
+	}
+	return f.setContainerState(id, "running", "Up Less than a second")
+}
+
+// ExportContainer export container
+func (f *Fake) ExportContainer(name, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call("ExportContainer", name, path)
+}
+
+// CreateExec container exec create
+func (f *Fake) CreateExec(container string, opt ExecOptions) (types.IDResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateExec", append([]string{container}, opt.Cmd...)...); err != nil {
+		return types.IDResponse{}, err
+	}
+	return types.IDResponse{ID: "exec-" + container}, nil
+}
+
+// StartExecSession start the session which echoes back the input like cat
+func (f *Fake) StartExecSession(container string, opt ExecOptions) (ExecSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("StartExecSession", append([]string{container}, opt.Cmd...)...); err != nil {
+		return nil, err
+	}
+	if f.containerIndex(container) < 0 {
+		return nil, fmt.Errorf("No such container: %s", container)
+	}
+
+	r, w := io.Pipe()
+	return &fakeSession{f: f, r: r, w: w}, nil
+}
+
+type fakeSession struct {
+	f *Fake
+	r *io.PipeReader
+	w *io.PipeWriter
+}
+
+func (s *fakeSession) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+func (s *fakeSession) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
+
+func (s *fakeSession) Close() error {
+	s.w.Close()
+	return s.r.Close()
+}
+
+func (s *fakeSession) Resize(height, width uint) error {
+	s.f.mu.Lock()
+	defer s.f.mu.Unlock()
+	return s.f.call("ResizeExecSession", fmt.Sprint(height), fmt.Sprint(width))
+}
+
+// AttachContainer attach to the main process of the container
+func (f *Fake) AttachContainer(id, detachKeys string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("AttachContainer", id, detachKeys); err != nil {
+		return err
+	}
+
+	i := f.containerIndex(id)
+	if i < 0 {
+		return fmt.Errorf("No such container: %s", id)
+	}
+	if f.ContainerItems[i].State != "running" {
+		return fmt.Errorf("container %s is not running", strings.TrimPrefix(f.ContainerItems[i].Names[0], "/"))
+	}
+	return nil
+}
+
+// ContainerLogStream returns the container logs multiplexed like the docker api.
+func (f *Fake) ContainerLogStream(ctx context.Context, name string, opt LogOptions) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ContainerLogStream", name); err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if logs := f.Logs[name]; logs != "" {
+		if _, err := stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte(logs)); err != nil {
+			return nil, err
+		}
+	}
+	return ioutil.NopCloser(buf), nil
+}
+
+// StreamLogs call handler for each line of the container logs
+func (f *Fake) StreamLogs(ctx context.Context, name string, opt LogOptions, handler func(LogLine)) error {
+	reader, err := f.ContainerLogStream(ctx, name, opt)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return ReadLogLines(reader, false, handler)
+}
+
+// WatchStats call handler with StatsItems of the container and wait until ctx is done
+func (f *Fake) WatchStats(ctx context.Context, id string, handler func(Stats)) error {
+	f.mu.Lock()
+	err := f.call("WatchStats", id)
+	stats := f.StatsItems[id]
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		handler(s)
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// TopContainer return Processes of the container
+func (f *Fake) TopContainer(ctx context.Context, id, psArgs string) (container.ContainerTopOKBody, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("TopContainer", id, psArgs); err != nil {
+		return container.ContainerTopOKBody{}, err
+	}
+	if f.containerIndex(id) < 0 {
+		return container.ContainerTopOKBody{}, fmt.Errorf("no such container: %s", id)
+	}
+	return f.Processes[id], nil
+}
+
+// SignalProcess record the signal
+func (f *Fake) SignalProcess(ctx context.Context, id string, pid int, signal string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := killCommand(pid, signal); err != nil {
+		return err
+	}
+	return f.call("SignalProcess", id, fmt.Sprint(pid), signal)
+}
+
+// ContainerPID translate the PID on the host with ProcDir, or by matching Processes and the processes listed
+// in ExecResults
+func (f *Fake) ContainerPID(ctx context.Context, id string, pid int) (int, error) {
+	f.mu.Lock()
+	err := f.call("ContainerPID", id, fmt.Sprint(pid))
+	proc := f.ProcDir
+	f.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	return containerPID(ctx, f, proc, id, pid)
+}
+
+// ExecCommand return the result in ExecResults
+func (f *Fake) ExecCommand(ctx context.Context, id string, opt ExecOptions) (ExecResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ExecCommand", append([]string{id}, opt.Cmd...)...); err != nil {
+		return ExecResult{}, err
+	}
+
+	i := f.containerIndex(id)
+	if i < 0 {
+		return ExecResult{}, fmt.Errorf("No such container: %s", id)
+	}
+	if f.ContainerItems[i].State != "running" {
+		return ExecResult{}, fmt.Errorf("container %s is not running", strings.TrimPrefix(f.ContainerItems[i].Names[0], "/"))
+	}
+	return f.ExecResults[f.ContainerItems[i].ID], nil
+}
+
+// archive make tar stream of the file or directory in Files like CopyFromContainer.
+// caller must hold f.mu.
+func (f *Fake) archive(id, p string) (io.Reader, error) {
+	files := f.Files[id]
+	p = path.Clean(p)
+
+	top := path.Base(p)
+	if p == "/" {
+		top = "."
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	if content, ok := files[p]; ok {
+		tw.WriteHeader(&tar.Header{Name: top, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+		tw.Close()
+		return &buf, nil
+	}
+
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	var names []string
+	for name := range files {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no such file or directory: %s", p)
+	}
+	sort.Strings(names)
+
+	tw.WriteHeader(&tar.Header{Name: top + "/", Mode: 0755, Typeflag: tar.TypeDir})
+	dirs := make(map[string]bool)
+	for _, name := range names {
+		rel := strings.TrimPrefix(name, prefix)
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			if !dirs[dir] {
+				dirs[dir] = true
+				tw.WriteHeader(&tar.Header{Name: path.Join(top, dir) + "/", Mode: 0755, Typeflag: tar.TypeDir})
+			}
+		}
+		content := files[name]
+		tw.WriteHeader(&tar.Header{Name: path.Join(top, rel), Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+
+	return &buf, nil
+}
+
+// ListDir list the directory in Files
+func (f *Fake) ListDir(ctx context.Context, id, dir string) ([]FileEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ListDir", id, dir); err != nil {
+		return nil, err
+	}
+
+	r, err := f.archive(id, dir)
+	if err != nil {
+		return nil, err
+	}
+	return readDirEntries(r)
+}
+
+// ReadFile read the file in Files
+func (f *Fake) ReadFile(ctx context.Context, id, file string, limit int64) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ReadFile", id, file); err != nil {
+		return nil, false, err
+	}
+
+	r, err := f.archive(id, file)
+	if err != nil {
+		return nil, false, err
+	}
+	return readTarFile(r, limit)
+}
+
+// DownloadFromContainer extract the file or directory in Files to dst
+func (f *Fake) DownloadFromContainer(ctx context.Context, id, src, dst string, progress func(int64)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DownloadFromContainer", id, src, dst); err != nil {
+		return err
+	}
+
+	r, err := f.archive(id, src)
+	if err != nil {
+		return err
+	}
+	return extractTar(r, dst, progress)
+}
+
+// UploadToContainer add the host files to Files
+func (f *Fake) UploadToContainer(ctx context.Context, id, src, dstDir string, progress func(int64)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("UploadToContainer", id, src, dstDir); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := writeTar(&buf, src, progress); err != nil {
+		return err
+	}
+
+	if f.Files[id] == nil {
+		f.Files[id] = make(map[string]string)
+	}
+
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		f.Files[id][path.Join(dstDir, hdr.Name)] = string(data)
+	}
+}
+
+// DiffContainer return Changes of the container
+func (f *Fake) DiffContainer(ctx context.Context, id string) ([]container.ContainerChangeResponseItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DiffContainer", id); err != nil {
+		return nil, err
+	}
+	return f.Changes[id], nil
+}
+
+// ExportChanges write the changed files in Files to path.
+// changed paths which are not in Files are written as directories.
+func (f *Fake) ExportChanges(ctx context.Context, id, path string, progress func(int64)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ExportChanges", id, path); err != nil {
+		return err
+	}
+
+	return writeChangesFile(path, f.Changes[id], progress, func(p string) (*tar.Header, io.ReadCloser, error) {
+		content, ok := f.Files[id][p]
+		if !ok {
+			return &tar.Header{Typeflag: tar.TypeDir, Mode: 0755}, ioutil.NopCloser(strings.NewReader("")), nil
+		}
+		hdr := &tar.Header{Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}
+		return hdr, ioutil.NopCloser(strings.NewReader(content)), nil
+	})
+}
+
+// Images get images
+func (f *Fake) Images(opt types.ImageListOptions) ([]types.ImageSummary, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Images"); err != nil {
+		return nil, err
+	}
+
+	images := make([]types.ImageSummary, len(f.ImageItems))
+	copy(images, f.ImageItems)
+	return images, nil
+}
+
+// InspectImage inspect image
+func (f *Fake) InspectImage(name string) (types.ImageInspect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("InspectImage", name); err != nil {
+		return types.ImageInspect{}, err
+	}
+
+	if img, ok := f.ImageInspects[name]; ok {
+		return img, nil
+	}
+
+	i := f.imageIndex(name)
+	if i < 0 {
+		return types.ImageInspect{}, fmt.Errorf("No such image: %s", name)
+	}
+
+	img := f.ImageItems[i]
+	return types.ImageInspect{
+		ID:       img.ID,
+		RepoTags: img.RepoTags,
+		Size:     img.Size,
+	}, nil
+}
+
+// HistoryImage return ImageHistories of the image
+func (f *Fake) HistoryImage(ctx context.Context, name string) ([]image.HistoryResponseItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("HistoryImage", name); err != nil {
+		return nil, err
+	}
+
+	i := f.imageIndex(name)
+	if i < 0 {
+		return nil, fmt.Errorf("no such image: %s", name)
+	}
+	return f.ImageHistories[f.ImageItems[i].ID], nil
+}
+
+// BuildImage write the build context, call output with BuildOutput and add the image
+func (f *Fake) BuildImage(ctx context.Context, opt BuildOptions, output func(string)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("BuildImage", opt.ContextDir, strings.Join(opt.Tags, ",")); err != nil {
+		return err
+	}
+
+	if err := writeBuildContext(ioutil.Discard, opt); err != nil {
+		return err
+	}
+
+	for _, line := range f.BuildOutput {
+		output(line)
+	}
+
+	f.ImageItems = append(f.ImageItems, types.ImageSummary{
+		ID:       fmt.Sprintf("sha256:%064d", len(f.ImageItems)),
+		RepoTags: opt.Tags,
+	})
+	return nil
+}
+
+// PullImage pull image, progress is notified with PullLayers as completed layers
+func (f *Fake) PullImage(ctx context.Context, name string, progress func(PullProgress)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("PullImage", name); err != nil {
+		return err
+	}
+
+	if progress != nil {
+		var p PullProgress
+		for _, l := range f.PullLayers {
+			p.Layers = append(p.Layers, &LayerProgress{
+				ID:         l.ID,
+				Status:     "Pull complete",
+				Size:       l.Size,
+				Downloaded: l.Size,
+				Extracted:  l.Size,
+			})
+		}
+		progress(p)
+	}
+
+	if f.imageIndex(name) < 0 {
+		f.ImageItems = append(f.ImageItems, types.ImageSummary{
+			ID:       fmt.Sprintf("sha256:%064d", len(f.ImageItems)+1),
+			RepoTags: []string{name},
+		})
+	}
+	return nil
+}
+
+// RemoveImage remove image
+func (f *Fake) RemoveImage(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveImage", name); err != nil {
+		return err
+	}
+
+	i := f.imageIndex(name)
+	if i < 0 {
+		return fmt.Errorf("No such image: %s", name)
+	}
+
+	// the tag is removed when the image has other tags
+	if tags := f.ImageItems[i].RepoTags; len(tags) > 1 {
+		for j, tag := range tags {
+			if tag == name {
+				f.ImageItems[i].RepoTags = append(append([]string{}, tags[:j]...), tags[j+1:]...)
+				return nil
+			}
+		}
+	}
+	f.ImageItems = append(f.ImageItems[:i], f.ImageItems[i+1:]...)
+	return nil
+}
+
+// RemoveDanglingImages remove dangling images
+func (f *Fake) RemoveDanglingImages() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveDanglingImages"); err != nil {
+		return err
+	}
+
+	images := make([]types.ImageSummary, 0, len(f.ImageItems))
+	for _, img := range f.ImageItems {
+		if len(img.RepoTags) == 0 || img.RepoTags[0] == "<none>:<none>" {
+			continue
+		}
+		images = append(images, img)
+	}
+	f.ImageItems = images
+	return nil
+}
+
+// SaveImage save image to tar file
+func (f *Fake) SaveImage(ids []string, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call("SaveImage", strings.Join(ids, ","), path)
+}
+
+// LoadImage load image from tar file
+func (f *Fake) LoadImage(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call("LoadImage", path)
+}
+
+// ImportImage import image
+func (f *Fake) ImportImage(name, tag, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ImportImage", name, tag, path); err != nil {
+		return err
+	}
+
+	f.ImageItems = append(f.ImageItems, types.ImageSummary{
+		ID:       fmt.Sprintf("sha256:%064d", len(f.ImageItems)+1),
+		RepoTags: []string{name + ":" + tag},
+	})
+	return nil
+}
+
+// SearchImage search images
+func (f *Fake) SearchImage(name string) ([]registry.SearchResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("SearchImage", name); err != nil {
+		return nil, err
+	}
+
+	var results []registry.SearchResult
+	for _, r := range f.SearchResults {
+		if strings.Contains(r.Name, name) {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// Volumes get volumes
+func (f *Fake) Volumes() ([]*types.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Volumes"); err != nil {
+		return nil, err
+	}
+
+	volumes := make([]*types.Volume, len(f.VolumeItems))
+	copy(volumes, f.VolumeItems)
+	return volumes, nil
+}
+
+// InspectVolume inspect volume
+func (f *Fake) InspectVolume(name string) (types.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("InspectVolume", name); err != nil {
+		return types.Volume{}, err
+	}
+
+	for _, v := range f.VolumeItems {
+		if v.Name == name {
+			return *v, nil
+		}
+	}
+	return types.Volume{}, fmt.Errorf("get %s: no such volume", name)
+}
+
+// RemoveVolume remove volume
+func (f *Fake) RemoveVolume(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveVolume", name); err != nil {
+		return err
+	}
+
+	for i, v := range f.VolumeItems {
+		if v.Name == name {
+			f.VolumeItems = append(f.VolumeItems[:i], f.VolumeItems[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("get %s: no such volume", name)
+}
+
+// PruneVolumes remove unused volume
+func (f *Fake) PruneVolumes() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call("PruneVolumes")
+}
+
+// CreateVolume create volume
+func (f *Fake) CreateVolume(opt volumetypes.VolumeCreateBody) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateVolume", opt.Name); err != nil {
+		return err
+	}
+
+	driver := opt.Driver
+	if driver == "" {
+		driver = "local"
+	}
+
+	f.VolumeItems = append(f.VolumeItems, &types.Volume{
+		Name:       opt.Name,
+		Driver:     driver,
+		Labels:     opt.Labels,
+		Options:    opt.DriverOpts,
+		Mountpoint: "/var/lib/docker/volumes/" + opt.Name + "/_data",
+	})
+	return nil
+}
+
+// NewCreateVolumeOptions generate options to create volume
+func (f *Fake) NewCreateVolumeOptions(data map[string]string) volumetypes.VolumeCreateBody {
+	return newCreateVolumeOptions(data)
+}
+
+// Networks get networks
+func (f *Fake) Networks(opt types.NetworkListOptions) ([]types.NetworkResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("Networks"); err != nil {
+		return nil, err
+	}
+
+	networks := make([]types.NetworkResource, len(f.NetworkItems))
+	copy(networks, f.NetworkItems)
+	return networks, nil
+}
+
+// InspectNetwork inspect network
+func (f *Fake) InspectNetwork(name string) (types.NetworkResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("InspectNetwork", name); err != nil {
+		return types.NetworkResource{}, err
+	}
+
+	for _, n := range f.NetworkItems {
+		if n.ID == name || n.Name == name {
+			return n, nil
+		}
+	}
+	return types.NetworkResource{}, fmt.Errorf("network %s not found", name)
+}
+
+// RemoveNetwork remove network
+func (f *Fake) RemoveNetwork(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveNetwork", name); err != nil {
+		return err
+	}
+
+	for i, n := range f.NetworkItems {
+		if n.ID == name || n.Name == name {
+			f.NetworkItems = append(f.NetworkItems[:i], f.NetworkItems[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("network %s not found", name)
+}
+
+// networkIndex find the network by id or name. caller must hold f.mu.
+func (f *Fake) networkIndex(name string) int {
+	for i, n := range f.NetworkItems {
+		if n.ID == name || n.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// CreateNetwork create network
+func (f *Fake) CreateNetwork(name string, opt types.NetworkCreate) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateNetwork", name); err != nil {
+		return "", err
+	}
+	if f.networkIndex(name) >= 0 {
+		return "", fmt.Errorf("network with name %s already exists", name)
+	}
+
+	driver := opt.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+
+	n := types.NetworkResource{
+		ID:         fmt.Sprintf("%064d", len(f.NetworkItems)+1),
+		Name:       name,
+		Driver:     driver,
+		Scope:      "local",
+		EnableIPv6: opt.EnableIPv6,
+		Internal:   opt.Internal,
+		Attachable: opt.Attachable,
+		Labels:     opt.Labels,
+		Options:    opt.Options,
+	}
+	if opt.IPAM != nil {
+		n.IPAM = *opt.IPAM
+	}
+	f.NetworkItems = append(f.NetworkItems, n)
+	return n.ID, nil
+}
+
+// ConnectNetwork connect container to network, the address is assigned from 172.18.0.0/16
+func (f *Fake) ConnectNetwork(name, container string, settings *dockernetwork.EndpointSettings) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("ConnectNetwork", name, container); err != nil {
+		return err
+	}
+
+	ni := f.networkIndex(name)
+	if ni < 0 {
+		return fmt.Errorf("network %s not found", name)
+	}
+	ci := f.containerIndex(container)
+	if ci < 0 {
+		return fmt.Errorf("No such container: %s", container)
+	}
+
+	n := &f.NetworkItems[ni]
+	c := f.ContainerItems[ci]
+	if _, ok := n.Containers[c.ID]; ok {
+		return fmt.Errorf("endpoint with name %s already exists in network %s", strings.TrimPrefix(c.Names[0], "/"), n.Name)
+	}
+	if n.Containers == nil {
+		n.Containers = make(map[string]types.EndpointResource)
+	}
+
+	endpoint := types.EndpointResource{
+		Name:        strings.TrimPrefix(c.Names[0], "/"),
+		IPv4Address: fmt.Sprintf("172.18.0.%d/16", len(n.Containers)+2),
+	}
+	if settings != nil && settings.IPAMConfig != nil {
+		if ip := settings.IPAMConfig.IPv4Address; ip != "" {
+			endpoint.IPv4Address = ip + "/16"
+		}
+		if ip := settings.IPAMConfig.IPv6Address; ip != "" {
+			endpoint.IPv4Address = ""
+			endpoint.IPv6Address = ip + "/64"
+		}
+	}
+	n.Containers[c.ID] = endpoint
+	return nil
+}
+
+// DisconnectNetwork disconnect container from network
+func (f *Fake) DisconnectNetwork(name, container string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DisconnectNetwork", name, container); err != nil {
+		return err
+	}
+
+	ni := f.networkIndex(name)
+	if ni < 0 {
+		return fmt.Errorf("network %s not found", name)
+	}
+	ci := f.containerIndex(container)
+	if ci < 0 {
+		return fmt.Errorf("No such container: %s", container)
+	}
+
+	id := f.ContainerItems[ci].ID
+	if _, ok := f.NetworkItems[ni].Containers[id]; !ok {
+		return fmt.Errorf("container %s is not connected to network %s", container, f.NetworkItems[ni].Name)
+	}
+	delete(f.NetworkItems[ni].Containers, id)
+	return nil
+}
+
+// volumeIndex find the volume by name. caller must hold f.mu.
+func (f *Fake) volumeIndex(name string) int {
+	for i, v := range f.VolumeItems {
+		if v.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ensureVolume create the volume when it does not exist like mounting it. caller must hold f.mu.
+func (f *Fake) ensureVolume(name string) {
+	if f.volumeIndex(name) >= 0 {
+		return
+	}
+	f.VolumeItems = append(f.VolumeItems, &types.Volume{
+		Name:       name,
+		Driver:     "local",
+		Mountpoint: "/var/lib/docker/volumes/" + name + "/_data",
+	})
+}
+
+// CreateVolumeHelper create the container whose Files are VolumeFiles under VolumeHelperMount
+func (f *Fake) CreateVolumeHelper(ctx context.Context, volume string, readOnly bool) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CreateVolumeHelper", volume); err != nil {
+		return "", err
+	}
+	f.ensureVolume(volume)
+
+	id := fmt.Sprintf("%064d", len(f.ContainerItems)+1)
+	f.ContainerItems = append(f.ContainerItems, types.Container{
+		ID:     id,
+		Names:  []string{"/" + id[:12]},
+		Image:  VolumeHelperImage,
+		Labels: map[string]string{volumeHelperLabel: volume},
+		State:  "created",
+		Status: "Created",
+	})
+
+	files := make(map[string]string)
+	for p, content := range f.VolumeFiles[volume] {
+		files[path.Join(VolumeHelperMount, p)] = content
+	}
+	f.Files[id] = files
+	return id, nil
+}
+
+// RemoveVolumeHelpers remove containers which have the helper label
+func (f *Fake) RemoveVolumeHelpers(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveVolumeHelpers"); err != nil {
+		return err
+	}
+
+	containers := f.ContainerItems[:0]
+	for _, c := range f.ContainerItems {
+		if _, ok := c.Labels[volumeHelperLabel]; !ok {
+			containers = append(containers, c)
+		}
+	}
+	f.ContainerItems = containers
+	return nil
+}
+
+// BackupVolume write VolumeFiles of the volume to the tar file
+func (f *Fake) BackupVolume(ctx context.Context, volume, file string, compress bool, progress func(int64)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("BackupVolume", volume, file); err != nil {
+		return err
+	}
+	if f.volumeIndex(volume) < 0 {
+		return fmt.Errorf("get %s: no such volume", volume)
+	}
+
+	files := make(map[string]string)
+	for p, content := range f.VolumeFiles[volume] {
+		files[path.Join(VolumeHelperMount, p)] = content
+	}
+	f.Files["backup"] = files
+	defer delete(f.Files, "backup")
+
+	var r io.Reader
+	if len(files) == 0 {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		tw.WriteHeader(&tar.Header{Name: path.Base(VolumeHelperMount) + "/", Mode: 0755, Typeflag: tar.TypeDir})
+		tw.Close()
+		r = &buf
+	} else {
+		var err error
+		if r, err = f.archive("backup", VolumeHelperMount); err != nil {
+			return err
+		}
+	}
+	return writeBackupFile(file, r, compress, progress)
+}
+
+// RestoreVolume extract the tar file into VolumeFiles of the volume
+func (f *Fake) RestoreVolume(ctx context.Context, volume, file string, progress func(int64)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RestoreVolume", volume, file); err != nil {
+		return err
+	}
+
+	data, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+
+	r, err := backupReader(data, progress)
+	if err != nil {
+		return err
+	}
+
+	f.ensureVolume(volume)
+	if f.VolumeFiles[volume] == nil {
+		f.VolumeFiles[volume] = make(map[string]string)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		f.VolumeFiles[volume][path.Join("/", hdr.Name)] = string(content)
+	}
+}
+
+// CloneVolume copy the volume and its VolumeFiles
+func (f *Fake) CloneVolume(ctx context.Context, src, dst string, progress func(string)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("CloneVolume", src, dst); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	i := f.volumeIndex(src)
+	if i < 0 {
+		return fmt.Errorf("get %s: no such volume", src)
+	}
+	if f.volumeIndex(dst) >= 0 {
+		return fmt.Errorf("volume %s already exists", dst)
+	}
+
+	progress("create volume " + dst)
+	clone := *f.VolumeItems[i]
+	clone.Name = dst
+	clone.Mountpoint = "/var/lib/docker/volumes/" + dst + "/_data"
+	f.VolumeItems = append(f.VolumeItems, &clone)
+
+	progress("copy " + src + " to " + dst)
+	files := make(map[string]string)
+	for p, content := range f.VolumeFiles[src] {
+		files[p] = content
+	}
+	f.VolumeFiles[dst] = files
+	return nil
+}
+
+// diskUsage make the disk usage from the state. caller must hold f.mu.
+func (f *Fake) diskUsage() types.DiskUsage {
+	var du types.DiskUsage
+
+	for i := range f.ImageItems {
+		img := f.ImageItems[i]
+		img.Containers = 0
+		for _, c := range f.ContainerItems {
+			if c.ImageID == img.ID || (c.ImageID == "" && f.imageIndex(c.Image) == i) {
+				img.Containers++
+			}
+		}
+		du.LayersSize += img.Size
+		du.Images = append(du.Images, &img)
+	}
+
+	for i := range f.ContainerItems {
+		c := f.ContainerItems[i]
+		du.Containers = append(du.Containers, &c)
+	}
+
+	for _, v := range f.VolumeItems {
+		volume := *v
+		volume.UsageData = &types.VolumeUsageData{}
+		for _, content := range f.VolumeFiles[v.Name] {
+			volume.UsageData.Size += int64(len(content))
+		}
+		for _, c := range f.ContainerItems {
+			for _, m := range c.Mounts {
+				if m.Name == v.Name {
+					volume.UsageData.RefCount++
+				}
+			}
+		}
+		du.Volumes = append(du.Volumes, &volume)
+	}
+
+	for _, b := range f.BuildCacheItems {
+		cache := *b
+		du.BuildCache = append(du.BuildCache, &cache)
+	}
+	return du
+}
+
+// DiskUsage get the disk usage made from the state
+func (f *Fake) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("DiskUsage"); err != nil {
+		return types.DiskUsage{}, err
+	}
+	return f.diskUsage(), nil
+}
+
+// RemoveBuildCache remove the record in BuildCacheItems
+func (f *Fake) RemoveBuildCache(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call("RemoveBuildCache", id); err != nil {
+		return err
+	}
+
+	for i, b := range f.BuildCacheItems {
+		if b.ID == id {
+			if b.InUse {
+				return fmt.Errorf("build cache %s is in use", id)
+			}
+			f.BuildCacheItems = append(f.BuildCacheItems[:i], f.BuildCacheItems[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/skanehira/docui/common"
+)
+
+// FileEntry file in the container
+type FileEntry struct {
+	Name       string
+	Size       int64
+	Mode       os.FileMode
+	ModTime    time.Time
+	LinkTarget string
+}
+
+// IsDir reports whether the entry is a directory
+func (e FileEntry) IsDir() bool {
+	return e.Mode.IsDir()
+}
+
+// listDirWorkers max number of files stat'ed at once
+const listDirWorkers = 8
+
+// ListDir list files in the directory of the container.
+// the archive of the directory contains the whole subtree, so names are listed with find
+// in running containers and each file is stat'ed. the archive is used only for stopped
+// containers and containers without find.
+func (d *Docker) ListDir(ctx context.Context, id, dir string) ([]FileEntry, error) {
+	stat, err := d.ContainerStatPath(ctx, id, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// follow the link to the directory, e.g. /bin -> /usr/bin
+	if stat.Mode&os.ModeSymlink != 0 && stat.LinkTarget != "" {
+		dir = stat.LinkTarget
+		if stat, err = d.ContainerStatPath(ctx, id, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	if !stat.Mode.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	if c, err := d.ContainerInspect(ctx, id); err == nil && c.State != nil && c.State.Running {
+		entries, err := d.statDir(ctx, id, dir)
+		if err == nil || ctx.Err() != nil {
+			return entries, err
+		}
+		common.Logger.Errorf("cannot list %s with find, read the archive instead %s", dir, err)
+	}
+
+	reader, _, err := d.CopyFromContainer(ctx, id, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readDirEntries(reader)
+}
+
+// statDir list names in dir with find, and stat each file
+func (d *Docker) statDir(ctx context.Context, id, dir string) ([]FileEntry, error) {
+	result, err := d.ExecCommand(ctx, id, ExecOptions{Cmd: []string{"find", dir, "-mindepth", "1", "-maxdepth", "1", "-print0"}})
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("find exited with %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+
+	names := parseFindNames(result.Stdout)
+
+	entries := make([]FileEntry, len(names))
+	errs := make([]error, len(names))
+	workers := make(chan struct{}, listDirWorkers)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			stat, err := d.ContainerStatPath(ctx, id, path.Join(dir, name))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			entries[i] = FileEntry{
+				Name:       name,
+				Size:       stat.Size,
+				Mode:       stat.Mode,
+				ModTime:    stat.Mtime,
+				LinkTarget: stat.LinkTarget,
+			}
+		}(i, name)
+	}
+	wg.Wait()
+
+	var listed []FileEntry
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			listed = append(listed, entries[i])
+		case client.IsErrNotFound(err):
+			// removed after ls
+		default:
+			return nil, err
+		}
+	}
+
+	sortEntries(listed)
+	return listed, nil
+}
+
+// parseFindNames return the names of the paths printed by find -print0, names may contain newlines
+func parseFindNames(out string) []string {
+	var names []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			names = append(names, path.Base(p))
+		}
+	}
+	return names
+}
+
+// readDirEntries read the files just under the top directory of the archive
+func readDirEntries(r io.Reader) ([]FileEntry, error) {
+	tr := tar.NewReader(r)
+
+	var root string
+	var entries []FileEntry
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		// the first entry is the directory itself
+		if root == "" {
+			root = name
+			continue
+		}
+
+		if path.Dir(name) != root {
+			continue
+		}
+
+		entries = append(entries, FileEntry{
+			Name:       path.Base(name),
+			Size:       hdr.Size,
+			Mode:       hdr.FileInfo().Mode(),
+			ModTime:    hdr.ModTime,
+			LinkTarget: hdr.Linkname,
+		})
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// sortEntries sort entries by name, directories first
+func sortEntries(entries []FileEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// ReadFile read the file in the container up to limit bytes.
+// truncated is true when the file is larger than limit.
+func (d *Docker) ReadFile(ctx context.Context, id, file string, limit int64) (data []byte, truncated bool, err error) {
+	reader, _, err := d.CopyFromContainer(ctx, id, file)
+	if err != nil {
+		return nil, false, err
+	}
+	defer reader.Close()
+
+	return readTarFile(reader, limit)
+}
+
+// readTarFile read the first file of the archive up to limit bytes
+func readTarFile(r io.Reader, limit int64) ([]byte, bool, error) {
+	tr := tar.NewReader(r)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+		return nil, false, fmt.Errorf("%s is not a regular file", hdr.Name)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(tr, limit))
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, hdr.Size > limit, nil
+}
+
+// DownloadFromContainer copy src file or directory in the container to dst on the host.
+// progress is called with the copied bytes.
+func (d *Docker) DownloadFromContainer(ctx context.Context, id, src, dst string, progress func(int64)) error {
+	reader, _, err := d.CopyFromContainer(ctx, id, src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := extractTar(reader, dst, progress); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// UploadToContainer copy src file or directory on the host into dstDir in the container.
+// progress is called with the copied bytes.
+func (d *Docker) UploadToContainer(ctx context.Context, id, src, dstDir string, progress func(int64)) error {
+	stat, err := d.ContainerStatPath(ctx, id, dstDir)
+	if err != nil {
+		return err
+	}
+	if !stat.Mode.IsDir() && stat.Mode&os.ModeSymlink == 0 {
+		return fmt.Errorf("%s is not a directory", dstDir)
+	}
+
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeTar(writer, src, progress))
+	}()
+
+	err = d.CopyToContainer(ctx, id, dstDir, reader, types.CopyToContainerOptions{})
+	// stop writing when docker stopped reading
+	reader.CloseWithError(err)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,430 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/skanehira/docui/common"
+)
+
+// SpecFromInspect reconstruct the spec of the container.
+// settings which are inherited from the image are omitted.
+func SpecFromInspect(c types.ContainerJSON, image types.ImageInspect) ContainerSpec {
+	var spec ContainerSpec
+	imageConfig := image.Config
+	if imageConfig == nil {
+		imageConfig = &container.Config{}
+	}
+
+	if c.ContainerJSONBase != nil {
+		spec.Name = strings.TrimPrefix(c.Name, "/")
+	}
+
+	if config := c.Config; config != nil {
+		spec.Image = config.Image
+		spec.Tty = config.Tty
+		spec.OpenStdin = config.OpenStdin
+
+		if config.User != imageConfig.User {
+			spec.User = config.User
+		}
+		if config.WorkingDir != imageConfig.WorkingDir {
+			spec.WorkingDir = config.WorkingDir
+		}
+		if !equalStrings(config.Entrypoint, imageConfig.Entrypoint) {
+			spec.Entrypoint = config.Entrypoint
+		}
+		// the image cmd is reset when the entrypoint is changed
+		if !equalStrings(config.Cmd, imageConfig.Cmd) || spec.Entrypoint != nil {
+			spec.Cmd = config.Cmd
+		}
+
+		imageEnv := make(map[string]bool)
+		for _, env := range imageConfig.Env {
+			imageEnv[env] = true
+		}
+		for _, env := range config.Env {
+			if !imageEnv[env] {
+				spec.Env = append(spec.Env, env)
+			}
+		}
+
+		for k, v := range config.Labels {
+			if iv, ok := imageConfig.Labels[k]; ok && iv == v {
+				continue
+			}
+			if spec.Labels == nil {
+				spec.Labels = make(map[string]string)
+			}
+			spec.Labels[k] = v
+		}
+	}
+
+	if c.ContainerJSONBase == nil || c.HostConfig == nil {
+		return spec
+	}
+	hostConfig := c.HostConfig
+
+	var ports []string
+	for port := range hostConfig.PortBindings {
+		ports = append(ports, string(port))
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		p := PortBinding{ContainerPort: nat.Port(port).Port(), Protocol: nat.Port(port).Proto()}
+		if p.Protocol == "tcp" {
+			p.Protocol = ""
+		}
+
+		bindings := hostConfig.PortBindings[nat.Port(port)]
+		if len(bindings) == 0 {
+			spec.Ports = append(spec.Ports, p)
+		}
+		for _, b := range bindings {
+			p.HostIP, p.HostPort = b.HostIP, b.HostPort
+			if p.HostIP == "0.0.0.0" {
+				p.HostIP = ""
+			}
+			spec.Ports = append(spec.Ports, p)
+		}
+	}
+
+	targets := make(map[string]bool)
+	for _, bind := range hostConfig.Binds {
+		if m, err := parseBind(bind); err == nil {
+			spec.Mounts = append(spec.Mounts, m)
+			targets[m.Target] = true
+		}
+	}
+	for _, m := range hostConfig.Mounts {
+		spec.Mounts = append(spec.Mounts, MountSpec{
+			Type:     m.Type,
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		})
+		targets[m.Target] = true
+	}
+	// anonymous volumes, e.g. docker run -v /data
+	if c.Config != nil {
+		var volumes []string
+		for target := range c.Config.Volumes {
+			if _, ok := imageConfig.Volumes[target]; !ok && !targets[target] {
+				volumes = append(volumes, target)
+			}
+		}
+		for _, target := range common.SortKeys(volumes) {
+			spec.Mounts = append(spec.Mounts, MountSpec{Type: mount.TypeVolume, Target: target})
+		}
+	}
+
+	switch policy := hostConfig.RestartPolicy; {
+	case policy.Name == "" || policy.Name == "no":
+	case policy.Name == "on-failure" && policy.MaximumRetryCount > 0:
+		spec.RestartPolicy = fmt.Sprintf("on-failure:%d", policy.MaximumRetryCount)
+	default:
+		spec.RestartPolicy = policy.Name
+	}
+
+	if mode := string(hostConfig.NetworkMode); mode != "" && mode != "default" && mode != "bridge" {
+		spec.Network = mode
+		if c.NetworkSettings != nil {
+			if endpoint := c.NetworkSettings.Networks[mode]; endpoint != nil {
+				for _, alias := range endpoint.Aliases {
+					// docker adds the short container id as an alias
+					if len(c.ID) >= 12 && alias == c.ID[:12] {
+						continue
+					}
+					spec.Aliases = append(spec.Aliases, alias)
+				}
+			}
+		}
+	}
+
+	spec.Memory = hostConfig.Memory
+	spec.CPUs = float64(hostConfig.NanoCPUs) / 1e9
+	spec.CapAdd = hostConfig.CapAdd
+	spec.CapDrop = hostConfig.CapDrop
+	spec.Privileged = hostConfig.Privileged
+	spec.AutoRemove = hostConfig.AutoRemove
+
+	return spec
+}
+
+// parseBind parse bind of the host config, e.g. "/src:/app:ro,z".
+// options except for read only are ignored.
+func parseBind(bind string) (MountSpec, error) {
+	parts := strings.Split(bind, ":")
+	if len(parts) == 3 {
+		mode := "rw"
+		for _, opt := range strings.Split(parts[2], ",") {
+			if opt == "ro" {
+				mode = "ro"
+			}
+		}
+		parts[2] = mode
+	}
+	return ParseVolume(strings.Join(parts, ":"))
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RunCommandLine format the spec as docker run command line
+func RunCommandLine(spec ContainerSpec) string {
+	args := [][]string{{"docker", "run", "-d"}}
+	add := func(flag string, values ...string) {
+		for _, value := range values {
+			args = append(args, []string{flag, value})
+		}
+	}
+	flag := func(flag string, on bool) {
+		if on {
+			args = append(args, []string{flag})
+		}
+	}
+
+	if spec.Name != "" {
+		add("--name", spec.Name)
+	}
+	flag("--interactive", spec.OpenStdin)
+	flag("--tty", spec.Tty)
+	flag("--rm", spec.AutoRemove)
+	flag("--privileged", spec.Privileged)
+	if spec.User != "" {
+		add("--user", spec.User)
+	}
+	if spec.WorkingDir != "" {
+		add("--workdir", spec.WorkingDir)
+	}
+	add("--env", spec.Env...)
+	for _, p := range spec.Ports {
+		add("--publish", p.String())
+	}
+	for _, m := range spec.Mounts {
+		add("--mount", m.String())
+	}
+	add("--label", sortedLabels(spec.Labels)...)
+	if spec.Network != "" {
+		add("--network", spec.Network)
+	}
+	add("--network-alias", spec.Aliases...)
+	if spec.RestartPolicy != "" {
+		add("--restart", spec.RestartPolicy)
+	}
+	if spec.Memory > 0 {
+		add("--memory", FormatMemory(spec.Memory))
+	}
+	if spec.CPUs > 0 {
+		add("--cpus", strconv.FormatFloat(spec.CPUs, 'f', -1, 64))
+	}
+	add("--cap-add", spec.CapAdd...)
+	add("--cap-drop", spec.CapDrop...)
+
+	// docker run takes only one word as the entrypoint,
+	// the rest of the entrypoint is passed before the cmd.
+	cmd := spec.Cmd
+	if len(spec.Entrypoint) > 0 {
+		add("--entrypoint", spec.Entrypoint[0])
+		cmd = append(append([]string{}, spec.Entrypoint[1:]...), spec.Cmd...)
+	}
+
+	args = append(args, append([]string{spec.Image}, cmd...))
+
+	lines := make([]string, len(args))
+	for i, arg := range args {
+		lines[i] = common.JoinShellWords(arg)
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// ComposeService format the spec as docker-compose file with the service
+func ComposeService(spec ContainerSpec) string {
+	var b strings.Builder
+	line := func(indent int, format string, a ...interface{}) {
+		b.WriteString(strings.Repeat("  ", indent))
+		fmt.Fprintf(&b, format, a...)
+		b.WriteString("\n")
+	}
+	list := func(key string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		line(2, "%s:", key)
+		for _, v := range values {
+			line(3, "- %s", yamlString(v))
+		}
+	}
+
+	service := spec.Name
+	if service == "" {
+		service = "app"
+	}
+
+	line(0, "services:")
+	line(1, "%s:", yamlString(service))
+	line(2, "image: %s", yamlString(spec.Image))
+	if spec.Name != "" {
+		line(2, "container_name: %s", yamlString(spec.Name))
+	}
+	if spec.Entrypoint != nil {
+		line(2, "entrypoint: %s", yamlList(spec.Entrypoint))
+	}
+	if spec.Cmd != nil {
+		line(2, "command: %s", yamlList(spec.Cmd))
+	}
+	if spec.User != "" {
+		line(2, "user: %s", yamlString(spec.User))
+	}
+	if spec.WorkingDir != "" {
+		line(2, "working_dir: %s", yamlString(spec.WorkingDir))
+	}
+	list("environment", spec.Env)
+
+	if len(spec.Labels) > 0 {
+		line(2, "labels:")
+		for _, k := range common.SortKeys(labelKeys(spec.Labels)) {
+			line(3, "%s: %s", yamlString(k), yamlString(spec.Labels[k]))
+		}
+	}
+
+	var ports []string
+	for _, p := range spec.Ports {
+		ports = append(ports, p.String())
+	}
+	list("ports", ports)
+
+	var volumes []string
+	if len(spec.Mounts) > 0 {
+		line(2, "volumes:")
+		for _, m := range spec.Mounts {
+			line(3, "- type: %s", m.Type)
+			if m.Source != "" {
+				line(4, "source: %s", yamlString(m.Source))
+			}
+			line(4, "target: %s", yamlString(m.Target))
+			if m.ReadOnly {
+				line(4, "read_only: true")
+			}
+			if m.Type == mount.TypeVolume && m.Source != "" {
+				volumes = append(volumes, m.Source)
+			}
+		}
+	}
+
+	var networks []string
+	switch {
+	case spec.Network == "host" || spec.Network == "none" || strings.HasPrefix(spec.Network, "container:"):
+		line(2, "network_mode: %s", yamlString(spec.Network))
+	case spec.Network != "":
+		networks = append(networks, spec.Network)
+		line(2, "networks:")
+		if len(spec.Aliases) == 0 {
+			line(3, "%s: {}", yamlString(spec.Network))
+		} else {
+			line(3, "%s:", yamlString(spec.Network))
+			line(4, "aliases: %s", yamlList(spec.Aliases))
+		}
+	}
+
+	if spec.RestartPolicy != "" {
+		line(2, "restart: %s", yamlString(spec.RestartPolicy))
+	}
+	if spec.Memory > 0 {
+		line(2, "mem_limit: %s", yamlString(FormatMemory(spec.Memory)))
+	}
+	if spec.CPUs > 0 {
+		line(2, "cpus: %s", strconv.FormatFloat(spec.CPUs, 'f', -1, 64))
+	}
+	list("cap_add", spec.CapAdd)
+	list("cap_drop", spec.CapDrop)
+	if spec.Privileged {
+		line(2, "privileged: true")
+	}
+	if spec.Tty {
+		line(2, "tty: true")
+	}
+	if spec.OpenStdin {
+		line(2, "stdin_open: true")
+	}
+
+	// volumes and networks are created outside of the compose file
+	for _, top := range []struct {
+		key   string
+		names []string
+	}{
+		{"volumes", volumes},
+		{"networks", networks},
+	} {
+		if len(top.names) == 0 {
+			continue
+		}
+		line(0, "%s:", top.key)
+		for _, name := range top.names {
+			line(1, "%s:", yamlString(name))
+			line(2, "external: true")
+		}
+	}
+
+	return b.String()
+}
+
+// yamlString quote the string when it isn't a plain scalar of yaml
+func yamlString(s string) string {
+	plain := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '_' || r == '-' || r == '.' || r == '/')
+	}) < 0
+	// numbers and booleans are not strings
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		plain = false
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		plain = false
+	}
+
+	if plain {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+func yamlList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = yamlString(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func labelKeys(labels map[string]string) []string {
+	var keys []string
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func sortedLabels(labels map[string]string) []string {
+	var result []string
+	for _, k := range common.SortKeys(labelKeys(labels)) {
+		result = append(result, k+"="+labels[k])
+	}
+	return result
+}
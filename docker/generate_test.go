@@ -0,0 +1,190 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+func testInspect() (types.ContainerJSON, types.ImageInspect) {
+	image := types.ImageInspect{
+		Config: &container.Config{
+			Env:     []string{"PATH=/usr/bin", "NGINX_VERSION=1.15"},
+			Cmd:     []string{"nginx", "-g", "daemon off;"},
+			Labels:  map[string]string{"maintainer": "nginx"},
+			Volumes: map[string]struct{}{"/var/cache/nginx": {}},
+		},
+	}
+
+	c := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:   "0123456789abcdef",
+			Name: "/web",
+			HostConfig: &container.HostConfig{
+				Binds: []string{"/srv:/usr/share/nginx/html:ro,z"},
+				Mounts: []mount.Mount{
+					{Type: mount.TypeVolume, Source: "logs", Target: "/var/log/nginx"},
+				},
+				PortBindings: nat.PortMap{
+					"80/tcp": {{HostIP: "0.0.0.0", HostPort: "8080"}},
+					"53/udp": {{HostIP: "127.0.0.1", HostPort: "5353"}},
+				},
+				RestartPolicy: container.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+				NetworkMode:   "front",
+				Resources:     container.Resources{Memory: 512 << 20, NanoCPUs: 1500000000},
+				CapAdd:        []string{"NET_ADMIN"},
+			},
+		},
+		Config: &container.Config{
+			Image:   "nginx:latest",
+			Env:     []string{"PATH=/usr/bin", "NGINX_VERSION=1.15", "TZ=Asia/Tokyo"},
+			Cmd:     []string{"nginx", "-g", "daemon off;"},
+			Labels:  map[string]string{"maintainer": "nginx", "tier": "web"},
+			Volumes: map[string]struct{}{"/var/cache/nginx": {}, "/tmp/data": {}},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"front": {Aliases: []string{"www", "0123456789ab"}},
+			},
+		},
+	}
+
+	return c, image
+}
+
+func TestSpecFromInspect(t *testing.T) {
+	c, image := testInspect()
+
+	want := ContainerSpec{
+		Name:   "web",
+		Image:  "nginx:latest",
+		Env:    []string{"TZ=Asia/Tokyo"},
+		Labels: map[string]string{"tier": "web"},
+		Ports: []PortBinding{
+			{HostIP: "127.0.0.1", HostPort: "5353", ContainerPort: "53", Protocol: "udp"},
+			{HostPort: "8080", ContainerPort: "80"},
+		},
+		Mounts: []MountSpec{
+			{Type: mount.TypeBind, Source: "/srv", Target: "/usr/share/nginx/html", ReadOnly: true},
+			{Type: mount.TypeVolume, Source: "logs", Target: "/var/log/nginx"},
+			{Type: mount.TypeVolume, Target: "/tmp/data"},
+		},
+		RestartPolicy: "on-failure:3",
+		Network:       "front",
+		Aliases:       []string{"www"},
+		Memory:        512 << 20,
+		CPUs:          1.5,
+		CapAdd:        []string{"NET_ADMIN"},
+	}
+
+	got := SpecFromInspect(c, image)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, got)
+	}
+}
+
+func TestRunCommandLine(t *testing.T) {
+	c, image := testInspect()
+	spec := SpecFromInspect(c, image)
+	spec.Entrypoint = []string{"/entrypoint.sh", "--verbose"}
+	spec.Cmd = []string{"nginx", "-g", "daemon off;"}
+
+	got := RunCommandLine(spec)
+	want := `docker run -d \
+  --name web \
+  --env TZ=Asia/Tokyo \
+  --publish 127.0.0.1:5353:53/udp \
+  --publish 8080:80 \
+  --mount type=bind,source=/srv,target=/usr/share/nginx/html,readonly \
+  --mount type=volume,source=logs,target=/var/log/nginx \
+  --mount type=volume,target=/tmp/data \
+  --label tier=web \
+  --network front \
+  --network-alias www \
+  --restart on-failure:3 \
+  --memory 512m \
+  --cpus 1.5 \
+  --cap-add NET_ADMIN \
+  --entrypoint /entrypoint.sh \
+  nginx:latest --verbose nginx -g 'daemon off;'`
+	if got != want {
+		t.Errorf("Expected\n%s\nGot\n%s", want, got)
+	}
+
+	// the generated command line is parsed to the same spec
+	cmd, err := ParseRunCommand(got)
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if want := RunCommandLine(cmd.Spec); want != got {
+		t.Errorf("Expected\n%s\nGot\n%s", want, got)
+	}
+}
+
+func TestComposeService(t *testing.T) {
+	c, image := testInspect()
+
+	got := ComposeService(SpecFromInspect(c, image))
+	want := `services:
+  web:
+    image: "nginx:latest"
+    container_name: web
+    environment:
+      - "TZ=Asia/Tokyo"
+    labels:
+      tier: web
+    ports:
+      - "127.0.0.1:5353:53/udp"
+      - "8080:80"
+    volumes:
+      - type: bind
+        source: /srv
+        target: /usr/share/nginx/html
+        read_only: true
+      - type: volume
+        source: logs
+        target: /var/log/nginx
+      - type: volume
+        target: /tmp/data
+    networks:
+      front:
+        aliases: [www]
+    restart: "on-failure:3"
+    mem_limit: 512m
+    cpus: 1.5
+    cap_add:
+      - NET_ADMIN
+volumes:
+  logs:
+    external: true
+networks:
+  front:
+    external: true
+`
+	if got != want {
+		t.Errorf("Expected\n%s\nGot\n%s", want, got)
+	}
+}
+
+func TestYamlString(t *testing.T) {
+	tests := map[string]string{
+		"web":        "web",
+		"":           `""`,
+		"yes":        `"yes"`,
+		"1.5":        `"1.5"`,
+		"a: b":       `"a: b"`,
+		`say "hi"`:   `"say \"hi\""`,
+		"/srv/html":  "/srv/html",
+		"nginx:1.15": `"nginx:1.15"`,
+	}
+	for in, want := range tests {
+		if got := yamlString(in); got != want {
+			t.Errorf("Expected %s. Got %s.", want, got)
+		}
+	}
+}
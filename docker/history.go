@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+var (
+	// buildArgsPrefix build args added to RUN, e.g. "|2 A=1 B=2 /bin/sh -c ..."
+	buildArgsPrefix = regexp.MustCompile(`^\|\d+ (\S+=\S* )*`)
+	// nopInstruction instruction which doesn't run command, e.g. "/bin/sh -c #(nop)  CMD [...]"
+	nopInstruction = regexp.MustCompile(`^/bin/sh -c #\(nop\)\s*`)
+	shellCommand   = regexp.MustCompile(`^(RUN )?/bin/sh -c `)
+	// copyInContext source of ADD and COPY, e.g. "ADD file:abc in / "
+	copyInContext = regexp.MustCompile(`^(ADD|COPY) (.*) in (\S+)\s*$`)
+)
+
+// HistoryImage get the history of the image, the newest layer is first
+func (d *Docker) HistoryImage(ctx context.Context, name string) ([]image.HistoryResponseItem, error) {
+	return d.ImageHistory(ctx, name)
+}
+
+// Instruction convert created-by of the history to Dockerfile instruction.
+// It returns empty string when the layer doesn't have created-by.
+func Instruction(createdBy string) string {
+	s := strings.TrimSpace(createdBy)
+	s = strings.TrimSpace(strings.TrimSuffix(s, "# buildkit"))
+	if s == "" {
+		return ""
+	}
+
+	s = buildArgsPrefix.ReplaceAllString(s, "")
+
+	if nopInstruction.MatchString(s) {
+		s = nopInstruction.ReplaceAllString(s, "")
+	} else if shellCommand.MatchString(s) {
+		s = "RUN " + shellCommand.ReplaceAllString(s, "")
+	}
+
+	if m := copyInContext.FindStringSubmatch(s); m != nil {
+		s = m[1] + " " + m[2] + " " + m[3]
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// Dockerfile reconstruct approximate Dockerfile from the history.
+// The base image and the build context are not recoverable.
+func Dockerfile(history []image.HistoryResponseItem) string {
+	lines := []string{
+		"# reconstructed from image history, base image and files are unknown",
+		"FROM scratch",
+	}
+
+	for i := len(history) - 1; i >= 0; i-- {
+		instruction := Instruction(history[i].CreatedBy)
+		if instruction == "" {
+			continue
+		}
+
+		if strings.HasPrefix(instruction, "RUN ") {
+			instruction = strings.Replace(instruction, " && ", " \\\n    && ", -1)
+		}
+		lines = append(lines, instruction)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
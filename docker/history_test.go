@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+func TestInstruction(t *testing.T) {
+	tests := []struct {
+		createdBy string
+		want      string
+	}{
+		{`/bin/sh -c #(nop)  CMD ["nginx" "-g" "daemon off;"]`, `CMD ["nginx" "-g" "daemon off;"]`},
+		{`/bin/sh -c #(nop) ADD file:5d673d25da3a14ce1f6cf66e4c7fd4f4b85a3759a9d93efb3fd9ff852b5b56e4 in / `, `ADD file:5d673d25da3a14ce1f6cf66e4c7fd4f4b85a3759a9d93efb3fd9ff852b5b56e4 /`},
+		{`/bin/sh -c #(nop) COPY dir:abc in /app `, `COPY dir:abc /app`},
+		{`/bin/sh -c apt-get update`, `RUN apt-get update`},
+		{`|2 VERSION=1.0 USER=app /bin/sh -c make install`, `RUN make install`},
+		{`RUN /bin/sh -c go build ./... # buildkit`, `RUN go build ./...`},
+		{`WORKDIR /src`, `WORKDIR /src`},
+		{``, ``},
+	}
+
+	for _, tt := range tests {
+		if got := Instruction(tt.createdBy); got != tt.want {
+			t.Errorf("Expected %q. Got %q.", tt.want, got)
+		}
+	}
+}
+
+func TestDockerfile(t *testing.T) {
+	history := []image.HistoryResponseItem{
+		{CreatedBy: `/bin/sh -c #(nop)  CMD ["sh"]`},
+		{CreatedBy: `/bin/sh -c apk update && apk add curl`},
+		{CreatedBy: ``},
+		{CreatedBy: `/bin/sh -c #(nop) ADD file:abc in / `},
+	}
+
+	want := `# reconstructed from image history, base image and files are unknown
+FROM scratch
+ADD file:abc /
+RUN apk update \
+    && apk add curl
+CMD ["sh"]
+`
+	if got := Dockerfile(history); got != want {
+		t.Errorf("Expected\n%s\nGot\n%s", want, got)
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -23,20 +23,17 @@ func (d *Docker) InspectImage(name string) (types.ImageInspect, error) {
 	return img, err
 }
 
-// PullImage pull image
-func (d *Docker) PullImage(name string) error {
-	resp, err := d.ImagePull(context.TODO(), name, types.ImagePullOptions{})
+// PullImage pull image.
+// progress is called on the pulling goroutine whenever the progress is updated.
+func (d *Docker) PullImage(ctx context.Context, name string, progress func(PullProgress)) error {
+	resp, err := d.ImagePull(ctx, name, types.ImagePullOptions{})
 
 	if err != nil {
 		return err
 	}
+	defer resp.Close()
 
-	_, err = io.Copy(ioutil.Discard, resp)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return decodePullStream(resp, progress, time.Now)
 }
 
 // RemoveImage remove image
@@ -1,26 +1,50 @@
 package docker
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
 )
 
+// LogOptions container log options
+type LogOptions struct {
+	Since  string
+	Until  string
+	Tail   string
+	Follow bool
+}
+
+// LogLine a line of container logs
+type LogLine struct {
+	Time   time.Time
+	Stderr bool
+	Text   string
+}
+
 // ContainerLogStream returns the logs generated by a container in an io.ReadCloser.
-func (d *Docker) ContainerLogStream(name string) (io.ReadCloser, error) {
+// Each line of the logs has a timestamp.
+func (d *Docker) ContainerLogStream(ctx context.Context, name string, opt LogOptions) (io.ReadCloser, error) {
+	tail := opt.Tail
+	if tail == "" {
+		tail = "all"
+	}
+
 	options := types.ContainerLogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
-		Since:      "",
-		Until:      "",
-		Timestamps: false,
-		Follow:     true,
-		Tail:       "all",
+		Since:      opt.Since,
+		Until:      opt.Until,
+		Timestamps: true,
+		Follow:     opt.Follow,
+		Tail:       tail,
 		Details:    false,
 	}
 
-	ctx := context.Background()
 	reader, err := d.ContainerLogs(ctx, name, options)
 	if err != nil {
 		return nil, err
@@ -28,3 +52,96 @@ func (d *Docker) ContainerLogStream(name string) (io.ReadCloser, error) {
 
 	return reader, nil
 }
+
+// StreamLogs read container logs and call handler for each line
+// until the logs end or ctx is done.
+func (d *Docker) StreamLogs(ctx context.Context, name string, opt LogOptions, handler func(LogLine)) error {
+	container, err := d.InspectContainer(name)
+	if err != nil {
+		return err
+	}
+
+	reader, err := d.ContainerLogStream(ctx, name, opt)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// the logs of tty container are not multiplexed
+	err = ReadLogLines(reader, container.Config != nil && container.Config.Tty, handler)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// ReadLogLines demultiplex the log stream and call handler for each line
+func ReadLogLines(r io.Reader, tty bool, handler func(LogLine)) error {
+	stdout := &logLineWriter{handler: handler}
+	stderr := &logLineWriter{handler: handler, stderr: true}
+
+	var err error
+	if tty {
+		_, err = io.Copy(stdout, r)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, r)
+	}
+
+	stdout.flush()
+	stderr.flush()
+
+	return err
+}
+
+// logLineWriter split written data into lines
+type logLineWriter struct {
+	buf     bytes.Buffer
+	stderr  bool
+	handler func(LogLine)
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf.Next(i + 1))
+		w.emit(line[:i])
+	}
+
+	return len(p), nil
+}
+
+func (w *logLineWriter) flush() {
+	if w.buf.Len() > 0 {
+		w.emit(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *logLineWriter) emit(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	w.handler(parseLogLine(line, w.stderr))
+}
+
+// parseLogLine split timestamp added by docker from the line
+func parseLogLine(line string, stderr bool) LogLine {
+	l := LogLine{
+		Stderr: stderr,
+		Text:   line,
+	}
+
+	kv := strings.SplitN(line, " ", 2)
+	if t, err := time.Parse(time.RFC3339Nano, kv[0]); err == nil {
+		l.Time = t
+		l.Text = ""
+		if len(kv) > 1 {
+			l.Text = kv[1]
+		}
+	}
+
+	return l
+}
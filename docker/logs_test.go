@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+func TestReadLogLines(t *testing.T) {
+	buf := new(bytes.Buffer)
+	stdout := stdcopy.NewStdWriter(buf, stdcopy.Stdout)
+	stderr := stdcopy.NewStdWriter(buf, stdcopy.Stderr)
+
+	stdout.Write([]byte("2019-01-11T15:38:27.123456789Z hello\n2019-01-11T15:38:28Z wor"))
+	stderr.Write([]byte("2019-01-11T15:38:29Z oops\n"))
+	stdout.Write([]byte("ld\n"))
+
+	var lines []LogLine
+	if err := ReadLogLines(buf, false, func(l LogLine) {
+		lines = append(lines, l)
+	}); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines. Got %+v.", lines)
+	}
+
+	if lines[0].Text != "hello" || lines[0].Time.Nanosecond() != 123456789 {
+		t.Errorf("Expected hello with timestamp. Got %+v.", lines[0])
+	}
+
+	if !lines[1].Stderr || lines[1].Text != "oops" {
+		t.Errorf("Expected stderr line. Got %+v.", lines[1])
+	}
+
+	want := time.Date(2019, 1, 11, 15, 38, 28, 0, time.UTC)
+	if lines[2].Text != "world" || !lines[2].Time.Equal(want) {
+		t.Errorf("Expected joined line. Got %+v.", lines[2])
+	}
+}
+
+func TestReadLogLinesTty(t *testing.T) {
+	buf := bytes.NewBufferString("2019-01-11T15:38:27Z $ ls\r\nno timestamp")
+
+	var lines []LogLine
+	if err := ReadLogLines(buf, true, func(l LogLine) {
+		lines = append(lines, l)
+	}); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if len(lines) != 2 || lines[0].Text != "$ ls" {
+		t.Fatalf("Expected tty lines. Got %+v.", lines)
+	}
+
+	if !lines[1].Time.IsZero() || lines[1].Text != "no timestamp" {
+		t.Errorf("Expected line without timestamp. Got %+v.", lines[1])
+	}
+}
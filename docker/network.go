@@ -2,10 +2,124 @@ package docker
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strings"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
 )
 
+// NetworkSpec values of the network to create.
+// Labels and Options are "key=value".
+type NetworkSpec struct {
+	Name       string
+	Driver     string
+	Subnet     string
+	Gateway    string
+	IPRange    string
+	Internal   bool
+	Attachable bool
+	IPv6       bool
+	Labels     []string
+	Options    []string
+}
+
+// NewNetworkOptions validate the spec and make options to create network
+func NewNetworkOptions(spec NetworkSpec) (types.NetworkCreate, error) {
+	opt := types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         spec.Driver,
+		EnableIPv6:     spec.IPv6,
+		Internal:       spec.Internal,
+		Attachable:     spec.Attachable,
+		Labels:         make(map[string]string),
+		Options:        make(map[string]string),
+	}
+
+	if spec.Name == "" {
+		return opt, fmt.Errorf("name is required")
+	}
+
+	for _, label := range spec.Labels {
+		key, value, err := ParseLabel(label)
+		if err != nil {
+			return opt, err
+		}
+		opt.Labels[key] = value
+	}
+
+	for _, o := range spec.Options {
+		kv := strings.SplitN(o, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return opt, fmt.Errorf("invalid driver option %s", o)
+		}
+		opt.Options[kv[0]] = kv[1]
+	}
+
+	if spec.Subnet == "" {
+		if spec.Gateway != "" || spec.IPRange != "" {
+			return opt, fmt.Errorf("gateway and ip range require subnet")
+		}
+		return opt, nil
+	}
+
+	_, subnet, err := net.ParseCIDR(spec.Subnet)
+	if err != nil {
+		return opt, fmt.Errorf("invalid subnet %s", spec.Subnet)
+	}
+
+	if spec.Gateway != "" {
+		gateway := net.ParseIP(spec.Gateway)
+		if gateway == nil {
+			return opt, fmt.Errorf("invalid gateway %s", spec.Gateway)
+		}
+		if !subnet.Contains(gateway) {
+			return opt, fmt.Errorf("gateway %s is not in subnet %s", spec.Gateway, spec.Subnet)
+		}
+	}
+
+	if spec.IPRange != "" {
+		ip, ipRange, err := net.ParseCIDR(spec.IPRange)
+		if err != nil {
+			return opt, fmt.Errorf("invalid ip range %s", spec.IPRange)
+		}
+		subnetOnes, _ := subnet.Mask.Size()
+		rangeOnes, _ := ipRange.Mask.Size()
+		if !subnet.Contains(ip) || rangeOnes < subnetOnes {
+			return opt, fmt.Errorf("ip range %s is not in subnet %s", spec.IPRange, spec.Subnet)
+		}
+	}
+
+	opt.IPAM = &network.IPAM{
+		Driver: "default",
+		Config: []network.IPAMConfig{
+			{Subnet: spec.Subnet, Gateway: spec.Gateway, IPRange: spec.IPRange},
+		},
+	}
+	return opt, nil
+}
+
+// NewEndpointSettings make settings to connect container to network.
+// ip is the optional static IPv4 or IPv6 address.
+func NewEndpointSettings(aliases []string, ip string) (*network.EndpointSettings, error) {
+	settings := &network.EndpointSettings{Aliases: aliases}
+	if ip == "" {
+		return settings, nil
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, fmt.Errorf("invalid ip address %s", ip)
+	}
+	if addr.To4() != nil {
+		settings.IPAMConfig = &network.EndpointIPAMConfig{IPv4Address: ip}
+	} else {
+		settings.IPAMConfig = &network.EndpointIPAMConfig{IPv6Address: ip}
+	}
+	return settings, nil
+}
+
 // Networks get networks
 func (d *Docker) Networks(opt types.NetworkListOptions) ([]types.NetworkResource, error) {
 	return d.NetworkList(context.TODO(), opt)
@@ -20,3 +134,22 @@ func (d *Docker) InspectNetwork(name string) (types.NetworkResource, error) {
 func (d *Docker) RemoveNetwork(name string) error {
 	return d.NetworkRemove(context.TODO(), name)
 }
+
+// CreateNetwork create network and return its id
+func (d *Docker) CreateNetwork(name string, opt types.NetworkCreate) (string, error) {
+	resp, err := d.NetworkCreate(context.TODO(), name, opt)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// ConnectNetwork connect container to network
+func (d *Docker) ConnectNetwork(name, container string, settings *network.EndpointSettings) error {
+	return d.NetworkConnect(context.TODO(), name, container, settings)
+}
+
+// DisconnectNetwork disconnect container from network
+func (d *Docker) DisconnectNetwork(name, container string, force bool) error {
+	return d.NetworkDisconnect(context.TODO(), name, container, force)
+}
@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"testing"
+)
+
+func TestNewNetworkOptions(t *testing.T) {
+	spec := NetworkSpec{
+		Name:       "backend",
+		Driver:     "bridge",
+		Subnet:     "172.30.0.0/16",
+		Gateway:    "172.30.0.1",
+		IPRange:    "172.30.5.0/24",
+		Attachable: true,
+		Labels:     []string{"env=dev"},
+		Options:    []string{"com.docker.network.bridge.name=br-backend"},
+	}
+
+	opt, err := NewNetworkOptions(spec)
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if !opt.CheckDuplicate || !opt.Attachable || opt.Driver != "bridge" {
+		t.Errorf("Unexpected options %+v.", opt)
+	}
+	if opt.Labels["env"] != "dev" || opt.Options["com.docker.network.bridge.name"] != "br-backend" {
+		t.Errorf("Unexpected labels or options %+v %+v.", opt.Labels, opt.Options)
+	}
+	if opt.IPAM == nil || len(opt.IPAM.Config) != 1 || opt.IPAM.Config[0].Gateway != "172.30.0.1" {
+		t.Errorf("Unexpected IPAM %+v.", opt.IPAM)
+	}
+}
+
+func TestNewNetworkOptionsInvalid(t *testing.T) {
+	tests := []NetworkSpec{
+		{},
+		{Name: "n", Gateway: "10.0.0.1"},
+		{Name: "n", Subnet: "10.0.0.0"},
+		{Name: "n", Subnet: "10.0.0.0/24", Gateway: "10.0.1.1"},
+		{Name: "n", Subnet: "10.0.0.0/24", IPRange: "10.0.0.0/16"},
+		{Name: "n", Subnet: "10.0.0.0/24", IPRange: "10.1.0.0/28"},
+		{Name: "n", Options: []string{"novalue"}},
+	}
+
+	for _, spec := range tests {
+		if _, err := NewNetworkOptions(spec); err == nil {
+			t.Errorf("Expected error for %+v.", spec)
+		}
+	}
+}
+
+func TestNewEndpointSettings(t *testing.T) {
+	settings, err := NewEndpointSettings([]string{"db"}, "172.30.5.10")
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if settings.Aliases[0] != "db" || settings.IPAMConfig.IPv4Address != "172.30.5.10" {
+		t.Errorf("Unexpected settings %+v.", settings)
+	}
+
+	settings, err = NewEndpointSettings(nil, "fd00::10")
+	if err != nil || settings.IPAMConfig.IPv6Address != "fd00::10" {
+		t.Errorf("Expected IPv6 address. Got %+v %v.", settings, err)
+	}
+
+	if _, err := NewEndpointSettings(nil, "300.1.1.1"); err == nil {
+		t.Error("Expected error for invalid address.")
+	}
+}
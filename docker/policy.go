@@ -0,0 +1,369 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/skanehira/docui/common"
+)
+
+// resources of cleanup rules
+const (
+	CleanupContainer = "container"
+	CleanupImage     = "image"
+	CleanupVolume    = "volume"
+)
+
+// CleanupPolicy rules to remove old resources, e.g.
+//
+//	{"rules": [
+//	  {"resource": "container", "status": ["exited"], "olderThan": "3d"},
+//	  {"resource": "image", "keepNewest": 5},
+//	  {"resource": "volume", "labels": ["!keep=true"]}
+//	]}
+type CleanupPolicy struct {
+	Rules []CleanupRule `json:"rules"`
+}
+
+// CleanupRule conditions of resources to remove.
+// running containers and images or volumes used by containers are never removed.
+type CleanupRule struct {
+	// Name name of the rule in the preview, the default is made from the conditions
+	Name string `json:"name"`
+	// Resource "container", "image" or "volume"
+	Resource string `json:"resource"`
+	// OlderThan created before the duration like "72h" or "3d"
+	OlderThan string `json:"olderThan"`
+	// Labels "key", "key=value", "!key" or "!key=value" like PruneFilter
+	Labels []string `json:"labels"`
+	// Status container states, e.g. "exited", "created" or "dead"
+	Status []string `json:"status"`
+	// KeepNewest keep the newest N tags per repository, only for images
+	KeepNewest int `json:"keepNewest"`
+	// All remove all unused resources, it is required for the rule without conditions
+	All bool `json:"all"`
+}
+
+// CleanupItem resource to be removed by the policy
+type CleanupItem struct {
+	Resource string
+	// ID id to remove, "repository:tag" for tagged images
+	ID      string
+	Name    string
+	Created time.Time
+	Rule    string
+}
+
+// CleanupResources resources to evaluate the policy
+type CleanupResources struct {
+	Containers []types.Container
+	Images     []types.ImageSummary
+	Volumes    []*types.Volume
+}
+
+// LoadCleanupPolicy read the policy from the json file
+func LoadCleanupPolicy(file string) (CleanupPolicy, error) {
+	var policy CleanupPolicy
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return policy, err
+	}
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return policy, fmt.Errorf("invalid policy %s: %s", file, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid policy %s: %s", file, err)
+	}
+	return policy, nil
+}
+
+// Validate check the rules
+func (p CleanupPolicy) Validate() error {
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("no rules")
+	}
+	for i, r := range p.Rules {
+		if _, err := r.condition(time.Now()); err != nil {
+			return fmt.Errorf("rule %d: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
+// parseAge parse the duration, "d" suffix is days
+func parseAge(age string) (time.Duration, error) {
+	if strings.HasSuffix(age, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(age, "d"))
+		if err == nil && days >= 0 {
+			return time.Duration(days) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid olderThan %q: use a duration like 72h or 3d", age)
+	}
+	return d, nil
+}
+
+// condition validate the rule and make the condition
+func (r CleanupRule) condition(now time.Time) (pruneCondition, error) {
+	var c pruneCondition
+
+	switch r.Resource {
+	case CleanupContainer, CleanupImage, CleanupVolume:
+	default:
+		return c, fmt.Errorf("unknown resource %q: use container, image or volume", r.Resource)
+	}
+	if len(r.Status) > 0 && r.Resource != CleanupContainer {
+		return c, fmt.Errorf("status is only for containers")
+	}
+	for _, s := range r.Status {
+		if s == "running" || s == "paused" || s == "restarting" {
+			return c, fmt.Errorf("%s containers are never removed", s)
+		}
+	}
+	if r.KeepNewest < 0 || (r.KeepNewest > 0 && r.Resource != CleanupImage) {
+		return c, fmt.Errorf("keepNewest is only for images")
+	}
+
+	// the rule without conditions removes every unused resource, so it must be explicit
+	hasCondition := r.OlderThan != "" || len(r.Labels) > 0 || len(r.Status) > 0 || r.KeepNewest > 0
+	if !hasCondition && !r.All {
+		return c, fmt.Errorf("no conditions: set olderThan, labels, status or keepNewest, or \"all\": true to remove all unused %ss", r.Resource)
+	}
+	if hasCondition && r.All {
+		return c, fmt.Errorf("all cannot be used with conditions")
+	}
+
+	if r.OlderThan != "" {
+		age, err := parseAge(r.OlderThan)
+		if err != nil {
+			return c, err
+		}
+		c.until = now.Add(-age)
+	}
+
+	labels, err := parseLabelFilters(r.Labels)
+	if err != nil {
+		return c, err
+	}
+	c.labels = labels
+	return c, nil
+}
+
+// String make the name of the rule from the conditions, e.g. "exited containers older than 3d"
+func (r CleanupRule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	words := []string{}
+	if r.All {
+		words = append(words, "all unused")
+	}
+	if len(r.Status) > 0 {
+		words = append(words, strings.Join(r.Status, "/"))
+	}
+	words = append(words, r.Resource+"s")
+	if r.KeepNewest > 0 {
+		words = append(words, fmt.Sprintf("except newest %d per repository", r.KeepNewest))
+	}
+	if r.OlderThan != "" {
+		words = append(words, "older than "+r.OlderThan)
+	}
+	if len(r.Labels) > 0 {
+		words = append(words, "with "+strings.Join(r.Labels, " "))
+	}
+	return strings.Join(words, " ")
+}
+
+// Evaluate list resources which match any rule, a resource is listed once with the first rule
+func (p CleanupPolicy) Evaluate(r CleanupResources, now time.Time) ([]CleanupItem, error) {
+	var items []CleanupItem
+	seen := make(map[string]bool)
+
+	for _, rule := range p.Rules {
+		c, err := rule.condition(now)
+		if err != nil {
+			return nil, err
+		}
+
+		var matched []CleanupItem
+		switch rule.Resource {
+		case CleanupContainer:
+			matched = cleanupContainers(r.Containers, rule, c)
+		case CleanupImage:
+			matched = cleanupImages(r.Images, r.Containers, rule, c)
+		case CleanupVolume:
+			matched = cleanupVolumes(r.Volumes, r.Containers, c)
+		}
+
+		for _, item := range matched {
+			key := item.Resource + " " + item.ID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			item.Rule = rule.String()
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func cleanupContainers(containers []types.Container, rule CleanupRule, c pruneCondition) []CleanupItem {
+	var items []CleanupItem
+	for i := range containers {
+		ct := &containers[i]
+		if isActiveContainer(ct) {
+			continue
+		}
+		if len(rule.Status) > 0 && !containsString(rule.Status, ct.State) {
+			continue
+		}
+		created := time.Unix(ct.Created, 0)
+		if !c.match(created, ct.Labels) {
+			continue
+		}
+		name := ""
+		if len(ct.Names) > 0 {
+			name = strings.TrimPrefix(ct.Names[0], "/")
+		}
+		items = append(items, CleanupItem{Resource: CleanupContainer, ID: ct.ID, Name: name, Created: created})
+	}
+	return items
+}
+
+func cleanupImages(images []types.ImageSummary, containers []types.Container, rule CleanupRule, c pruneCondition) []CleanupItem {
+	// containers refer to the image by id, and by the name when it is created
+	used := make(map[string]bool)
+	for _, ct := range containers {
+		used[ct.ImageID] = true
+		used[ct.Image] = true
+	}
+
+	// tags per repository, the newest image first
+	type tag struct {
+		repoTag string
+		image   *types.ImageSummary
+	}
+	repos := make(map[string][]tag)
+	var items []CleanupItem
+
+	for i := range images {
+		img := &images[i]
+		if isDanglingImage(img) {
+			if rule.KeepNewest == 0 && !used[img.ID] && c.match(time.Unix(img.Created, 0), img.Labels) {
+				items = append(items, CleanupItem{Resource: CleanupImage, ID: img.ID, Name: "<none>", Created: time.Unix(img.Created, 0)})
+			}
+			continue
+		}
+		for _, repoTag := range img.RepoTags {
+			repo, _ := common.ParseRepoTag(repoTag)
+			repos[repo] = append(repos[repo], tag{repoTag: repoTag, image: img})
+		}
+	}
+
+	names := make([]string, 0, len(repos))
+	for repo := range repos {
+		names = append(names, repo)
+	}
+	sort.Strings(names)
+
+	for _, repo := range names {
+		tags := repos[repo]
+		sort.SliceStable(tags, func(i, j int) bool {
+			if tags[i].image.Created != tags[j].image.Created {
+				return tags[i].image.Created > tags[j].image.Created
+			}
+			return tags[i].repoTag < tags[j].repoTag
+		})
+
+		for i, t := range tags {
+			// the newest KeepNewest tags are kept, and older tags are kept while they are in use
+			if i < rule.KeepNewest || used[t.image.ID] || used[t.repoTag] {
+				continue
+			}
+			created := time.Unix(t.image.Created, 0)
+			if !c.match(created, t.image.Labels) {
+				continue
+			}
+			items = append(items, CleanupItem{Resource: CleanupImage, ID: t.repoTag, Name: t.repoTag, Created: created})
+		}
+	}
+	return items
+}
+
+func cleanupVolumes(volumes []*types.Volume, containers []types.Container, c pruneCondition) []CleanupItem {
+	used := make(map[string]bool)
+	for _, ct := range containers {
+		for _, m := range ct.Mounts {
+			if m.Name != "" {
+				used[m.Name] = true
+			}
+		}
+	}
+
+	var items []CleanupItem
+	for _, v := range volumes {
+		if used[v.Name] {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, v.CreatedAt)
+		if err != nil && !c.until.IsZero() {
+			// the age is unknown
+			continue
+		}
+		if !c.match(created, v.Labels) {
+			continue
+		}
+		items = append(items, CleanupItem{Resource: CleanupVolume, ID: v.Name, Name: v.Name, Created: created})
+	}
+	return items
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ListCleanupResources get all containers, images and volumes to evaluate the policy
+func ListCleanupResources(e Engine) (CleanupResources, error) {
+	var r CleanupResources
+	var err error
+
+	if r.Containers, err = e.Containers(types.ContainerListOptions{All: true}); err != nil {
+		return r, err
+	}
+	if r.Images, err = e.Images(types.ImageListOptions{}); err != nil {
+		return r, err
+	}
+	if r.Volumes, err = e.Volumes(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// RemoveCleanupItem remove the resource, images are untagged and removed when the last tag is removed
+func RemoveCleanupItem(e Engine, item CleanupItem) error {
+	switch item.Resource {
+	case CleanupContainer:
+		return e.RemoveContainer(item.ID)
+	case CleanupImage:
+		return e.RemoveImage(item.ID)
+	case CleanupVolume:
+		return e.RemoveVolume(item.ID)
+	}
+	return fmt.Errorf("unknown resource %s", item.Resource)
+}
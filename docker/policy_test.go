@@ -0,0 +1,207 @@
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+func testCleanupResources(now time.Time) CleanupResources {
+	daysAgo := func(days int) int64 {
+		return now.Add(-time.Duration(days) * 24 * time.Hour).Unix()
+	}
+	return CleanupResources{
+		Containers: []types.Container{
+			{ID: "web", Names: []string{"/web"}, Image: "app:7", ImageID: "sha256:7", State: "running", Created: daysAgo(10)},
+			{ID: "old-job", Names: []string{"/old-job"}, ImageID: "sha256:1", State: "exited", Created: daysAgo(5),
+				Mounts: []types.MountPoint{{Name: "cache"}}},
+			{ID: "new-job", Names: []string{"/new-job"}, State: "exited", Created: daysAgo(1)},
+			{ID: "draft", Names: []string{"/draft"}, State: "created", Created: daysAgo(5), Labels: map[string]string{"keep": "true"}},
+		},
+		Images: []types.ImageSummary{
+			{ID: "sha256:1", RepoTags: []string{"app:1"}, Created: daysAgo(7)},
+			{ID: "sha256:2", RepoTags: []string{"app:2"}, Created: daysAgo(6)},
+			{ID: "sha256:3", RepoTags: []string{"app:3", "app:stable"}, Created: daysAgo(5)},
+			{ID: "sha256:7", RepoTags: []string{"app:7"}, Created: daysAgo(1)},
+			{ID: "sha256:redis", RepoTags: []string{"redis:5"}, Created: daysAgo(30)},
+			{ID: "sha256:none", RepoTags: []string{"<none>:<none>"}, Created: daysAgo(2)},
+		},
+		Volumes: []*types.Volume{
+			{Name: "cache", CreatedAt: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+			{Name: "pgdata", Labels: map[string]string{"keep": "true"}},
+			{Name: "tmp"},
+		},
+	}
+}
+
+func cleanupIDs(items []CleanupItem) []string {
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.Resource+" "+item.ID)
+	}
+	return ids
+}
+
+func TestEvaluateCleanupPolicy(t *testing.T) {
+	now := time.Now()
+	r := testCleanupResources(now)
+
+	tests := []struct {
+		rule CleanupRule
+		want []string
+	}{
+		{
+			CleanupRule{Resource: CleanupContainer, Status: []string{"exited"}, OlderThan: "3d"},
+			[]string{"container old-job"},
+		},
+		{
+			CleanupRule{Resource: CleanupContainer, Labels: []string{"!keep"}},
+			[]string{"container old-job", "container new-job"},
+		},
+		{
+			// app:7 and app:3 are the newest tags, and app:1 is used by old-job
+			CleanupRule{Resource: CleanupImage, KeepNewest: 2},
+			[]string{"image app:stable", "image app:2"},
+		},
+		{
+			CleanupRule{Resource: CleanupImage, OlderThan: "20d"},
+			[]string{"image redis:5"},
+		},
+		{
+			CleanupRule{Resource: CleanupImage, OlderThan: "36h"},
+			[]string{"image sha256:none", "image app:3", "image app:stable", "image app:2", "image redis:5"},
+		},
+		{
+			CleanupRule{Resource: CleanupVolume, Labels: []string{"!keep=true"}},
+			[]string{"volume tmp"},
+		},
+		{
+			CleanupRule{Resource: CleanupVolume, All: true},
+			[]string{"volume pgdata", "volume tmp"},
+		},
+	}
+
+	for _, tt := range tests {
+		items, err := CleanupPolicy{Rules: []CleanupRule{tt.rule}}.Evaluate(r, now)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.rule, err)
+			continue
+		}
+		if got := cleanupIDs(items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v. Got %v.", tt.rule, tt.want, got)
+		}
+	}
+}
+
+func TestEvaluateCleanupPolicyOnce(t *testing.T) {
+	now := time.Now()
+	policy := CleanupPolicy{Rules: []CleanupRule{
+		{Name: "old jobs", Resource: CleanupContainer, OlderThan: "3d", Labels: []string{"!keep"}},
+		{Resource: CleanupContainer, Status: []string{"exited"}},
+	}}
+
+	items, err := policy.Evaluate(testCleanupResources(now), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CleanupItem{
+		{Resource: CleanupContainer, ID: "old-job", Name: "old-job", Rule: "old jobs"},
+		{Resource: CleanupContainer, ID: "new-job", Name: "new-job", Rule: "exited containers"},
+	}
+	for i := range items {
+		items[i].Created = time.Time{}
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, items)
+	}
+}
+
+func TestCleanupRuleErrors(t *testing.T) {
+	rules := []CleanupRule{
+		{Resource: "network"},
+		{Resource: CleanupImage, Status: []string{"exited"}},
+		{Resource: CleanupContainer, Status: []string{"running"}},
+		{Resource: CleanupVolume, KeepNewest: 3},
+		{Resource: CleanupContainer, OlderThan: "3 days"},
+		{Resource: CleanupContainer, Labels: []string{"=true"}},
+		{Resource: CleanupImage},
+		{Resource: CleanupVolume},
+		{Resource: CleanupVolume, All: true, Labels: []string{"tmp"}},
+	}
+	for _, rule := range rules {
+		if err := (CleanupPolicy{Rules: []CleanupRule{rule}}).Validate(); err == nil {
+			t.Errorf("Expected error for %+v.", rule)
+		}
+	}
+	if err := (CleanupPolicy{}).Validate(); err == nil {
+		t.Error("Expected error without rules.")
+	}
+}
+
+func TestLoadCleanupPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "cleanup.json")
+	data := `{"rules": [{"resource": "image", "keepNewest": 5}, {"resource": "container", "status": ["exited"], "olderThan": "3d"}]}`
+	if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadCleanupPolicy(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.Rules[1].String(); got != "exited containers older than 3d" {
+		t.Errorf("Expected the rule name. Got %s.", got)
+	}
+
+	if err := ioutil.WriteFile(file, []byte(`{"rules": [{"resource": "images"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCleanupPolicy(file); err == nil {
+		t.Error("Expected error with the unknown resource.")
+	}
+}
+
+func TestRemoveCleanupItems(t *testing.T) {
+	f := NewFake()
+	now := time.Now()
+	r := testCleanupResources(now)
+	f.ContainerItems = r.Containers
+	f.ImageItems = r.Images
+	f.VolumeItems = r.Volumes
+
+	resources, err := ListCleanupResources(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := CleanupPolicy{Rules: []CleanupRule{{Resource: CleanupVolume, All: true}, {Resource: CleanupImage, OlderThan: "20d"}}}.Evaluate(resources, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if err := RemoveCleanupItem(f, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"RemoveVolume pgdata", "RemoveVolume tmp", "RemoveImage redis:5"}
+	var got []string
+	for _, call := range f.Calls {
+		if call != "Containers" && call != "Images" && call != "Volumes" {
+			got = append(got, call)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v. Got %v.", want, got)
+	}
+}
@@ -0,0 +1,322 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// UsageCategory kind of resources using the disk
+type UsageCategory string
+
+const (
+	// ImageUsage images
+	ImageUsage UsageCategory = "Images"
+	// ContainerUsage writable layers of containers
+	ContainerUsage UsageCategory = "Containers"
+	// VolumeUsage local volumes
+	VolumeUsage UsageCategory = "Local Volumes"
+	// BuildCacheUsage build cache
+	BuildCacheUsage UsageCategory = "Build Cache"
+)
+
+// UsageCategories categories in the order of "docker system df"
+var UsageCategories = []UsageCategory{ImageUsage, ContainerUsage, VolumeUsage, BuildCacheUsage}
+
+// Usage disk usage of the category
+type Usage struct {
+	Category    UsageCategory
+	Total       int
+	Active      int
+	Size        int64
+	Reclaimable int64
+}
+
+// PruneFilter conditions of the resources to prune
+type PruneFilter struct {
+	// Until prune resources created before it, timestamp or duration like "24h"
+	Until string
+	// Labels prune resources which have all labels, "key" or "key=value".
+	// "!key" or "!key=value" excludes resources which have the label.
+	Labels []string
+	// AllImages prune all unused images, not only dangling images
+	AllImages bool
+}
+
+// PruneItem resource to be removed by prune
+type PruneItem struct {
+	ID   string
+	Name string
+	// Tags tags of the image, an image which has tags is removed by untagging all of them
+	Tags    []string
+	Size    int64
+	Created time.Time
+}
+
+// SummarizeDiskUsage calculate the usage of each category like "docker system df"
+func SummarizeDiskUsage(du types.DiskUsage) []Usage {
+	images := Usage{Category: ImageUsage, Total: len(du.Images), Size: du.LayersSize}
+	var used int64
+	for _, i := range du.Images {
+		if i.Containers > 0 {
+			images.Active++
+			if i.SharedSize >= 0 {
+				used += i.Size - i.SharedSize
+			}
+		}
+	}
+	images.Reclaimable = du.LayersSize - used
+	if images.Reclaimable < 0 {
+		images.Reclaimable = 0
+	}
+
+	containers := Usage{Category: ContainerUsage, Total: len(du.Containers)}
+	for _, c := range du.Containers {
+		containers.Size += c.SizeRw
+		if isActiveContainer(c) {
+			containers.Active++
+		} else {
+			containers.Reclaimable += c.SizeRw
+		}
+	}
+
+	volumes := Usage{Category: VolumeUsage, Total: len(du.Volumes)}
+	for _, v := range du.Volumes {
+		if v.UsageData == nil {
+			continue
+		}
+		size := v.UsageData.Size
+		if size < 0 {
+			size = 0
+		}
+		volumes.Size += size
+		if v.UsageData.RefCount > 0 {
+			volumes.Active++
+		} else {
+			volumes.Reclaimable += size
+		}
+	}
+
+	cache := Usage{Category: BuildCacheUsage, Total: len(du.BuildCache)}
+	for _, b := range du.BuildCache {
+		if b.InUse {
+			cache.Active++
+		}
+		if b.Shared {
+			continue
+		}
+		cache.Size += b.Size
+		if !b.InUse {
+			cache.Reclaimable += b.Size
+		}
+	}
+
+	return []Usage{images, containers, volumes, cache}
+}
+
+// isActiveContainer running or paused containers are not pruned
+func isActiveContainer(c *types.Container) bool {
+	return c.State == "running" || c.State == "paused" || c.State == "restarting"
+}
+
+// isDanglingImage image without tags
+func isDanglingImage(i *types.ImageSummary) bool {
+	return len(i.RepoTags) == 0 || (len(i.RepoTags) == 1 && i.RepoTags[0] == "<none>:<none>")
+}
+
+// parseUntil parse the timestamp, date or duration before now
+func parseUntil(until string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(until); err == nil {
+		return now.Add(-d), nil
+	}
+	if sec, err := strconv.ParseInt(until, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, until, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until %q: use a duration like 24h or a date like 2006-01-02", until)
+}
+
+// labelFilter label condition of PruneFilter
+type labelFilter struct {
+	key, value string
+	hasValue   bool
+	exclude    bool
+}
+
+func parseLabelFilters(labels []string) ([]labelFilter, error) {
+	var result []labelFilter
+	for _, l := range labels {
+		f := labelFilter{exclude: strings.HasPrefix(l, "!")}
+		kv := strings.SplitN(strings.TrimPrefix(l, "!"), "=", 2)
+		f.key = kv[0]
+		if f.key == "" {
+			return nil, fmt.Errorf("invalid label %q", l)
+		}
+		if len(kv) == 2 {
+			f.value, f.hasValue = kv[1], true
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+func matchLabels(labels map[string]string, filters []labelFilter) bool {
+	for _, f := range filters {
+		value, ok := labels[f.key]
+		has := ok && (!f.hasValue || value == f.value)
+		if has == f.exclude {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneCondition parsed PruneFilter
+type pruneCondition struct {
+	until  time.Time
+	labels []labelFilter
+}
+
+func (c pruneCondition) match(created time.Time, labels map[string]string) bool {
+	if !c.until.IsZero() && !created.Before(c.until) {
+		return false
+	}
+	return matchLabels(labels, c.labels)
+}
+
+// parsePruneFilter validate the filter for the category
+func parsePruneFilter(category UsageCategory, f PruneFilter, now time.Time) (pruneCondition, error) {
+	var c pruneCondition
+	var err error
+
+	if f.Until != "" {
+		if category == VolumeUsage {
+			return c, fmt.Errorf("until is not supported for volumes")
+		}
+		if c.until, err = parseUntil(f.Until, now); err != nil {
+			return c, err
+		}
+	}
+
+	if len(f.Labels) > 0 && category == BuildCacheUsage {
+		return c, fmt.Errorf("labels are not supported for build cache")
+	}
+	if c.labels, err = parseLabelFilters(f.Labels); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// PruneCandidates list unused resources which match the filter, they are removed with RemovePruneItem
+func PruneCandidates(du types.DiskUsage, category UsageCategory, f PruneFilter, now time.Time) ([]PruneItem, error) {
+	c, err := parsePruneFilter(category, f, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []PruneItem
+	switch category {
+	case ImageUsage:
+		for _, i := range du.Images {
+			if i.Containers > 0 || (!f.AllImages && !isDanglingImage(i)) {
+				continue
+			}
+			created := time.Unix(i.Created, 0)
+			if !c.match(created, i.Labels) {
+				continue
+			}
+			item := PruneItem{ID: i.ID, Name: "<none>", Size: i.Size, Created: created}
+			if !isDanglingImage(i) {
+				item.Name = strings.Join(i.RepoTags, ", ")
+				item.Tags = i.RepoTags
+			}
+			items = append(items, item)
+		}
+	case ContainerUsage:
+		for _, ct := range du.Containers {
+			created := time.Unix(ct.Created, 0)
+			if isActiveContainer(ct) || !c.match(created, ct.Labels) {
+				continue
+			}
+			name := ""
+			if len(ct.Names) > 0 {
+				name = strings.TrimPrefix(ct.Names[0], "/")
+			}
+			items = append(items, PruneItem{ID: ct.ID, Name: name, Size: ct.SizeRw, Created: created})
+		}
+	case VolumeUsage:
+		for _, v := range du.Volumes {
+			if v.UsageData == nil || v.UsageData.RefCount > 0 || !c.match(time.Time{}, v.Labels) {
+				continue
+			}
+			created, _ := time.Parse(time.RFC3339, v.CreatedAt)
+			size := v.UsageData.Size
+			if size < 0 {
+				size = 0
+			}
+			items = append(items, PruneItem{ID: v.Name, Name: v.Name, Size: size, Created: created})
+		}
+	case BuildCacheUsage:
+		for _, b := range du.BuildCache {
+			// build cache is pruned by the last used time
+			used := b.CreatedAt
+			if b.LastUsedAt != nil {
+				used = *b.LastUsedAt
+			}
+			if b.InUse || !c.match(used, nil) {
+				continue
+			}
+			items = append(items, PruneItem{ID: b.ID, Name: b.Description, Size: b.Size, Created: b.CreatedAt})
+		}
+	default:
+		return nil, fmt.Errorf("unknown category %s", category)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Size > items[j].Size
+	})
+	return items, nil
+}
+
+// RemoveBuildCache remove the build cache record
+func (d *Docker) RemoveBuildCache(ctx context.Context, id string) error {
+	_, err := d.BuildCachePrune(ctx, types.BuildCachePruneOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("id", id)),
+	})
+	return err
+}
+
+// RemovePruneItem remove the resource listed by PruneCandidates.
+// only listed resources are removed, and resources used since they are listed are refused by the daemon.
+func RemovePruneItem(ctx context.Context, e Engine, category UsageCategory, item PruneItem) error {
+	switch category {
+	case ImageUsage:
+		if len(item.Tags) == 0 {
+			return e.RemoveImage(item.ID)
+		}
+		for _, tag := range item.Tags {
+			if err := e.RemoveImage(tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ContainerUsage:
+		return e.RemoveContainer(item.ID)
+	case VolumeUsage:
+		return e.RemoveVolume(item.ID)
+	case BuildCacheUsage:
+		return e.RemoveBuildCache(ctx, item.ID)
+	}
+	return fmt.Errorf("unknown category %s", category)
+}
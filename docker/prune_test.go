@@ -0,0 +1,177 @@
+package docker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+func testDiskUsage(now time.Time) types.DiskUsage {
+	lastUsed := now.Add(-time.Hour)
+	return types.DiskUsage{
+		LayersSize: 1000,
+		Images: []*types.ImageSummary{
+			{ID: "sha256:nginx", RepoTags: []string{"nginx:latest"}, Size: 600, SharedSize: 100, Containers: 1, Created: now.Add(-48 * time.Hour).Unix()},
+			{ID: "sha256:old", RepoTags: []string{"app:1.0"}, Size: 300, SharedSize: 100, Created: now.Add(-72 * time.Hour).Unix(), Labels: map[string]string{"env": "dev"}},
+			{ID: "sha256:dangling", RepoTags: []string{"<none>:<none>"}, Size: 100, Created: now.Add(-time.Hour).Unix()},
+		},
+		Containers: []*types.Container{
+			{ID: "web", Names: []string{"/web"}, State: "running", SizeRw: 10, Created: now.Add(-48 * time.Hour).Unix()},
+			{ID: "job", Names: []string{"/job"}, State: "exited", SizeRw: 20, Created: now.Add(-48 * time.Hour).Unix(), Labels: map[string]string{"env": "dev"}},
+			{ID: "tmp", Names: []string{"/tmp"}, State: "created", SizeRw: 30, Created: now.Add(-time.Hour).Unix()},
+		},
+		Volumes: []*types.Volume{
+			{Name: "pgdata", UsageData: &types.VolumeUsageData{Size: 500, RefCount: 1}},
+			{Name: "cache", UsageData: &types.VolumeUsageData{Size: 200}, Labels: map[string]string{"keep": "true"}},
+			{Name: "remote", UsageData: &types.VolumeUsageData{Size: -1}},
+		},
+		BuildCache: []*types.BuildCache{
+			{ID: "a", Size: 40, InUse: true, CreatedAt: now.Add(-48 * time.Hour)},
+			{ID: "b", Size: 50, CreatedAt: now.Add(-48 * time.Hour)},
+			{ID: "c", Size: 60, CreatedAt: now.Add(-48 * time.Hour), LastUsedAt: &lastUsed},
+		},
+	}
+}
+
+func TestSummarizeDiskUsage(t *testing.T) {
+	got := SummarizeDiskUsage(testDiskUsage(time.Now()))
+	want := []Usage{
+		{Category: ImageUsage, Total: 3, Active: 1, Size: 1000, Reclaimable: 500},
+		{Category: ContainerUsage, Total: 3, Active: 1, Size: 60, Reclaimable: 50},
+		{Category: VolumeUsage, Total: 3, Active: 1, Size: 700, Reclaimable: 200},
+		{Category: BuildCacheUsage, Total: 3, Active: 1, Size: 150, Reclaimable: 110},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, got)
+	}
+}
+
+func TestPruneCandidates(t *testing.T) {
+	now := time.Now()
+	du := testDiskUsage(now)
+
+	ids := func(items []PruneItem) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		category UsageCategory
+		filter   PruneFilter
+		want     []string
+	}{
+		{ImageUsage, PruneFilter{}, []string{"sha256:dangling"}},
+		{ImageUsage, PruneFilter{AllImages: true}, []string{"sha256:old", "sha256:dangling"}},
+		{ImageUsage, PruneFilter{AllImages: true, Until: "24h"}, []string{"sha256:old"}},
+		{ImageUsage, PruneFilter{AllImages: true, Labels: []string{"!env=dev"}}, []string{"sha256:dangling"}},
+		{ContainerUsage, PruneFilter{}, []string{"tmp", "job"}},
+		{ContainerUsage, PruneFilter{Labels: []string{"env"}}, []string{"job"}},
+		{ContainerUsage, PruneFilter{Until: "24h"}, []string{"job"}},
+		{VolumeUsage, PruneFilter{}, []string{"cache", "remote"}},
+		{VolumeUsage, PruneFilter{Labels: []string{"!keep"}}, []string{"remote"}},
+		{BuildCacheUsage, PruneFilter{}, []string{"c", "b"}},
+		{BuildCacheUsage, PruneFilter{Until: "24h"}, []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		items, err := PruneCandidates(du, tt.category, tt.filter, now)
+		if err != nil {
+			t.Errorf("%s %+v: unexpected error %s", tt.category, tt.filter, err)
+			continue
+		}
+		if got := ids(items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %+v: expected %v. Got %v.", tt.category, tt.filter, tt.want, got)
+		}
+	}
+}
+
+func TestPruneFilterErrors(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		category UsageCategory
+		filter   PruneFilter
+	}{
+		{VolumeUsage, PruneFilter{Until: "24h"}},
+		{BuildCacheUsage, PruneFilter{Labels: []string{"env=dev"}}},
+		{ContainerUsage, PruneFilter{Until: "yesterday"}},
+		{ContainerUsage, PruneFilter{Labels: []string{"=dev"}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := PruneCandidates(types.DiskUsage{}, tt.category, tt.filter, now); err == nil {
+			t.Errorf("%s %+v: expected error.", tt.category, tt.filter)
+		}
+	}
+}
+
+func TestParsePruneFilter(t *testing.T) {
+	until := time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC)
+	f := PruneFilter{Until: "2019-01-02T00:00:00Z", Labels: []string{"env=dev", "!keep"}}
+	c, err := parsePruneFilter(ImageUsage, f, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.until.Equal(until) {
+		t.Errorf("Expected until %s. Got %s.", until, c.until)
+	}
+	want := []labelFilter{{key: "env", value: "dev", hasValue: true}, {key: "keep", exclude: true}}
+	if !reflect.DeepEqual(c.labels, want) {
+		t.Errorf("Expected labels %+v. Got %+v.", want, c.labels)
+	}
+}
+
+func TestRemovePruneItems(t *testing.T) {
+	f := NewFake()
+	f.ContainerItems = []types.Container{
+		{ID: "web", Names: []string{"/web"}, Image: "nginx:latest", State: "running"},
+		{ID: "job", Names: []string{"/job"}, Image: "nginx:latest", State: "exited", SizeRw: 20},
+	}
+	f.ImageItems = []types.ImageSummary{
+		{ID: "sha256:nginx", RepoTags: []string{"nginx:latest"}},
+		{ID: "sha256:app", RepoTags: []string{"app:1", "app:stable"}, Size: 200},
+		{ID: "sha256:dangling", RepoTags: []string{"<none>:<none>"}, Size: 100},
+	}
+
+	du, err := f.DiskUsage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := PruneCandidates(du, ContainerUsage, PruneFilter{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the container stopped after the preview is not removed
+	f.ContainerItems[0].State = "exited"
+	for _, item := range items {
+		if err := RemovePruneItem(context.Background(), f, ContainerUsage, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(f.ContainerItems) != 1 || f.ContainerItems[0].ID != "web" {
+		t.Errorf("Expected only job is removed. Got %+v.", f.ContainerItems)
+	}
+
+	items, err = PruneCandidates(du, ImageUsage, PruneFilter{AllImages: true}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if err := RemovePruneItem(context.Background(), f, ImageUsage, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(f.ImageItems) != 1 || f.ImageItems[0].ID != "sha256:nginx" {
+		t.Errorf("Expected the image used by web is kept. Got %+v.", f.ImageItems)
+	}
+	if got := f.CallsOf("RemoveImage"); !reflect.DeepEqual(got, []string{"RemoveImage app:1", "RemoveImage app:stable", "RemoveImage sha256:dangling"}) {
+		t.Errorf("Expected tags are removed one by one. Got %v.", got)
+	}
+}
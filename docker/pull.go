@@ -0,0 +1,199 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/skanehira/docui/common"
+)
+
+// progressInterval minimum interval to notify pull and copy progress
+var progressInterval = 250 * time.Millisecond
+
+// LayerProgress image layer pull progress
+type LayerProgress struct {
+	ID         string
+	Status     string
+	Size       int64
+	Downloaded int64
+	Extracted  int64
+	// Exists the layer already exists in local
+	Exists bool
+}
+
+// Done whether the layer has been pulled
+func (l *LayerProgress) Done() bool {
+	return l.Exists || l.Status == "Pull complete"
+}
+
+// PullProgress image pull progress
+type PullProgress struct {
+	Layers      []*LayerProgress
+	BytesPerSec float64
+}
+
+// Percent aggregate percentage of download and extract of layers.
+// each layer counts equally, because waiting layers have no size yet,
+// and adding their sizes to the total later would make the percentage go back.
+func (p PullProgress) Percent() float64 {
+	var layers int
+	var pulled float64
+	for _, l := range p.Layers {
+		if l.Exists {
+			continue
+		}
+		layers++
+
+		switch {
+		case l.Done():
+			pulled++
+		case l.Size > 0:
+			pulled += math.Min(1, float64(l.Downloaded+l.Extracted)/float64(l.Size*2))
+		}
+	}
+
+	if layers == 0 {
+		return 0
+	}
+
+	return pulled / float64(layers) * 100
+}
+
+// String progress summary
+func (p PullProgress) String() string {
+	var done int
+	for _, l := range p.Layers {
+		if l.Done() {
+			done++
+		}
+	}
+
+	return fmt.Sprintf("%.1f%% %d/%d layers %s/s", p.Percent(), done, len(p.Layers), common.ParseSizeToString(int64(p.BytesPerSec)))
+}
+
+type pullTracker struct {
+	progress  PullProgress
+	layers    map[string]*LayerProgress
+	now       func() time.Time
+	rateTime  time.Time
+	rateBytes int64
+	notified  time.Time
+}
+
+func newPullTracker(now func() time.Time) *pullTracker {
+	t := &pullTracker{
+		layers: make(map[string]*LayerProgress),
+		now:    now,
+	}
+	t.rateTime = now()
+	return t
+}
+
+func (t *pullTracker) layer(id string) *LayerProgress {
+	l, ok := t.layers[id]
+	if !ok {
+		l = &LayerProgress{ID: id}
+		t.layers[id] = l
+		t.progress.Layers = append(t.progress.Layers, l)
+	}
+	return l
+}
+
+func (t *pullTracker) downloaded() int64 {
+	var bytes int64
+	for _, l := range t.progress.Layers {
+		bytes += l.Downloaded
+	}
+	return bytes
+}
+
+// update apply message to progress, and return true when status of layer is changed
+func (t *pullTracker) update(msg jsonmessage.JSONMessage) bool {
+	// messages without id are about the whole image, e.g. "Digest: sha256:...",
+	// and "Pulling from" has the tag as id
+	if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from") {
+		return false
+	}
+
+	l := t.layer(msg.ID)
+	changed := l.Status != msg.Status
+	l.Status = msg.Status
+
+	switch msg.Status {
+	case "Already exists":
+		l.Exists = true
+	case "Downloading":
+		if msg.Progress != nil {
+			if msg.Progress.Total > 0 {
+				l.Size = msg.Progress.Total
+			}
+			l.Downloaded = msg.Progress.Current
+		}
+	case "Verifying Checksum", "Download complete":
+		l.Downloaded = l.Size
+	case "Extracting":
+		l.Downloaded = l.Size
+		if msg.Progress != nil {
+			if l.Size == 0 {
+				l.Size = msg.Progress.Total
+			}
+			l.Extracted = msg.Progress.Current
+		}
+	case "Pull complete":
+		l.Downloaded = l.Size
+		l.Extracted = l.Size
+	}
+
+	now := t.now()
+	if elapsed := now.Sub(t.rateTime); elapsed >= time.Second {
+		bytes := t.downloaded()
+		t.progress.BytesPerSec = float64(bytes-t.rateBytes) / elapsed.Seconds()
+		t.rateTime = now
+		t.rateBytes = bytes
+	}
+
+	return changed
+}
+
+// decodePullStream decode image pull json messages and notify progress.
+// error message in the stream is returned as error.
+func decodePullStream(r io.Reader, progress func(PullProgress), now func() time.Time) error {
+	tracker := newPullTracker(now)
+	dec := json.NewDecoder(r)
+
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if msg.ErrorMessage != "" {
+			return errors.New(msg.ErrorMessage)
+		}
+
+		changed := tracker.update(msg)
+		if progress != nil && (changed || now().Sub(tracker.notified) >= progressInterval) {
+			tracker.notified = now()
+			progress(tracker.progress)
+		}
+	}
+
+	if progress != nil {
+		tracker.progress.BytesPerSec = 0
+		progress(tracker.progress)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package docker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestDecodePullStream(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"Pulling from library/alpine","id":"latest"}`,
+		`{"status":"Already exists","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Pulling fs layer","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Downloading","progressDetail":{"current":500,"total":1000},"id":"bbb"}`,
+		`{"status":"Download complete","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Extracting","progressDetail":{"current":500,"total":1000},"id":"bbb"}`,
+		`{"status":"Pull complete","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Digest: sha256:0123"}`,
+		`{"status":"Status: Downloaded newer image for alpine:latest"}`,
+	}, "\n")
+
+	var percents []float64
+	var last PullProgress
+	err := decodePullStream(strings.NewReader(stream), func(p PullProgress) {
+		percents = append(percents, p.Percent())
+		last = p
+	}, fakeClock(time.Second))
+
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	for i := 1; i < len(percents); i++ {
+		if percents[i] < percents[i-1] {
+			t.Errorf("Expected percent not to decrease. Got %v.", percents)
+		}
+	}
+
+	if got := last.Percent(); got != 100 {
+		t.Errorf("Expected 100%%. Got %.1f%%.", got)
+	}
+
+	if got := last.String(); got != "100.0% 2/2 layers 0.0MB/s" {
+		t.Errorf("Expected summary. Got %s.", got)
+	}
+}
+
+func TestDecodePullStreamProgress(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"Downloading","progressDetail":{"current":0,"total":4194304},"id":"bbb"}`,
+		`{"status":"Downloading","progressDetail":{"current":2097152,"total":4194304},"id":"bbb"}`,
+	}, "\n")
+
+	var got []PullProgress
+	err := decodePullStream(strings.NewReader(stream), func(p PullProgress) {
+		got = append(got, PullProgress{BytesPerSec: p.BytesPerSec})
+	}, fakeClock(time.Second))
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if len(got) < 2 {
+		t.Fatalf("Expected progress notifications. Got %d.", len(got))
+	}
+
+	if rate := got[1].BytesPerSec; rate <= 0 {
+		t.Errorf("Expected bytes per second. Got %f.", rate)
+	}
+}
+
+func TestDecodePullStreamWaitingLayers(t *testing.T) {
+	// docker downloads a few layers at once, and the others wait without sizes
+	stream := strings.Join([]string{
+		`{"status":"Pulling fs layer","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Waiting","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Waiting","progressDetail":{},"id":"ccc"}`,
+		`{"status":"Downloading","progressDetail":{"current":90,"total":100},"id":"aaa"}`,
+		`{"status":"Download complete","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Downloading","progressDetail":{"current":10,"total":100000},"id":"bbb"}`,
+		`{"status":"Extracting","progressDetail":{"current":100,"total":100},"id":"aaa"}`,
+		`{"status":"Pull complete","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Downloading","progressDetail":{"current":10,"total":500000},"id":"ccc"}`,
+		`{"status":"Downloading","progressDetail":{"current":100000,"total":100000},"id":"bbb"}`,
+		`{"status":"Download complete","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Pull complete","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Download complete","progressDetail":{},"id":"ccc"}`,
+		`{"status":"Pull complete","progressDetail":{},"id":"ccc"}`,
+	}, "\n")
+
+	tracker := newPullTracker(fakeClock(time.Second))
+	dec := json.NewDecoder(strings.NewReader(stream))
+	var percents []float64
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err != nil {
+			break
+		}
+		tracker.update(msg)
+		percents = append(percents, tracker.progress.Percent())
+	}
+
+	for i := 1; i < len(percents); i++ {
+		if percents[i] < percents[i-1] {
+			t.Errorf("Expected percent not to decrease. Got %v.", percents)
+			break
+		}
+	}
+	if got := percents[len(percents)-1]; got != 100 {
+		t.Errorf("Expected 100%%. Got %.1f%%.", got)
+	}
+}
+
+func TestDecodePullStreamError(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"Pulling from library/alpine","id":"nothing"}`,
+		`{"errorDetail":{"message":"manifest for alpine:nothing not found: manifest unknown"},"error":"manifest for alpine:nothing not found: manifest unknown"}`,
+	}, "\n")
+
+	err := decodePullStream(strings.NewReader(stream), nil, time.Now)
+	if err == nil {
+		t.Fatal("Expected error. Got nil.")
+	}
+
+	if !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("Expected manifest unknown. Got %s.", err)
+	}
+}
@@ -0,0 +1,135 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// RecreateContainer replace the container with a new container created from spec.
+// the old container is stopped and renamed aside, and removed only after the new
+// container is created, and started when the old one was running.
+// anonymous and image volumes of the old container are kept like compose. when a step fails, the old container is restored.
+func RecreateContainer(e Engine, id string, spec ContainerSpec, progress func(string)) error {
+	old, err := e.InspectContainer(id)
+	if err != nil {
+		return err
+	}
+	oldName := strings.TrimPrefix(old.Name, "/")
+	wasRunning := old.State != nil && old.State.Running
+
+	// the daemon removes the container started with --rm when it is stopped, and it cannot be restored
+	if wasRunning && old.HostConfig != nil && old.HostConfig.AutoRemove {
+		return fmt.Errorf("cannot recreate %s: it is started with --rm and is removed when stopped", oldName)
+	}
+
+	var imageVolumes map[string]struct{}
+	if image, err := e.InspectImage(old.Image); err == nil && image.Config != nil {
+		imageVolumes = image.Config.Volumes
+	}
+	spec = keepVolumes(spec, old.Mounts, imageVolumes)
+
+	// options are validated before touching the old container
+	options, err := e.NewContainerOptions(spec)
+	if err != nil {
+		return err
+	}
+
+	// rollback restore the old container, and return err with the rollback errors
+	var rollback []func() error
+	fail := func(err error) error {
+		progress("rollback")
+		var errs []string
+		for i := len(rollback) - 1; i >= 0; i-- {
+			if rerr := rollback[i](); rerr != nil {
+				errs = append(errs, rerr.Error())
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%s (rollback failed: %s)", err, strings.Join(errs, ", "))
+		}
+		return err
+	}
+
+	if wasRunning {
+		progress("stop " + oldName)
+		if err := e.StopContainer(old.ID, nil); err != nil {
+			return err
+		}
+		rollback = append(rollback, func() error {
+			return e.StartContainer(old.ID)
+		})
+	}
+
+	asideName := fmt.Sprintf("%s-old-%d", oldName, time.Now().Unix())
+	progress("rename " + oldName + " to " + asideName)
+	if err := e.RenameContainer(old.ID, asideName); err != nil {
+		return fail(err)
+	}
+	rollback = append(rollback, func() error {
+		return e.RenameContainer(old.ID, oldName)
+	})
+
+	progress("create " + spec.Name)
+	newID, err := e.CreateContainer(options)
+	if err != nil {
+		return fail(err)
+	}
+	rollback = append(rollback, func() error {
+		return e.RemoveContainer(newID)
+	})
+
+	if wasRunning {
+		progress("start " + spec.Name)
+		if err := e.StartContainer(newID); err != nil {
+			return fail(err)
+		}
+	}
+
+	progress("remove " + asideName)
+	if err := e.RemoveContainer(old.ID); err != nil {
+		// the new container is already in place, so the old container is left renamed
+		return fmt.Errorf("cannot remove old container %s: %s", asideName, err)
+	}
+
+	return nil
+}
+
+// keepVolumes reuse volumes of the old container for anonymous volumes in spec, and for image volumes
+// which are not mounted in spec. other mounts of the old container, which the user removed, are not added.
+func keepVolumes(spec ContainerSpec, mounts []types.MountPoint, imageVolumes map[string]struct{}) ContainerSpec {
+	specMounts := make([]MountSpec, len(spec.Mounts))
+	copy(specMounts, spec.Mounts)
+
+	for _, m := range mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" {
+			continue
+		}
+
+		found := false
+		for i := range specMounts {
+			if specMounts[i].Target != m.Destination {
+				continue
+			}
+			found = true
+			if specMounts[i].Type == mount.TypeVolume && specMounts[i].Source == "" {
+				specMounts[i].Source = m.Name
+			}
+		}
+		// image volumes are not shown in the form, so they are not removed by the user
+		if _, ok := imageVolumes[m.Destination]; ok && !found {
+			specMounts = append(specMounts, MountSpec{
+				Type:     mount.TypeVolume,
+				Source:   m.Name,
+				Target:   m.Destination,
+				ReadOnly: !m.RW,
+			})
+		}
+	}
+
+	spec.Mounts = specMounts
+	return spec
+}
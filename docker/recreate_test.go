@@ -0,0 +1,173 @@
+package docker
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+func newRecreateFake() *Fake {
+	f := NewFake()
+	f.ContainerItems = []types.Container{
+		{ID: "old", Names: []string{"/web"}, Image: "nginx", State: "running"},
+	}
+	f.ImageItems = []types.ImageSummary{
+		{ID: "sha256:nginx", RepoTags: []string{"nginx:latest"}},
+	}
+	return f
+}
+
+func TestRecreateContainer(t *testing.T) {
+	f := newRecreateFake()
+
+	spec := ContainerSpec{Name: "web", Image: "nginx:latest", Env: []string{"A=1"}}
+	if err := RecreateContainer(f, "old", spec, func(string) {}); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	if len(f.ContainerItems) != 1 {
+		t.Fatalf("Expected 1 container. Got %+v.", f.ContainerItems)
+	}
+	c := f.ContainerItems[0]
+	if c.ID == "old" || c.Names[0] != "/web" || c.State != "running" {
+		t.Errorf("Expected new running container web. Got %+v.", c)
+	}
+}
+
+func TestRecreateContainerRollback(t *testing.T) {
+	f := newRecreateFake()
+	f.Errs["CreateContainer"] = errors.New("create failed")
+
+	spec := ContainerSpec{Name: "web", Image: "nginx:latest"}
+	err := RecreateContainer(f, "old", spec, func(string) {})
+	if err == nil || err.Error() != "create failed" {
+		t.Fatalf("Expected create failed. Got %v.", err)
+	}
+
+	if len(f.ContainerItems) != 1 {
+		t.Fatalf("Expected 1 container. Got %+v.", f.ContainerItems)
+	}
+	c := f.ContainerItems[0]
+	if c.ID != "old" || c.Names[0] != "/web" || c.State != "running" {
+		t.Errorf("Expected old container is restored. Got %+v.", c)
+	}
+}
+
+func TestRecreateContainerStartFailed(t *testing.T) {
+	f := newRecreateFake()
+	f.Errs["StartContainer"] = errors.New("start failed")
+
+	spec := ContainerSpec{Name: "web", Image: "nginx:latest"}
+	if err := RecreateContainer(f, "old", spec, func(string) {}); err == nil {
+		t.Fatal("Expected error.")
+	}
+
+	// the new container is removed, and the old one is renamed back
+	if len(f.ContainerItems) != 1 || f.ContainerItems[0].ID != "old" || f.ContainerItems[0].Names[0] != "/web" {
+		t.Errorf("Expected old container is restored. Got %+v.", f.ContainerItems)
+	}
+}
+
+func TestRecreateStoppedContainer(t *testing.T) {
+	f := newRecreateFake()
+	f.ContainerItems[0].State = "exited"
+
+	spec := ContainerSpec{Name: "web", Image: "nginx:latest"}
+	if err := RecreateContainer(f, "old", spec, func(string) {}); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	if len(f.ContainerItems) != 1 || f.ContainerItems[0].ID == "old" || f.ContainerItems[0].State != "created" {
+		t.Errorf("Expected new container is created but not started. Got %+v.", f.ContainerItems)
+	}
+	for _, call := range f.Calls {
+		if strings.HasPrefix(call, "StopContainer") || strings.HasPrefix(call, "StartContainer") {
+			t.Errorf("Expected stopped container is not started. Got %s.", call)
+		}
+	}
+}
+
+func TestRecreateContainerKeepVolumes(t *testing.T) {
+	f := newRecreateFake()
+	f.ContainerItems[0].Mounts = []types.MountPoint{
+		// image volume
+		{Type: mount.TypeVolume, Name: "3f2a9c", Destination: "/var/lib/postgresql/data", RW: true},
+		// anonymous volume
+		{Type: mount.TypeVolume, Name: "8b1e77", Destination: "/cache", RW: true},
+		{Type: mount.TypeVolume, Name: "logs", Destination: "/logs", RW: true},
+		// removed in the form
+		{Type: mount.TypeVolume, Name: "5d0c41", Destination: "/tmp", RW: true},
+		{Type: mount.TypeVolume, Name: "uploads", Destination: "/uploads", RW: true},
+		{Type: mount.TypeBind, Source: "/etc/app", Destination: "/etc/app"},
+	}
+	f.ImageInspects["nginx"] = types.ImageInspect{ID: "sha256:nginx", Config: &container.Config{
+		Volumes: map[string]struct{}{"/var/lib/postgresql/data": {}},
+	}}
+
+	spec := ContainerSpec{Name: "web", Image: "nginx:latest", Mounts: []MountSpec{
+		{Type: mount.TypeVolume, Target: "/cache"},
+		{Type: mount.TypeVolume, Source: "new-logs", Target: "/logs"},
+		{Type: mount.TypeBind, Source: "/etc/app", Target: "/etc/app"},
+	}}
+	if err := RecreateContainer(f, "old", spec, func(string) {}); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	volumes := make(map[string]string)
+	for _, m := range f.ContainerItems[0].Mounts {
+		if m.Type == mount.TypeVolume {
+			volumes[m.Destination] = m.Name
+		}
+	}
+	want := map[string]string{
+		"/var/lib/postgresql/data": "3f2a9c",
+		"/cache":                   "8b1e77",
+		"/logs":                    "new-logs",
+	}
+	if !reflect.DeepEqual(volumes, want) {
+		t.Errorf("Expected %v. Got %v.", want, volumes)
+	}
+	if len(spec.Mounts) != 3 || spec.Mounts[0].Source != "" {
+		t.Errorf("Expected spec is not changed. Got %+v.", spec.Mounts)
+	}
+}
+
+func TestRecreateAutoRemoveContainer(t *testing.T) {
+	f := newRecreateFake()
+	f.ContainerJSONs["old"] = types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+		ID:         "old",
+		Name:       "/web",
+		Image:      "nginx",
+		State:      &types.ContainerState{Status: "running", Running: true},
+		HostConfig: &container.HostConfig{AutoRemove: true},
+	}}
+
+	spec := ContainerSpec{Name: "web", Image: "nginx:latest"}
+	if err := RecreateContainer(f, "old", spec, func(string) {}); err == nil {
+		t.Fatal("Expected error.")
+	}
+	for _, call := range f.Calls {
+		if strings.HasPrefix(call, "StopContainer") || strings.HasPrefix(call, "CreateContainer") {
+			t.Errorf("Expected the old container is untouched. Got %s.", call)
+		}
+	}
+}
+
+func TestRecreateContainerInvalidSpec(t *testing.T) {
+	f := newRecreateFake()
+
+	spec := ContainerSpec{Name: "web", Image: "nginx:latest", Aliases: []string{"www"}}
+	if err := RecreateContainer(f, "old", spec, func(string) {}); err == nil {
+		t.Fatal("Expected error.")
+	}
+	for _, call := range f.Calls {
+		if strings.HasPrefix(call, "StopContainer") || strings.HasPrefix(call, "RenameContainer") {
+			t.Errorf("Expected the old container is untouched. Got %s.", call)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ExecSession interactive exec session.
+// Read returns the output of the process, Write sends the input to it.
+type ExecSession interface {
+	io.ReadWriteCloser
+	// Resize change the tty size of the process
+	Resize(height, width uint) error
+}
+
+type execSession struct {
+	d      *Docker
+	id     string
+	tty    bool
+	resp   types.HijackedResponse
+	output io.Reader
+}
+
+// StartExecSession start exec process in the container, and return the session connected to it
+func (d *Docker) StartExecSession(container string, opt ExecOptions) (ExecSession, error) {
+	exec, err := d.CreateExec(container, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.ContainerExecAttach(context.TODO(), exec.ID, types.ExecStartCheck{Tty: opt.Tty})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &execSession{
+		d:      d,
+		id:     exec.ID,
+		tty:    opt.Tty,
+		resp:   resp,
+		output: resp.Reader,
+	}
+
+	// stdout and stderr are multiplexed without tty
+	if !opt.Tty {
+		r, w := io.Pipe()
+		go func() {
+			_, err := stdcopy.StdCopy(w, w, resp.Reader)
+			w.CloseWithError(err)
+		}()
+		s.output = r
+	}
+
+	return s, nil
+}
+
+func (s *execSession) Read(p []byte) (int, error) {
+	return s.output.Read(p)
+}
+
+func (s *execSession) Write(p []byte) (int, error) {
+	return s.resp.Conn.Write(p)
+}
+
+func (s *execSession) Close() error {
+	s.resp.Close()
+	return nil
+}
+
+func (s *execSession) Resize(height, width uint) error {
+	if !s.tty {
+		return nil
+	}
+	return s.d.ContainerExecResize(context.TODO(), s.id, types.ResizeOptions{Height: height, Width: width})
+}
@@ -0,0 +1,398 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+)
+
+// PortBinding publish the container port to the host
+type PortBinding struct {
+	HostIP        string
+	HostPort      string
+	ContainerPort string
+	// Protocol tcp or udp, empty is tcp
+	Protocol string
+}
+
+// MountSpec mount of the container
+type MountSpec struct {
+	// Type bind, volume or tmpfs
+	Type mount.Type
+	// Source host path of bind, or volume name
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// ContainerSpec settings to create container
+type ContainerSpec struct {
+	Name       string
+	Image      string
+	User       string
+	Cmd        []string
+	Entrypoint []string
+	Env        []string
+	WorkingDir string
+	Labels     map[string]string
+	Ports      []PortBinding
+	Mounts     []MountSpec
+	// RestartPolicy no, always, unless-stopped or on-failure[:max-retries]
+	RestartPolicy string
+	Network       string
+	Aliases       []string
+	// Memory memory limit in bytes, 0 is unlimited
+	Memory int64
+	// CPUs number of CPUs, 0 is unlimited
+	CPUs       float64
+	CapAdd     []string
+	CapDrop    []string
+	Privileged bool
+	Tty        bool
+	OpenStdin  bool
+	// AutoRemove remove the container when it exits
+	AutoRemove bool
+}
+
+// ParsePort parse port binding like docker run -p, e.g. "127.0.0.1:8080:80/udp"
+func ParsePort(s string) (PortBinding, error) {
+	var p PortBinding
+	rest := strings.TrimSpace(s)
+
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		rest, p.Protocol = rest[:i], strings.ToLower(rest[i+1:])
+	}
+
+	// IPv6 host ip is enclosed in brackets, e.g. "[::1]:8080:80"
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]:")
+		if end < 0 {
+			return p, fmt.Errorf("invalid port %s", s)
+		}
+		p.HostIP, rest = rest[1:end], rest[end+2:]
+	}
+
+	parts := strings.Split(rest, ":")
+	switch {
+	case len(parts) == 1:
+		p.ContainerPort = parts[0]
+	case len(parts) == 2:
+		p.HostPort, p.ContainerPort = parts[0], parts[1]
+	case len(parts) == 3 && p.HostIP == "":
+		p.HostIP, p.HostPort, p.ContainerPort = parts[0], parts[1], parts[2]
+	default:
+		return p, fmt.Errorf("invalid port %s", s)
+	}
+
+	if _, err := p.port(); err != nil {
+		return p, err
+	}
+	if p.HostPort != "" {
+		if _, err := strconv.ParseUint(p.HostPort, 10, 16); err != nil {
+			return p, fmt.Errorf("invalid host port %s", p.HostPort)
+		}
+	}
+
+	return p, nil
+}
+
+// port return the container port with the protocol
+func (p PortBinding) port() (nat.Port, error) {
+	proto := p.Protocol
+	if proto == "" {
+		proto = "tcp"
+	}
+	if proto != "tcp" && proto != "udp" && proto != "sctp" {
+		return "", fmt.Errorf("invalid protocol %s", proto)
+	}
+
+	if _, err := strconv.ParseUint(p.ContainerPort, 10, 16); err != nil {
+		return "", fmt.Errorf("invalid container port %s", p.ContainerPort)
+	}
+
+	return nat.NewPort(proto, p.ContainerPort)
+}
+
+// String format the binding like docker run -p
+func (p PortBinding) String() string {
+	s := p.ContainerPort
+	if p.HostPort != "" || p.HostIP != "" {
+		s = p.HostPort + ":" + s
+	}
+	if p.HostIP != "" {
+		ip := p.HostIP
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		s = ip + ":" + s
+	}
+	if p.Protocol != "" && p.Protocol != "tcp" {
+		s += "/" + p.Protocol
+	}
+	return s
+}
+
+// ParseMount parse mount like docker run --mount, e.g. "type=bind,source=/src,target=/app,readonly"
+func ParseMount(s string) (MountSpec, error) {
+	m := MountSpec{Type: mount.TypeVolume}
+
+	for _, field := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		key, value := strings.ToLower(kv[0]), ""
+		if len(kv) == 2 {
+			value = kv[1]
+		}
+
+		switch key {
+		case "type":
+			m.Type = mount.Type(value)
+		case "source", "src":
+			m.Source = value
+		case "target", "destination", "dst":
+			m.Target = value
+		case "readonly", "ro":
+			if value == "" {
+				m.ReadOnly = true
+				continue
+			}
+			ro, err := strconv.ParseBool(value)
+			if err != nil {
+				return m, fmt.Errorf("invalid readonly %s", value)
+			}
+			m.ReadOnly = ro
+		case "":
+		default:
+			return m, fmt.Errorf("unsupported mount option %s", key)
+		}
+	}
+
+	return m, m.validate()
+}
+
+// ParseVolume parse mount like docker run -v, e.g. "/src:/app:ro" or "data:/var/lib/data"
+func ParseVolume(s string) (MountSpec, error) {
+	m := MountSpec{Type: mount.TypeVolume}
+
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 1:
+		// anonymous volume
+		m.Target = parts[0]
+	case 2, 3:
+		m.Source, m.Target = parts[0], parts[1]
+		if len(parts) == 3 {
+			for _, opt := range strings.Split(parts[2], ",") {
+				switch opt {
+				case "ro":
+					m.ReadOnly = true
+				case "rw":
+					m.ReadOnly = false
+				default:
+					return m, fmt.Errorf("unsupported volume option %s", opt)
+				}
+			}
+		}
+	default:
+		return m, fmt.Errorf("invalid volume %s", s)
+	}
+
+	if strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~") {
+		m.Type = mount.TypeBind
+	}
+
+	return m, m.validate()
+}
+
+func (m MountSpec) validate() error {
+	if m.Target == "" {
+		return fmt.Errorf("mount target is required")
+	}
+	if !strings.HasPrefix(m.Target, "/") {
+		return fmt.Errorf("mount target %s must be absolute path", m.Target)
+	}
+
+	switch m.Type {
+	case mount.TypeBind:
+		if m.Source == "" {
+			return fmt.Errorf("bind mount needs source")
+		}
+	case mount.TypeVolume:
+	case mount.TypeTmpfs:
+		if m.Source != "" {
+			return fmt.Errorf("tmpfs mount can't have source")
+		}
+	default:
+		return fmt.Errorf("unsupported mount type %s", m.Type)
+	}
+	return nil
+}
+
+// String format the mount like docker run --mount
+func (m MountSpec) String() string {
+	fields := []string{"type=" + string(m.Type)}
+	if m.Source != "" {
+		fields = append(fields, "source="+m.Source)
+	}
+	fields = append(fields, "target="+m.Target)
+	if m.ReadOnly {
+		fields = append(fields, "readonly")
+	}
+	return strings.Join(fields, ",")
+}
+
+// ParseLabel parse label like docker run --label, e.g. "tier=web"
+func ParseLabel(s string) (string, string, error) {
+	kv := strings.SplitN(s, "=", 2)
+	if kv[0] == "" {
+		return "", "", fmt.Errorf("invalid label %s", s)
+	}
+	if len(kv) == 1 {
+		return kv[0], "", nil
+	}
+	return kv[0], kv[1], nil
+}
+
+// ParseRestartPolicy parse restart policy like docker run --restart, e.g. "on-failure:3"
+func ParseRestartPolicy(s string) (container.RestartPolicy, error) {
+	var policy container.RestartPolicy
+	if s == "" {
+		return policy, nil
+	}
+
+	kv := strings.SplitN(s, ":", 2)
+	policy.Name = kv[0]
+
+	switch policy.Name {
+	case "no", "always", "unless-stopped":
+		if len(kv) == 2 {
+			return policy, fmt.Errorf("maximum retry count can't be used with restart policy %s", policy.Name)
+		}
+	case "on-failure":
+		if len(kv) == 2 {
+			count, err := strconv.Atoi(kv[1])
+			if err != nil || count < 0 {
+				return policy, fmt.Errorf("invalid maximum retry count %s", kv[1])
+			}
+			policy.MaximumRetryCount = count
+		}
+	default:
+		return policy, fmt.Errorf("invalid restart policy %s", s)
+	}
+
+	return policy, nil
+}
+
+// newContainerOptions convert spec to options to create container.
+// environment variables of the image are prepended to the spec.
+func newContainerOptions(image types.ImageInspect, spec ContainerSpec) (CreateContainerOptions, error) {
+	options := CreateContainerOptions{
+		Name: spec.Name,
+		Config: &container.Config{
+			Image:        spec.Image,
+			User:         spec.User,
+			Cmd:          spec.Cmd,
+			Entrypoint:   spec.Entrypoint,
+			WorkingDir:   spec.WorkingDir,
+			Labels:       spec.Labels,
+			AttachStdout: true,
+			AttachStderr: true,
+			Tty:          spec.Tty,
+			OpenStdin:    spec.OpenStdin,
+			AttachStdin:  spec.OpenStdin,
+		},
+		HostConfig: &container.HostConfig{
+			Privileged: spec.Privileged,
+			AutoRemove: spec.AutoRemove,
+			CapAdd:     spec.CapAdd,
+			CapDrop:    spec.CapDrop,
+		},
+	}
+
+	if image.Config != nil {
+		options.Config.Env = append(options.Config.Env, image.Config.Env...)
+	}
+	options.Config.Env = append(options.Config.Env, spec.Env...)
+
+	if len(spec.Ports) > 0 {
+		options.Config.ExposedPorts = nat.PortSet{}
+		options.HostConfig.PortBindings = nat.PortMap{}
+	}
+	for _, p := range spec.Ports {
+		port, err := p.port()
+		if err != nil {
+			return options, err
+		}
+
+		options.Config.ExposedPorts[port] = struct{}{}
+		options.HostConfig.PortBindings[port] = append(options.HostConfig.PortBindings[port], nat.PortBinding{
+			HostIP:   p.HostIP,
+			HostPort: p.HostPort,
+		})
+	}
+
+	for _, m := range spec.Mounts {
+		if err := m.validate(); err != nil {
+			return options, err
+		}
+		options.HostConfig.Mounts = append(options.HostConfig.Mounts, mount.Mount{
+			Type:     m.Type,
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+
+	policy, err := ParseRestartPolicy(spec.RestartPolicy)
+	if err != nil {
+		return options, err
+	}
+	options.HostConfig.RestartPolicy = policy
+
+	options.HostConfig.Memory = spec.Memory
+	options.HostConfig.NanoCPUs = int64(spec.CPUs * 1e9)
+
+	if spec.Network != "" {
+		options.HostConfig.NetworkMode = container.NetworkMode(spec.Network)
+		options.NetworkConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				spec.Network: {Aliases: spec.Aliases},
+			},
+		}
+	} else if len(spec.Aliases) > 0 {
+		return options, fmt.Errorf("network aliases need network")
+	}
+
+	return options, nil
+}
+
+// ParseMemory parse memory size like docker run --memory, e.g. "512m"
+func ParseMemory(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return units.RAMInBytes(s)
+}
+
+// FormatMemory format memory size like docker run --memory
+func FormatMemory(size int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"g", 1 << 30},
+		{"m", 1 << 20},
+		{"k", 1 << 10},
+	} {
+		if size >= unit.size && size%unit.size == 0 {
+			return fmt.Sprintf("%d%s", size/unit.size, unit.suffix)
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestParsePort(t *testing.T) {
+	tests := []struct {
+		in   string
+		want PortBinding
+		err  bool
+	}{
+		{in: "80", want: PortBinding{ContainerPort: "80"}},
+		{in: "8080:80", want: PortBinding{HostPort: "8080", ContainerPort: "80"}},
+		{in: "127.0.0.1:53:53/udp", want: PortBinding{HostIP: "127.0.0.1", HostPort: "53", ContainerPort: "53", Protocol: "udp"}},
+		{in: "[::1]:8080:80", want: PortBinding{HostIP: "::1", HostPort: "8080", ContainerPort: "80"}},
+		{in: "127.0.0.1::80", want: PortBinding{HostIP: "127.0.0.1", ContainerPort: "80"}},
+		{in: "80/icmp", err: true},
+		{in: "http", err: true},
+		{in: "a:b:c:d", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePort(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Expected error for %s. Got %+v.", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Expected %+v. Got %+v (%v).", tt.want, got, err)
+		}
+		if s := got.String(); s != tt.in {
+			t.Errorf("Expected %s. Got %s.", tt.in, s)
+		}
+	}
+}
+
+func TestParseMount(t *testing.T) {
+	got, err := ParseMount("type=bind,source=/src,target=/app,readonly")
+	want := MountSpec{Type: mount.TypeBind, Source: "/src", Target: "/app", ReadOnly: true}
+	if err != nil || got != want {
+		t.Errorf("Expected %+v. Got %+v (%v).", want, got, err)
+	}
+	if s := got.String(); s != "type=bind,source=/src,target=/app,readonly" {
+		t.Errorf("Unexpected string %s.", s)
+	}
+
+	if _, err := ParseMount("type=tmpfs,src=/tmp,dst=/tmp"); err == nil {
+		t.Error("Expected error for tmpfs with source.")
+	}
+	if _, err := ParseMount("type=volume,source=data"); err == nil {
+		t.Error("Expected error without target.")
+	}
+
+	tests := []struct {
+		in   string
+		want MountSpec
+	}{
+		{in: "/data", want: MountSpec{Type: mount.TypeVolume, Target: "/data"}},
+		{in: "data:/var/lib/data", want: MountSpec{Type: mount.TypeVolume, Source: "data", Target: "/var/lib/data"}},
+		{in: "./src:/app:ro", want: MountSpec{Type: mount.TypeBind, Source: "./src", Target: "/app", ReadOnly: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseVolume(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Expected %+v. Got %+v (%v).", tt.want, got, err)
+		}
+	}
+}
+
+func TestParseRestartPolicy(t *testing.T) {
+	policy, err := ParseRestartPolicy("on-failure:3")
+	if err != nil || policy.Name != "on-failure" || policy.MaximumRetryCount != 3 {
+		t.Errorf("Unexpected policy %+v (%v).", policy, err)
+	}
+
+	for _, s := range []string{"always:3", "sometimes", "on-failure:x"} {
+		if _, err := ParseRestartPolicy(s); err == nil {
+			t.Errorf("Expected error for %s.", s)
+		}
+	}
+}
+
+func TestNewContainerOptions(t *testing.T) {
+	image := types.ImageInspect{Config: &container.Config{Env: []string{"PATH=/bin"}}}
+	spec := ContainerSpec{
+		Name:  "web",
+		Image: "nginx",
+		Env:   []string{"MODE=prod", "PW=$ecret"},
+		Ports: []PortBinding{
+			{HostPort: "8080", ContainerPort: "80"},
+			{HostPort: "8443", ContainerPort: "80"},
+			{ContainerPort: "53", Protocol: "udp"},
+		},
+		Mounts: []MountSpec{
+			{Type: mount.TypeTmpfs, Target: "/run"},
+		},
+		RestartPolicy: "unless-stopped",
+		Network:       "backend",
+		Aliases:       []string{"web"},
+		Memory:        512 * 1024 * 1024,
+		CPUs:          1.5,
+	}
+
+	options, err := newContainerOptions(image, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := options.Config.Env; !reflect.DeepEqual(got, []string{"PATH=/bin", "MODE=prod", "PW=$ecret"}) {
+		t.Errorf("Unexpected env %v.", got)
+	}
+	if got := len(options.HostConfig.PortBindings["80/tcp"]); got != 2 {
+		t.Errorf("Expected 2 bindings of 80/tcp. Got %d.", got)
+	}
+	if _, ok := options.Config.ExposedPorts[nat.Port("53/udp")]; !ok {
+		t.Errorf("Expected 53/udp exposed. Got %v.", options.Config.ExposedPorts)
+	}
+	if got := options.HostConfig.NanoCPUs; got != 1500000000 {
+		t.Errorf("Expected 1.5 CPUs. Got %d.", got)
+	}
+	if got := options.NetworkConfig.EndpointsConfig["backend"].Aliases; !reflect.DeepEqual(got, []string{"web"}) {
+		t.Errorf("Unexpected aliases %v.", got)
+	}
+	if got := options.HostConfig.RestartPolicy.Name; got != "unless-stopped" {
+		t.Errorf("Unexpected restart policy %s.", got)
+	}
+
+	spec.Network = ""
+	if _, err := newContainerOptions(image, spec); err == nil {
+		t.Error("Expected error for aliases without network.")
+	}
+}
@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Stats container resource usage
+type Stats struct {
+	Read          time.Time
+	CPUPercent    float64
+	MemoryUsage   uint64
+	MemoryLimit   uint64
+	MemoryPercent float64
+	NetworkRx     uint64
+	NetworkTx     uint64
+	BlockRead     uint64
+	BlockWrite    uint64
+}
+
+// WatchStats stream resource usage of the container and call handler for each sample.
+// It returns when ctx is done or the container is stopped.
+func (d *Docker) WatchStats(ctx context.Context, id string, handler func(Stats)) error {
+	resp, err := d.ContainerStats(ctx, id, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var v types.StatsJSON
+		if err := dec.Decode(&v); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		handler(calculateStats(&v))
+	}
+}
+
+// calculateStats calculate usage in the same way as docker stats command
+func calculateStats(v *types.StatsJSON) Stats {
+	s := Stats{
+		Read:        v.Read,
+		MemoryUsage: v.MemoryStats.Usage,
+		MemoryLimit: v.MemoryStats.Limit,
+	}
+
+	// the first sample doesn't have previous cpu usage
+	if v.PreCPUStats.SystemUsage != 0 {
+		cpuDelta := float64(v.CPUStats.CPUUsage.TotalUsage) - float64(v.PreCPUStats.CPUUsage.TotalUsage)
+		systemDelta := float64(v.CPUStats.SystemUsage) - float64(v.PreCPUStats.SystemUsage)
+
+		onlineCPUs := float64(v.CPUStats.OnlineCPUs)
+		if onlineCPUs == 0 {
+			onlineCPUs = float64(len(v.CPUStats.CPUUsage.PercpuUsage))
+		}
+
+		if cpuDelta > 0 && systemDelta > 0 {
+			s.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+		}
+	}
+
+	// page cache is not counted as used memory
+	if cache, ok := v.MemoryStats.Stats["cache"]; ok && cache < s.MemoryUsage {
+		s.MemoryUsage -= cache
+	}
+
+	if s.MemoryLimit != 0 {
+		s.MemoryPercent = float64(s.MemoryUsage) / float64(s.MemoryLimit) * 100
+	}
+
+	for _, n := range v.Networks {
+		s.NetworkRx += n.RxBytes
+		s.NetworkTx += n.TxBytes
+	}
+
+	for _, entry := range v.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			s.BlockRead += entry.Value
+		case "write":
+			s.BlockWrite += entry.Value
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestCalculateStats(t *testing.T) {
+	v := &types.StatsJSON{}
+	v.CPUStats.CPUUsage.TotalUsage = 400
+	v.CPUStats.SystemUsage = 2000
+	v.CPUStats.OnlineCPUs = 2
+	v.PreCPUStats.CPUUsage.TotalUsage = 200
+	v.PreCPUStats.SystemUsage = 1000
+	v.MemoryStats.Usage = 300
+	v.MemoryStats.Limit = 1000
+	v.MemoryStats.Stats = map[string]uint64{"cache": 100}
+	v.Networks = map[string]types.NetworkStats{
+		"eth0": {RxBytes: 10, TxBytes: 20},
+		"eth1": {RxBytes: 1, TxBytes: 2},
+	}
+	v.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Op: "Read", Value: 5},
+		{Op: "Write", Value: 7},
+		{Op: "Total", Value: 12},
+	}
+
+	s := calculateStats(v)
+
+	if s.CPUPercent != 40 {
+		t.Errorf("Expected cpu 40%%. Got %v.", s.CPUPercent)
+	}
+	if s.MemoryUsage != 200 || s.MemoryPercent != 20 {
+		t.Errorf("Expected memory 200 (20%%). Got %d (%v%%).", s.MemoryUsage, s.MemoryPercent)
+	}
+	if s.NetworkRx != 11 || s.NetworkTx != 22 {
+		t.Errorf("Expected network 11/22. Got %d/%d.", s.NetworkRx, s.NetworkTx)
+	}
+	if s.BlockRead != 5 || s.BlockWrite != 7 {
+		t.Errorf("Expected block 5/7. Got %d/%d.", s.BlockRead, s.BlockWrite)
+	}
+
+	// the first sample has no previous cpu usage
+	v.PreCPUStats = types.CPUStats{}
+	if s := calculateStats(v); s.CPUPercent != 0 {
+		t.Errorf("Expected cpu 0%% without previous sample. Got %v.", s.CPUPercent)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"io"
 	"log"
 	"os"
+	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/docker/pkg/term"
 	"github.com/skanehira/docui/common"
 )
@@ -26,21 +28,59 @@ type Streamer struct {
 	Out   *Out
 	Err   io.Writer
 	isTty bool
+	// detachKeys key sequence to detach from the stream
+	detachKeys []byte
+	// signalProxy send the signal to the container, Ctrl+C is forwarded with it when tty is false
+	signalProxy func(signal string)
 }
 
-func New() *Streamer {
+// New create streamer, stdout and stderr are multiplexed when tty is false
+func New(tty bool) *Streamer {
 	return &Streamer{
-		In:  NewIn(os.Stdin),
-		Out: NewOut(os.Stdout),
-		Err: os.Stderr,
+		In:    NewIn(os.Stdin),
+		Out:   NewOut(os.Stdout),
+		Err:   os.Stderr,
+		isTty: tty,
 	}
 }
 
+// SetDetachKeys set key sequence to detach, e.g. "ctrl-p,ctrl-q"
+func (s *Streamer) SetDetachKeys(keys string) error {
+	if keys == "" {
+		s.detachKeys = nil
+		return nil
+	}
+
+	b, err := term.ToBytes(keys)
+	if err != nil {
+		return err
+	}
+	s.detachKeys = b
+	return nil
+}
+
+// SetSignalProxy set the function to send signals to the container.
+// without tty the terminal is not in raw mode, so Ctrl+C interrupts docui unless it is forwarded.
+func (s *Streamer) SetSignalProxy(proxy func(signal string)) {
+	s.signalProxy = proxy
+}
+
 func (s *Streamer) Stream(ctx context.Context, id string, resp types.HijackedResponse, resize ResizeContainer) (err error) {
 	if id == "" {
 		return ErrEmptyExecID
 	}
 
+	if !s.isTty && s.signalProxy != nil {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, os.Interrupt)
+		done := make(chan struct{})
+		defer func() {
+			signal.Stop(sigc)
+			close(done)
+		}()
+		go s.forwardSignals(sigc, done)
+	}
+
 	errCh := make(chan error, 1)
 
 	go func() {
@@ -48,7 +88,7 @@ func (s *Streamer) Stream(ctx context.Context, id string, resp types.HijackedRes
 		errCh <- s.stream(ctx, resp)
 	}()
 
-	if s.In.IsTerminal {
+	if s.isTty && s.In.IsTerminal {
 		s.monitorTtySize(ctx, resize, id)
 	}
 
@@ -60,11 +100,27 @@ func (s *Streamer) Stream(ctx context.Context, id string, resp types.HijackedRes
 	return nil
 }
 
+// forwardSignals send interrupts to the container until done is closed
+func (s *Streamer) forwardSignals(sigc <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-sigc:
+			s.signalProxy("SIGINT")
+		case <-done:
+			return
+		}
+	}
+}
+
 func (s *Streamer) stream(ctx context.Context, resp types.HijackedResponse) error {
-	// set raw mode
-	restore, err := s.SetRawTerminal()
-	if err != nil {
-		return err
+	// set raw mode, without tty the terminal handles the line editing
+	restore := func() {}
+	if s.isTty {
+		var err error
+		restore, err = s.SetRawTerminal()
+		if err != nil {
+			return err
+		}
 	}
 	defer restore()
 
@@ -75,7 +131,11 @@ func (s *Streamer) stream(ctx context.Context, resp types.HijackedResponse) erro
 	select {
 	case err := <-outDone:
 		return err
-	case <-inDone:
+	case err := <-inDone:
+		// detached, the process keeps running
+		if _, ok := err.(term.EscapeError); ok {
+			return nil
+		}
 		select {
 		case err := <-outDone:
 			return err
@@ -87,15 +147,22 @@ func (s *Streamer) stream(ctx context.Context, resp types.HijackedResponse) erro
 	}
 }
 
-func (s *Streamer) streamIn(restore func(), resp types.HijackedResponse) <-chan struct{} {
-	done := make(chan struct{})
+func (s *Streamer) streamIn(restore func(), resp types.HijackedResponse) <-chan error {
+	done := make(chan error, 1)
 
 	go func() {
 		defer close(done)
-		_, err := io.Copy(resp.Conn, s.In)
+
+		var in io.Reader = s.In
+		if s.detachKeys != nil {
+			in = term.NewEscapeProxy(s.In, s.detachKeys)
+		}
+
+		_, err := io.Copy(resp.Conn, in)
 		restore()
 
 		if _, ok := err.(term.EscapeError); ok {
+			done <- err
 			return
 		}
 
@@ -116,7 +183,12 @@ func (s *Streamer) streamOut(restore func(), resp types.HijackedResponse) <-chan
 	done := make(chan error, 1)
 
 	go func() {
-		_, err := io.Copy(s.Out, resp.Reader)
+		var err error
+		if s.isTty {
+			_, err = io.Copy(s.Out, resp.Reader)
+		} else {
+			_, err = stdcopy.StdCopy(s.Out, s.Err, resp.Reader)
+		}
 		restore()
 
 		if err != nil {
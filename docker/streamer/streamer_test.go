@@ -0,0 +1,120 @@
+package streamer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/skanehira/docui/common"
+)
+
+func newTestStreamer(tty bool, input string) (*Streamer, *bytes.Buffer, *bytes.Buffer) {
+	common.NewLogger("error", "")
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	s := New(tty)
+	// key presses are read one by one from the terminal
+	s.In = NewIn(ioutil.NopCloser(iotest.OneByteReader(strings.NewReader(input))))
+	s.Out = NewOut(stdout)
+	s.Err = stderr
+	return s, stdout, stderr
+}
+
+func TestStreamDemultiplex(t *testing.T) {
+	var body bytes.Buffer
+	stdcopy.NewStdWriter(&body, stdcopy.Stdout).Write([]byte("out\n"))
+	stdcopy.NewStdWriter(&body, stdcopy.Stderr).Write([]byte("err\n"))
+
+	client, server := net.Pipe()
+	defer server.Close()
+	go server.Read(make([]byte, 1))
+
+	s, stdout, stderr := newTestStreamer(false, "")
+	resp := types.HijackedResponse{Conn: client, Reader: bufio.NewReader(&body)}
+	if err := s.Stream(context.Background(), "id", resp, nil); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	if stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Errorf("Expected out and err. Got %q and %q.", stdout, stderr)
+	}
+}
+
+func TestStreamDetach(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	// the container never closes the output
+	received := make(chan string, 1)
+	go func() {
+		b, _ := ioutil.ReadAll(server)
+		received <- string(b)
+	}()
+
+	s, _, _ := newTestStreamer(false, "ls\x10\x11echo")
+	if err := s.SetDetachKeys("ctrl-p,ctrl-q"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := types.HijackedResponse{Conn: client, Reader: bufio.NewReader(client)}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Stream(context.Background(), "id", resp, nil)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected no error. Got %s.", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected detached.")
+	}
+
+	client.Close()
+	if got := <-received; got != "ls" {
+		t.Errorf("Expected ls. Got %q.", got)
+	}
+}
+
+func TestSetDetachKeys(t *testing.T) {
+	s := New(true)
+	if err := s.SetDetachKeys("ctrl-x,y"); err != nil {
+		t.Errorf("Expected no error. Got %s.", err)
+	}
+	if err := s.SetDetachKeys("ctrl-"); err == nil {
+		t.Errorf("Expected error.")
+	}
+}
+
+func TestForwardSignals(t *testing.T) {
+	s := New(false)
+	sent := make(chan string, 1)
+	s.SetSignalProxy(func(signal string) {
+		sent <- signal
+	})
+
+	sigc := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go s.forwardSignals(sigc, done)
+
+	sigc <- os.Interrupt
+	select {
+	case signal := <-sent:
+		if signal != "SIGINT" {
+			t.Errorf("Expected SIGINT. Got %s.", signal)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the interrupt is forwarded.")
+	}
+}
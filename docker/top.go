@@ -0,0 +1,307 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// TopContainer list processes running in the container.
+// psArgs is passed to ps command, e.g. "aux".
+func (d *Docker) TopContainer(ctx context.Context, id, psArgs string) (container.ContainerTopOKBody, error) {
+	return d.ContainerTop(ctx, id, strings.Fields(psArgs))
+}
+
+// SignalProcess send signal to the process in the container with kill command.
+// pid is the PID in the container, use ContainerPID to translate the PID of TopContainer.
+func (d *Docker) SignalProcess(ctx context.Context, id string, pid int, signal string) error {
+	cmd, err := killCommand(pid, signal)
+	if err != nil {
+		return err
+	}
+
+	result, err := d.ExecCommand(ctx, id, ExecOptions{Cmd: cmd})
+	if err != nil {
+		return err
+	}
+
+	if result.ExitCode != 0 {
+		return fmt.Errorf("kill exited with %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr+result.Stdout))
+	}
+	return nil
+}
+
+// KillSignals signals suggested to send to containers
+var KillSignals = []string{
+	"SIGKILL", "SIGTERM", "SIGINT", "SIGHUP", "SIGQUIT",
+	"SIGUSR1", "SIGUSR2", "SIGSTOP", "SIGCONT", "SIGWINCH",
+}
+
+// ParseSignal validate the signal like TERM, SIGTERM or a number, and return it without "SIG"
+func ParseSignal(signal string) (string, error) {
+	signal = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(signal)), "SIG")
+	if signal == "" {
+		return "", fmt.Errorf("signal is empty")
+	}
+
+	if n, err := strconv.Atoi(signal); err == nil {
+		if n <= 0 {
+			return "", fmt.Errorf("invalid signal %s", signal)
+		}
+		return signal, nil
+	}
+
+	for _, r := range signal {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return "", fmt.Errorf("invalid signal %s", signal)
+		}
+	}
+	return signal, nil
+}
+
+// killCommand make kill command which sends signal to pid.
+// signal is a name like TERM, SIGTERM or a number.
+func killCommand(pid int, signal string) ([]string, error) {
+	if pid <= 0 {
+		return nil, fmt.Errorf("invalid pid %d", pid)
+	}
+
+	signal, err := ParseSignal(signal)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"kill", "-" + signal, strconv.Itoa(pid)}, nil
+}
+
+// ContainerPID translate the PID reported by TopContainer, which is the PID on the host, to the PID in the container
+func (d *Docker) ContainerPID(ctx context.Context, id string, pid int) (int, error) {
+	return containerPID(ctx, d, "/proc", id, pid)
+}
+
+// containerPID translate the PID on the host to the PID in the container.
+// when the daemon runs on this host, it is read from NSpid in proc/<pid>/status.
+// otherwise the process is found in the container by the command and the start time, and it needs sh in the container.
+func containerPID(ctx context.Context, e Engine, proc, id string, pid int) (int, error) {
+	c, err := e.InspectContainer(id)
+	if err != nil {
+		return 0, err
+	}
+	if c.ContainerJSONBase == nil {
+		return 0, fmt.Errorf("cannot inspect container %s", id)
+	}
+	if c.HostConfig != nil && c.HostConfig.PidMode.IsHost() {
+		return pid, nil
+	}
+
+	if proc != "" && c.State != nil && strings.HasPrefix(e.DaemonHost(), "unix://") {
+		if nspid, err := namespacePID(proc, pid, c.State.Pid); err == nil {
+			return nspid, nil
+		}
+	}
+	return matchContainerPID(ctx, e, id, pid)
+}
+
+// namespacePID read the PID in the container from proc, after checking the process is in the PID namespace of
+// the container whose main process is initPID. it fails when proc is not the one of the daemon host.
+func namespacePID(proc string, pid, initPID int) (int, error) {
+	link := func(p string) (string, error) {
+		return os.Readlink(filepath.Join(proc, p, "ns", "pid"))
+	}
+
+	self, err := link("self")
+	if err != nil {
+		return 0, err
+	}
+	initNS, err := link(strconv.Itoa(initPID))
+	if err != nil {
+		return 0, err
+	}
+	ns, err := link(strconv.Itoa(pid))
+	if err != nil {
+		return 0, err
+	}
+	if initNS == self {
+		return 0, fmt.Errorf("process %d is not the main process of a container on this host", initPID)
+	}
+	if ns != initNS {
+		return 0, fmt.Errorf("process %d is not in the container", pid)
+	}
+
+	if nspid, err := readNSPID(proc, initPID); err != nil || nspid != 1 {
+		return 0, fmt.Errorf("process %d is not the main process of a container on this host", initPID)
+	}
+	return readNSPID(proc, pid)
+}
+
+// readNSPID read the PID in the innermost PID namespace, the last one of NSpid in proc/<pid>/status
+func readNSPID(proc string, pid int) (int, error) {
+	b, err := ioutil.ReadFile(filepath.Join(proc, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		if !strings.HasPrefix(line, "NSpid:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "NSpid:"))
+		if len(fields) == 0 {
+			break
+		}
+		return strconv.Atoi(fields[len(fields)-1])
+	}
+	return 0, fmt.Errorf("no NSpid in the status of %d", pid)
+}
+
+// clockTicks ticks per second of start times in /proc/<pid>/stat
+const clockTicks = 100
+
+// listProcessesScript print the uptime, then the stat and the command line of each process.
+// newlines in them are replaced, so each process is always two lines.
+const listProcessesScript = `cat /proc/uptime
+for d in /proc/[0-9]*; do
+  s=$(tr '\n' ' ' < $d/stat 2>/dev/null) || continue
+  echo "$s"
+  tr '\0\n' '  ' < $d/cmdline 2>/dev/null; echo
+done`
+
+// process process to match between the host and the container
+type process struct {
+	pid     int
+	elapsed time.Duration
+	command string
+}
+
+// matchContainerPID find the process in the container which has the same command and start time as pid on the host.
+// it fails unless exactly one process matches.
+func matchContainerPID(ctx context.Context, e Engine, id string, pid int) (int, error) {
+	top, err := e.TopContainer(ctx, id, "-o pid,etimes,args")
+	if err != nil {
+		return 0, err
+	}
+	host, err := topProcess(top, pid)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := e.ExecCommand(ctx, id, ExecOptions{Cmd: []string{"sh", "-c", listProcessesScript}})
+	if err != nil {
+		return 0, fmt.Errorf("cannot list processes in the container, it needs sh: %s", err)
+	}
+	if result.ExitCode != 0 {
+		return 0, fmt.Errorf("cannot list processes in the container: %s", strings.TrimSpace(result.Stderr+result.Stdout))
+	}
+	processes, err := parseProcesses(result.Stdout)
+	if err != nil {
+		return 0, err
+	}
+	return matchProcess(host, processes)
+}
+
+// topProcess find the process of pid in the result of "ps -o pid,etimes,args"
+func topProcess(top container.ContainerTopOKBody, pid int) (process, error) {
+	column := func(names ...string) int {
+		for i, title := range top.Titles {
+			for _, name := range names {
+				if strings.EqualFold(title, name) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	pidColumn, elapsedColumn, commandColumn := column("PID"), column("ELAPSED"), column("COMMAND", "CMD")
+	if pidColumn < 0 || elapsedColumn < 0 || commandColumn < 0 {
+		return process{}, fmt.Errorf("unexpected ps columns %v", top.Titles)
+	}
+
+	for _, p := range top.Processes {
+		if len(p) <= pidColumn || len(p) <= elapsedColumn || len(p) <= commandColumn || p[pidColumn] != strconv.Itoa(pid) {
+			continue
+		}
+		elapsed, err := strconv.Atoi(p[elapsedColumn])
+		if err != nil {
+			return process{}, fmt.Errorf("invalid elapsed time %s", p[elapsedColumn])
+		}
+		return process{pid: pid, elapsed: time.Duration(elapsed) * time.Second, command: p[commandColumn]}, nil
+	}
+	return process{}, fmt.Errorf("no process %d in the container", pid)
+}
+
+// parseProcesses parse the output of listProcessesScript
+func parseProcesses(out string) ([]process, error) {
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+
+	var uptime float64
+	if fields := strings.Fields(lines[0]); len(fields) > 0 {
+		uptime, _ = strconv.ParseFloat(fields[0], 64)
+	}
+	if uptime <= 0 {
+		return nil, fmt.Errorf("cannot read uptime in the container")
+	}
+
+	var processes []process
+	for i := 1; i+1 < len(lines); i += 2 {
+		// pid (comm) state ppid ..., comm may contain spaces and parentheses
+		stat := lines[i]
+		begin, end := strings.Index(stat, " ("), strings.LastIndex(stat, ")")
+		if begin < 0 || end < begin {
+			continue
+		}
+		pid, err := strconv.Atoi(stat[:begin])
+		if err != nil {
+			continue
+		}
+		// starttime is the 22nd field, and the 20th after comm
+		fields := strings.Fields(stat[end+1:])
+		if len(fields) < 20 {
+			continue
+		}
+		ticks, err := strconv.ParseFloat(fields[19], 64)
+		if err != nil {
+			continue
+		}
+
+		command := strings.TrimSpace(lines[i+1])
+		if command == "" {
+			command = "[" + stat[begin+2:end] + "]"
+		}
+		elapsed := time.Duration((uptime - ticks/clockTicks) * float64(time.Second))
+		processes = append(processes, process{pid: pid, elapsed: elapsed, command: command})
+	}
+	return processes, nil
+}
+
+// matchProcess find the process in the container which has the same command and start time as host.
+// the processes are listed in the container a moment after ps on the host.
+func matchProcess(host process, processes []process) (int, error) {
+	command := strings.Join(strings.Fields(host.command), " ")
+
+	var matched []int
+	for _, p := range processes {
+		if strings.Join(strings.Fields(p.command), " ") != command {
+			continue
+		}
+		if d := p.elapsed - host.elapsed; d < -time.Second || d > 3*time.Second {
+			continue
+		}
+		matched = append(matched, p.pid)
+	}
+
+	switch len(matched) {
+	case 0:
+		return 0, fmt.Errorf("process %d is not found in the container", host.pid)
+	case 1:
+		return matched[0], nil
+	}
+	return 0, fmt.Errorf("process %d matches %d processes in the container", host.pid, len(matched))
+}
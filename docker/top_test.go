@@ -0,0 +1,173 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestKillCommand(t *testing.T) {
+	tests := []struct {
+		signal string
+		want   []string
+		err    bool
+	}{
+		{signal: "TERM", want: []string{"kill", "-TERM", "42"}},
+		{signal: "sigkill", want: []string{"kill", "-KILL", "42"}},
+		{signal: " 9 ", want: []string{"kill", "-9", "42"}},
+		{signal: "", err: true},
+		{signal: "TERM; rm -rf /", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := killCommand(42, tt.signal)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Expected error for %q. Got %v.", tt.signal, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", tt.signal, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %v. Got %v.", tt.want, got)
+		}
+	}
+
+	if _, err := killCommand(0, "TERM"); err == nil {
+		t.Error("Expected error for pid 0.")
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for signal, want := range map[string]string{"SIGHUP": "HUP", "usr1": "USR1", "15": "15"} {
+		if got, err := ParseSignal(signal); err != nil || got != want {
+			t.Errorf("Expected %s for %s. Got %s, %v.", want, signal, got, err)
+		}
+	}
+	for _, signal := range []string{"0", "-9", "SIG TERM"} {
+		if _, err := ParseSignal(signal); err == nil {
+			t.Errorf("Expected error for %q.", signal)
+		}
+	}
+}
+
+// procStat make a line of /proc/<pid>/stat with the start time
+func procStat(pid int, comm string, startSec float64) string {
+	return fmt.Sprintf("%d (%s) S 1 1 1 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 %d 0 0", pid, comm, int(startSec*clockTicks))
+}
+
+func TestContainerPID(t *testing.T) {
+	f := NewFake()
+	f.ContainerItems = []types.Container{{ID: "web", Names: []string{"/web"}, State: "running"}}
+	// ps on the host shows the PIDs on the host
+	f.Processes["web"] = container.ContainerTopOKBody{
+		Titles: []string{"PID", "ELAPSED", "COMMAND"},
+		Processes: [][]string{
+			{"4200", "300", "nginx: master process nginx -g daemon off;"},
+			{"4242", "120", "nginx: worker process"},
+			{"4243", "20", "nginx: worker process"},
+			{"4300", "5", "sleep 60"},
+		},
+	}
+	// the container started 1000 seconds after the boot
+	f.ExecResults["web"] = ExecResult{Stdout: strings.Join([]string{
+		"1300.50 2000.00",
+		procStat(1, "nginx", 1000), "nginx: master process nginx -g daemon off; ",
+		procStat(7, "nginx", 1180.2), "nginx: worker process ",
+		procStat(8, "nginx", 1280), "nginx: worker process ",
+		procStat(9, "my (odd) sleep", 1295.5), "sleep 60 ",
+		procStat(10, "sh", 1300.4), "sh -c ... ",
+	}, "\n") + "\n"}
+
+	for hostPID, want := range map[int]int{4200: 1, 4242: 7, 4243: 8, 4300: 9} {
+		got, err := f.ContainerPID(context.Background(), "web", hostPID)
+		if err != nil {
+			t.Errorf("Unexpected error for %d: %s", hostPID, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Expected %d for %d. Got %d.", want, hostPID, got)
+		}
+	}
+
+	if pid, err := f.ContainerPID(context.Background(), "web", 4999); err == nil {
+		t.Errorf("Expected error for the unknown process. Got %d.", pid)
+	}
+
+	// the worker restarted in the container, and ps on the host is old
+	f.Processes["web"].Processes[1][1] = "600"
+	if pid, err := f.ContainerPID(context.Background(), "web", 4242); err == nil {
+		t.Errorf("Expected error for the process started at other time. Got %d.", pid)
+	}
+
+	f.ContainerJSONs["web"] = types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+		ID:         "web",
+		HostConfig: &container.HostConfig{PidMode: "host"},
+	}}
+	if pid, err := f.ContainerPID(context.Background(), "web", 4242); err != nil || pid != 4242 {
+		t.Errorf("Expected the same pid with --pid=host. Got %d, %v.", pid, err)
+	}
+}
+
+func TestMatchProcessAmbiguous(t *testing.T) {
+	processes := []process{
+		{pid: 7, elapsed: 120 * time.Second, command: "worker"},
+		{pid: 8, elapsed: 120 * time.Second, command: "worker"},
+	}
+	if pid, err := matchProcess(process{pid: 4242, elapsed: 120 * time.Second, command: "worker"}, processes); err == nil {
+		t.Errorf("Expected error for the ambiguous process. Got %d.", pid)
+	}
+}
+
+func TestContainerPIDNamespace(t *testing.T) {
+	proc, err := ioutil.TempDir("", "docui-proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(proc)
+
+	// docui and 4300 are in the host namespace, 5000 is the main process of the container
+	for pid, ns := range map[string]string{"self": "pid:[4026531836]", "5000": "pid:[4026532301]", "4242": "pid:[4026532301]", "4300": "pid:[4026531836]"} {
+		if err := os.MkdirAll(filepath.Join(proc, pid, "ns"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(ns, filepath.Join(proc, pid, "ns", "pid")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for pid, nspid := range map[string]string{"5000": "5000\t1", "4242": "4242\t7", "4300": "4300"} {
+		status := "Name:\tnginx\nTgid:\t" + pid + "\nNSpid:\t" + nspid + "\n"
+		if err := ioutil.WriteFile(filepath.Join(proc, pid, "status"), []byte(status), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := NewFake()
+	f.ProcDir = proc
+	f.ContainerItems = []types.Container{{ID: "web", Names: []string{"/web"}, State: "running"}}
+	f.ContainerJSONs["web"] = types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+		ID:         "web",
+		State:      &types.ContainerState{Running: true, Pid: 5000},
+		HostConfig: &container.HostConfig{},
+	}}
+
+	if pid, err := f.ContainerPID(context.Background(), "web", 4242); err != nil || pid != 7 {
+		t.Errorf("Expected 7. Got %d, %v.", pid, err)
+	}
+	// the process is not in the container, and it is not found by exec either
+	if pid, err := f.ContainerPID(context.Background(), "web", 4300); err == nil {
+		t.Errorf("Expected error for the process out of the container. Got %d.", pid)
+	}
+}
@@ -44,6 +44,10 @@ func (d *Docker) CreateVolume(opt volumetypes.VolumeCreateBody) error {
 
 // NewCreateVolumeOptions generate options to create volume
 func (d *Docker) NewCreateVolumeOptions(data map[string]string) volumetypes.VolumeCreateBody {
+	return newCreateVolumeOptions(data)
+}
+
+func newCreateVolumeOptions(data map[string]string) volumetypes.VolumeCreateBody {
 	driverOpts := make(map[string]string)
 	labels := make(map[string]string)
 
@@ -0,0 +1,237 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/skanehira/docui/common"
+)
+
+var (
+	// VolumeHelperImage image of the short-lived container which mounts the volume
+	VolumeHelperImage = "busybox:latest"
+	// VolumeHelperMount mount point of the volume in the helper container
+	VolumeHelperMount = "/volume"
+	// volumeHelperLabel label to find helper containers left behind, they are removed with RemoveVolumeHelpers
+	volumeHelperLabel = "docui.volume-helper"
+)
+
+// CreateVolumeHelper create the container which mounts the volume at VolumeHelperMount.
+// the container is not started, files are copied with CopyFromContainer and CopyToContainer.
+// when it is started, it sleeps to run commands with exec until it is killed.
+// the caller must remove it with RemoveContainer.
+func (d *Docker) CreateVolumeHelper(ctx context.Context, volume string, readOnly bool) (string, error) {
+	if err := d.ensureImage(ctx, VolumeHelperImage); err != nil {
+		return "", err
+	}
+
+	resp, err := d.ContainerCreate(ctx,
+		&container.Config{
+			Image:  VolumeHelperImage,
+			Cmd:    []string{"sleep", "2147483647"},
+			Labels: map[string]string{volumeHelperLabel: volume},
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{
+				{Type: mount.TypeVolume, Source: volume, Target: VolumeHelperMount, ReadOnly: readOnly},
+			},
+		}, nil, "")
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// RemoveVolumeHelpers remove all helper containers, including ones left behind when docui exited while using them
+func (d *Docker) RemoveVolumeHelpers(ctx context.Context) error {
+	containers, err := d.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", volumeHelperLabel)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		if err := d.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureImage pull the image when it does not exist
+func (d *Docker) ensureImage(ctx context.Context, image string) error {
+	_, _, err := d.ImageInspectWithRaw(ctx, image)
+	if err == nil || !client.IsErrNotFound(err) {
+		return err
+	}
+
+	out, err := d.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(ioutil.Discard, out)
+	return err
+}
+
+// BackupVolume write files of the volume to the tar file on the host, and gzip it when compress is true.
+// progress is called with the copied bytes.
+func (d *Docker) BackupVolume(ctx context.Context, volume, file string, compress bool, progress func(int64)) error {
+	helper, err := d.CreateVolumeHelper(ctx, volume, true)
+	if err != nil {
+		return err
+	}
+	defer d.removeVolumeHelper(helper)
+
+	reader, _, err := d.CopyFromContainer(ctx, helper, VolumeHelperMount)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := writeBackupFile(file, reader, compress, progress); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// RestoreVolume extract the tar file on the host into the volume, the volume is created when it does not exist.
+// gzip compressed file is also accepted. progress is called with the copied bytes.
+func (d *Docker) RestoreVolume(ctx context.Context, volume, file string, progress func(int64)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader, err := backupReader(f, progress)
+	if err != nil {
+		return err
+	}
+
+	helper, err := d.CreateVolumeHelper(ctx, volume, false)
+	if err != nil {
+		return err
+	}
+	defer d.removeVolumeHelper(helper)
+
+	err = d.CopyToContainer(ctx, helper, VolumeHelperMount, reader, types.CopyToContainerOptions{})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// removeVolumeHelper remove the helper container, the context may be already canceled
+func (d *Docker) removeVolumeHelper(id string) {
+	if err := d.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{Force: true}); err != nil {
+		common.Logger.Errorf("cannot remove volume helper container %s", err)
+	}
+}
+
+// writeBackupFile write the archive of the volume directory to the new file.
+// names in the archive are rebased to "./", so it can be restored into any volume.
+func writeBackupFile(file string, r io.Reader, compress bool, progress func(int64)) (err error) {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		// do not leave the broken backup
+		if err != nil {
+			os.Remove(file)
+		}
+	}()
+
+	counter := newProgressCounter(progress)
+	var w io.Writer = io.MultiWriter(f, counter)
+	var gw *gzip.Writer
+	if compress {
+		gw = gzip.NewWriter(w)
+		w = gw
+	}
+
+	if err := rebaseTar(w, r); err != nil {
+		return err
+	}
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return err
+		}
+	}
+
+	counter.done()
+	return nil
+}
+
+// rebaseTar copy the archive of the directory, and replace the top directory with "."
+func rebaseTar(w io.Writer, r io.Reader) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if i := strings.Index(name, "/"); i >= 0 {
+			hdr.Name = "./" + name[i+1:]
+			if hdr.Typeflag == tar.TypeDir {
+				hdr.Name += "/"
+			}
+		} else {
+			hdr.Name = "./"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// backupReader return the tar stream of the backup file, gzip compressed file is decompressed.
+// progress is called with the read bytes of the file.
+func backupReader(r io.Reader, progress func(int64)) (io.Reader, error) {
+	counter := newProgressCounter(progress)
+	br := bufio.NewReader(io.TeeReader(r, counter))
+
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
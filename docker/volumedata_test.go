@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestRebaseTar(t *testing.T) {
+	var src bytes.Buffer
+	tw := tar.NewWriter(&src)
+	tw.WriteHeader(&tar.Header{Name: "volume/", Mode: 0755, Uid: 999, Typeflag: tar.TypeDir})
+	tw.WriteHeader(&tar.Header{Name: "volume/conf/", Mode: 0700, Uid: 999, Typeflag: tar.TypeDir})
+	tw.WriteHeader(&tar.Header{Name: "volume/conf/app.ini", Mode: 0600, Uid: 999, Size: 3, Typeflag: tar.TypeReg})
+	tw.Write([]byte("a=1"))
+	tw.Close()
+
+	var dst bytes.Buffer
+	if err := rebaseTar(&dst, &src); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	tr := tar.NewReader(&dst)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Uid != 999 {
+			t.Errorf("Expected the owner is kept. Got %d.", hdr.Uid)
+		}
+		names = append(names, hdr.Name)
+	}
+
+	expect := []string{"./", "./conf/", "./conf/app.ini"}
+	if !reflect.DeepEqual(names, expect) {
+		t.Errorf("Expected %v. Got %v.", expect, names)
+	}
+}
+
+func TestBackupRestoreVolume(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docui-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, compress := range []bool{false, true} {
+		f := NewFake()
+		f.VolumeItems = []*types.Volume{{Name: "data", Driver: "local"}}
+		f.VolumeFiles["data"] = map[string]string{"/conf/app.ini": "a=1", "/db": "rows"}
+
+		file := filepath.Join(tmp, "data.tar")
+		if compress {
+			file += ".gz"
+		}
+
+		var copied int64
+		if err := f.BackupVolume(context.Background(), "data", file, compress, func(n int64) { copied = n }); err != nil {
+			t.Fatalf("Expected no error. Got %s.", err)
+		}
+		if copied == 0 {
+			t.Error("Expected the progress is reported.")
+		}
+
+		// the backup is not overwritten
+		if err := f.BackupVolume(context.Background(), "data", file, compress, nil); err == nil {
+			t.Error("Expected error for existing file.")
+		}
+
+		if err := f.RestoreVolume(context.Background(), "restored", file, nil); err != nil {
+			t.Fatalf("Expected no error. Got %s.", err)
+		}
+		if !reflect.DeepEqual(f.VolumeFiles["restored"], f.VolumeFiles["data"]) {
+			t.Errorf("Expected %v. Got %v.", f.VolumeFiles["data"], f.VolumeFiles["restored"])
+		}
+		if f.volumeIndex("restored") < 0 {
+			t.Error("Expected the volume is created.")
+		}
+	}
+}
+
+func TestFakeRemoveVolumeHelpers(t *testing.T) {
+	f := NewFake()
+	f.ContainerItems = []types.Container{{ID: "web", Names: []string{"/web"}, State: "running"}}
+	if _, err := f.CreateVolumeHelper(context.Background(), "data", true); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	if err := f.RemoveVolumeHelpers(context.Background()); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if len(f.ContainerItems) != 1 || f.ContainerItems[0].ID != "web" {
+		t.Errorf("Expected only the helper is removed. Got %+v.", f.ContainerItems)
+	}
+}
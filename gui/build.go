@@ -0,0 +1,139 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// parseKeyValues parse space separated key=value.
+// when value is omitted, the environment variable is used like docker build.
+func parseKeyValues(s string) map[string]string {
+	values := make(map[string]string)
+	for _, kv := range strings.Fields(s) {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) == 2 {
+			values[pair[0]] = pair[1]
+		} else if v, ok := os.LookupEnv(pair[0]); ok {
+			values[pair[0]] = v
+		}
+	}
+	return values
+}
+
+// buildStep return the step of the build output line, e.g. "Step 2/5"
+func buildStep(line string) string {
+	if !strings.HasPrefix(line, "Step ") {
+		return ""
+	}
+	return strings.SplitN(line, " :", 2)[0]
+}
+
+func (g *Gui) buildImageForm() {
+	wd, _ := os.Getwd()
+
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle("Build image")
+	form.SetTitleAlign(tview.AlignLeft)
+
+	form.AddInputField("Context dir", wd, inputWidth, nil, nil).
+		AddInputField("Dockerfile", "Dockerfile", inputWidth, nil, nil).
+		AddInputField("Tags", "", inputWidth, nil, nil).
+		AddInputField("Build args", "", inputWidth, nil, nil).
+		AddInputField("Target", "", inputWidth, nil, nil).
+		AddCheckbox("No cache", false, nil).
+		AddInputField("Labels", "", inputWidth, nil, nil).
+		AddButton("Build", func() {
+			text := func(label string) string {
+				return form.GetFormItemByLabel(label).(*tview.InputField).GetText()
+			}
+
+			opt := docker.BuildOptions{
+				ContextDir: text("Context dir"),
+				Dockerfile: text("Dockerfile"),
+				Tags:       strings.Fields(text("Tags")),
+				BuildArgs:  parseKeyValues(text("Build args")),
+				Target:     text("Target"),
+				NoCache:    form.GetFormItemByLabel("No cache").(*tview.Checkbox).IsChecked(),
+				Labels:     parseKeyValues(text("Labels")),
+			}
+
+			g.closeAndSwitchPanel("form", "images")
+			g.buildImage(opt)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "images")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 19), true).ShowPage("main")
+}
+
+// buildImage run the build as a task, and show the output
+func (g *Gui) buildImage(opt docker.BuildOptions) {
+	viewName := "build"
+	name := strings.Join(opt.Tags, " ")
+	if name == "" {
+		name = opt.ContextDir
+	}
+
+	text := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	text.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	text.SetTitle(tview.Escape(fmt.Sprintf(" build %s (running) ", name)))
+	text.SetChangedFunc(func() {
+		g.app.Draw()
+	})
+	// follow the output until it is scrolled up
+	text.ScrollToEnd()
+
+	// output is written from the task goroutine, TextView is safe for concurrent writes
+	output := func(line string) {
+		fmt.Fprintln(text, tview.Escape(line))
+	}
+
+	task := g.startProgressTask("build image "+name, func(ctx context.Context, progress func(string)) error {
+		err := g.docker.BuildImage(ctx, opt, func(line string) {
+			output(line)
+			if step := buildStep(line); step != "" {
+				progress(step)
+			}
+		})
+
+		title := fmt.Sprintf(" build %s (done) ", name)
+		switch {
+		case ctx.Err() != nil:
+			title = fmt.Sprintf(" build %s (canceled) ", name)
+			fmt.Fprintln(text, "[yellow]build canceled[-]")
+		case err != nil:
+			common.Logger.Errorf("cannot build image %s", err)
+			title = fmt.Sprintf(" build %s (failed) ", name)
+			fmt.Fprintln(text, "[red]"+tview.Escape(err.Error())+"[-]")
+		}
+
+		g.app.QueueUpdateDraw(func() {
+			text.SetTitle(tview.Escape(title))
+		})
+		return err
+	})
+
+	text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			// the build keeps running in the tasks panel
+			g.closeAndSwitchPanel(viewName, "images")
+			return nil
+		case event.Rune() == 'c':
+			task.Cancel()
+			return nil
+		}
+		return event
+	})
+
+	g.pages.AddAndSwitchToPage(viewName, text, true)
+}
@@ -0,0 +1,31 @@
+package gui
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyValues(t *testing.T) {
+	os.Setenv("DOCUI_TEST_PROXY", "http://proxy")
+	defer os.Unsetenv("DOCUI_TEST_PROXY")
+
+	got := parseKeyValues("VERSION=1.0 EMPTY= DOCUI_TEST_PROXY DOCUI_TEST_UNSET")
+	want := map[string]string{
+		"VERSION":          "1.0",
+		"EMPTY":            "",
+		"DOCUI_TEST_PROXY": "http://proxy",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v. Got %v.", want, got)
+	}
+}
+
+func TestBuildStep(t *testing.T) {
+	if got := buildStep("Step 2/5 : RUN make"); got != "Step 2/5" {
+		t.Errorf("Expected Step 2/5. Got %s.", got)
+	}
+	if got := buildStep(" ---> Running in 3f2a"); got != "" {
+		t.Errorf("Expected empty. Got %s.", got)
+	}
+}
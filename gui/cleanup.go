@@ -0,0 +1,144 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// SetCleanupPolicy set the default policy file of the cleanup form
+func (g *Gui) SetCleanupPolicy(file string) {
+	g.state.policyFile = file
+}
+
+// cleanupPolicyForm input the policy file, and show resources matched by the policy
+func (v *diskUsageViewer) cleanupPolicyForm(g *Gui) {
+	viewName := "cleanupPolicy"
+	title := "Cleanup by policy"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+	form.AddInputField("Policy file", g.state.policyFile, inputWidth, nil, nil).
+		AddButton("Preview", func() {
+			file := strings.TrimSpace(form.GetFormItemByLabel("Policy file").(*tview.InputField).GetText())
+			items, err := evaluateCleanupPolicy(g.docker, file)
+			if err == nil && len(items) == 0 {
+				err = fmt.Errorf("nothing matches the policy")
+			}
+			if err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.state.policyFile = file
+			g.pages.RemovePage(viewName)
+			v.previewCleanup(g, items)
+		}).
+		AddButton("Cancel", func() {
+			v.closePopup(g, viewName)
+		})
+
+	v.showPopup(g, viewName, form, 80, 7)
+}
+
+// evaluateCleanupPolicy load the policy and list resources to be removed
+func evaluateCleanupPolicy(engine docker.Engine, file string) ([]docker.CleanupItem, error) {
+	if file == "" {
+		return nil, fmt.Errorf("policy file is required")
+	}
+
+	policy, err := docker.LoadCleanupPolicy(file)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := docker.ListCleanupResources(engine)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Evaluate(resources, time.Now())
+}
+
+// previewCleanup show the resources matched by the policy, and remove them when it is accepted
+func (v *diskUsageViewer) previewCleanup(g *Gui, items []docker.CleanupItem) {
+	viewName := "cleanupPreview"
+
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	for i, header := range []string{"Resource", "Name", "Created", "Rule"} {
+		table.SetCell(0, i, &tview.TableCell{
+			Text:            header,
+			NotSelectable:   true,
+			Align:           tview.AlignLeft,
+			Color:           tcell.ColorWhite,
+			BackgroundColor: tcell.ColorDefault,
+			Attributes:      tcell.AttrBold,
+		})
+	}
+	for i, item := range items {
+		created := ""
+		if !item.Created.IsZero() {
+			created = item.Created.Format("2006/01/02 15:04:05")
+		}
+		cells := []string{item.Resource, item.Name, created, item.Rule}
+		for j, cell := range cells {
+			table.SetCell(i+1, j, tview.NewTableCell(cell).
+				SetTextColor(tcell.ColorLightPink).
+				SetMaxWidth(1).
+				SetExpansion(1))
+		}
+	}
+	table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	table.SetTitle(fmt.Sprintf(" %d resources match the policy (dry run) ", len(items)))
+
+	status := tview.NewTextView().SetText(" p: remove them, q: cancel")
+	preview := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(status, 1, 0, false)
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			v.closePopup(g, viewName)
+		case event.Rune() == 'p':
+			v.closePopup(g, viewName)
+			v.cleanup(g, items)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	g.pages.AddAndSwitchToPage(viewName, preview, true)
+}
+
+func (v *diskUsageViewer) cleanup(g *Gui, items []docker.CleanupItem) {
+	g.startProgressTask("cleanup by policy", func(ctx context.Context, progress func(string)) error {
+		failed := 0
+		for i, item := range items {
+			if ctx.Err() != nil {
+				break
+			}
+			progress(fmt.Sprintf("%d/%d", i+1, len(items)))
+			if err := docker.RemoveCleanupItem(g.docker, item); err != nil {
+				common.Logger.Errorf("cannot remove %s %s %s", item.Resource, item.Name, err)
+				failed++
+			}
+		}
+
+		g.imagePanel().updateEntries(g)
+		g.containerPanel().updateEntries(g)
+		g.volumePanel().updateEntries(g)
+		v.refresh(g)
+
+		if failed > 0 {
+			return fmt.Errorf("cannot remove %d of %d resources", failed, len(items))
+		}
+		return nil
+	})
+}
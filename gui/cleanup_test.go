@@ -0,0 +1,46 @@
+package gui
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestEvaluateCleanupPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "cleanup.json")
+	if err := ioutil.WriteFile(file, []byte(`{"rules": [{"resource": "container", "status": ["exited"]}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := newTestFake()
+	items, err := evaluateCleanupPolicy(fake, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "db" {
+		t.Errorf("Expected the exited db container. Got %+v.", items)
+	}
+
+	if _, err := evaluateCleanupPolicy(fake, ""); err == nil {
+		t.Error("Expected error without the policy file.")
+	}
+
+	g := newTestGui(t, fake)
+	v := &diskUsageViewer{table: tview.NewTable()}
+	v.previewCleanup(g, items)
+	if !g.pages.HasPage("cleanupPreview") {
+		t.Error("Expected the preview of the policy.")
+	}
+	if calls := fake.CallsOf("RemoveContainer"); len(calls) != 0 {
+		t.Errorf("Expected nothing is removed before it is accepted. Got %v.", calls)
+	}
+}
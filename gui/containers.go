@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -8,7 +9,6 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/skanehira/docui/common"
-	"github.com/skanehira/docui/docker"
 )
 
 type container struct {
@@ -16,18 +16,23 @@ type container struct {
 	Name    string
 	Image   string
 	Status  string
+	State   string
 	Created string
 	Port    string
+	Running bool
 }
 
 type containers struct {
 	*tview.Table
 	filterWord string
+	// marked multi-selected containers, key is container id
+	marked map[string]bool
 }
 
 func newContainers(g *Gui) *containers {
 	containers := &containers{
-		Table: tview.NewTable().SetSelectable(true, false).Select(0, 0).SetFixed(1, 1),
+		Table:  tview.NewTable().SetSelectable(true, false).Select(0, 0).SetFixed(1, 1),
+		marked: make(map[string]bool),
 	}
 
 	containers.SetTitle("container list").SetTitleAlign(tview.AlignLeft)
@@ -50,9 +55,9 @@ func (c *containers) setKeybinding(g *Gui) {
 		case tcell.KeyCtrlE:
 			g.attachContainerForm()
 		case tcell.KeyCtrlL:
-			g.tailContainerLog()
+			g.showContainerLogs()
 		case tcell.KeyCtrlK:
-			g.killContainer()
+			g.killContainerForm()
 		case tcell.KeyCtrlR:
 			c.setEntries(g)
 		}
@@ -65,11 +70,39 @@ func (c *containers) setKeybinding(g *Gui) {
 		case 'u':
 			g.startContainer()
 		case 's':
-			g.stopContainer()
+			g.stopContainerForm()
+		case 'R':
+			g.restartContainerForm()
+		case 'p':
+			g.pauseContainer()
+		case 'P':
+			g.unpauseContainer()
 		case 'e':
 			g.exportContainerForm()
 		case 'c':
 			g.commitContainerForm()
+		case 'L':
+			g.mergedLogsForm()
+		case 'S':
+			g.showContainerStats()
+		case 't':
+			g.showContainerProcesses()
+		case 'b':
+			g.showContainerFiles()
+		case 'D':
+			g.showContainerDiff()
+		case 'g':
+			g.showContainerGenerate()
+		case 'E':
+			g.recreateContainerForm()
+		case 'a':
+			g.attachMainProcessForm()
+		case 'T':
+			g.showTerminal()
+		case 'x':
+			g.execCommandForm()
+		case ' ':
+			c.toggleMark(g)
 		}
 
 		return event
@@ -77,14 +110,20 @@ func (c *containers) setKeybinding(g *Gui) {
 }
 
 func (c *containers) entries(g *Gui) {
-	containers, err := docker.Client.Containers(types.ContainerListOptions{All: true})
+	containers, err := g.docker.Containers(types.ContainerListOptions{All: true})
 	if err != nil {
 		return
 	}
 
 	g.state.resources.containers = make([]*container, 0)
+	exists := make(map[string]bool)
+	var running []string
 
 	for _, con := range containers {
+		if con.State == "running" {
+			running = append(running, con.ID[:12])
+		}
+
 		if strings.Index(con.Names[0][1:], c.filterWord) == -1 {
 			continue
 		}
@@ -94,10 +133,22 @@ func (c *containers) entries(g *Gui) {
 			Image:   con.Image,
 			Name:    con.Names[0][1:],
 			Status:  con.Status,
+			State:   con.State,
 			Created: common.ParseDateToString(con.Created),
 			Port:    common.ParsePortToString(con.Ports),
+			Running: con.State == "running",
 		})
+		exists[con.ID[:12]] = true
 	}
+
+	// forget marks of removed containers
+	for id := range c.marked {
+		if !exists[id] {
+			delete(c.marked, id)
+		}
+	}
+
+	g.state.stats.sync(g, running)
 }
 
 func (c *containers) setEntries(g *Gui) {
@@ -111,6 +162,10 @@ func (c *containers) setEntries(g *Gui) {
 		"Status",
 		"Created",
 		"Port",
+		"CPU",
+		"Mem",
+		"Net I/O",
+		"Block I/O",
 	}
 
 	for i, header := range headers {
@@ -125,7 +180,12 @@ func (c *containers) setEntries(g *Gui) {
 	}
 
 	for i, container := range g.state.resources.containers {
-		table.SetCell(i+1, 0, tview.NewTableCell(container.ID).
+		id := container.ID
+		if c.marked[container.ID] {
+			id = "*" + id
+		}
+
+		table.SetCell(i+1, 0, tview.NewTableCell(id).
 			SetTextColor(tcell.ColorLightGreen).
 			SetMaxWidth(1).
 			SetExpansion(1))
@@ -141,7 +201,7 @@ func (c *containers) setEntries(g *Gui) {
 			SetExpansion(1))
 
 		table.SetCell(i+1, 3, tview.NewTableCell(container.Status).
-			SetTextColor(tcell.ColorLightGreen).
+			SetTextColor(containerStateColor(container.State)).
 			SetMaxWidth(1).
 			SetExpansion(1))
 
@@ -155,6 +215,48 @@ func (c *containers) setEntries(g *Gui) {
 			SetMaxWidth(1).
 			SetExpansion(1))
 	}
+
+	c.setStats(g)
+}
+
+// setStats update resource usage columns of running containers
+func (c *containers) setStats(g *Gui) {
+	for i, container := range g.state.resources.containers {
+		cells := make([]string, 4)
+		if s := g.state.stats.get(container.ID); container.Running && s != nil {
+			cells[0] = fmt.Sprintf("%.2f%%", s.latest.CPUPercent)
+			cells[1] = common.ParseBytesToString(s.latest.MemoryUsage) + " / " + common.ParseBytesToString(s.latest.MemoryLimit)
+			cells[2] = common.ParseBytesToString(s.latest.NetworkRx) + " / " + common.ParseBytesToString(s.latest.NetworkTx)
+			cells[3] = common.ParseBytesToString(s.latest.BlockRead) + " / " + common.ParseBytesToString(s.latest.BlockWrite)
+		}
+
+		for j, text := range cells {
+			c.SetCell(i+1, 6+j, tview.NewTableCell(text).
+				SetTextColor(tcell.ColorLightGreen).
+				SetMaxWidth(1).
+				SetExpansion(1))
+		}
+	}
+}
+
+// toggleMark mark or unmark the selected container, and move to the next row
+func (c *containers) toggleMark(g *Gui) {
+	container := g.selectedContainer()
+	if container == nil {
+		return
+	}
+
+	if c.marked[container.ID] {
+		delete(c.marked, container.ID)
+	} else {
+		c.marked[container.ID] = true
+	}
+
+	row, _ := c.GetSelection()
+	c.setEntries(g)
+	if row < c.GetRowCount()-1 {
+		c.Select(row+1, 0)
+	}
 }
 
 func (c *containers) focus(g *Gui) {
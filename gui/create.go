@@ -0,0 +1,370 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// containerValues input values of the container form
+type containerValues struct {
+	name        string
+	image       string
+	user        string
+	cmd         string
+	entrypoint  string
+	workingDir  string
+	restart     string
+	network     string
+	aliases     string
+	memory      string
+	cpus        string
+	capAdd      string
+	capDrop     string
+	privileged  bool
+	tty         bool
+	interactive bool
+	autoRemove  bool
+	// repeatable rows, empty rows are ignored
+	ports  []string
+	mounts []string
+	env    []string
+	labels []string
+}
+
+// newContainerValues convert spec to form values
+func newContainerValues(spec docker.ContainerSpec) containerValues {
+	v := containerValues{
+		name:        spec.Name,
+		image:       spec.Image,
+		user:        spec.User,
+		cmd:         common.JoinShellWords(spec.Cmd),
+		entrypoint:  common.JoinShellWords(spec.Entrypoint),
+		workingDir:  spec.WorkingDir,
+		restart:     spec.RestartPolicy,
+		network:     spec.Network,
+		aliases:     strings.Join(spec.Aliases, " "),
+		capAdd:      strings.Join(spec.CapAdd, " "),
+		capDrop:     strings.Join(spec.CapDrop, " "),
+		privileged:  spec.Privileged,
+		tty:         spec.Tty,
+		interactive: spec.OpenStdin,
+		autoRemove:  spec.AutoRemove,
+		env:         append([]string{}, spec.Env...),
+	}
+
+	if spec.Memory > 0 {
+		v.memory = docker.FormatMemory(spec.Memory)
+	}
+	if spec.CPUs > 0 {
+		v.cpus = strconv.FormatFloat(spec.CPUs, 'f', -1, 64)
+	}
+
+	for _, p := range spec.Ports {
+		v.ports = append(v.ports, p.String())
+	}
+	for _, m := range spec.Mounts {
+		v.mounts = append(v.mounts, m.String())
+	}
+
+	var keys []string
+	for k := range spec.Labels {
+		keys = append(keys, k)
+	}
+	for _, k := range common.SortKeys(keys) {
+		v.labels = append(v.labels, k+"="+spec.Labels[k])
+	}
+
+	return v
+}
+
+// spec parse form values to spec
+func (v containerValues) spec() (docker.ContainerSpec, error) {
+	spec := docker.ContainerSpec{
+		Name:          strings.TrimSpace(v.name),
+		Image:         strings.TrimSpace(v.image),
+		User:          strings.TrimSpace(v.user),
+		WorkingDir:    strings.TrimSpace(v.workingDir),
+		RestartPolicy: strings.TrimSpace(v.restart),
+		Network:       strings.TrimSpace(v.network),
+		Aliases:       strings.Fields(v.aliases),
+		CapAdd:        strings.Fields(v.capAdd),
+		CapDrop:       strings.Fields(v.capDrop),
+		Privileged:    v.privileged,
+		Tty:           v.tty,
+		OpenStdin:     v.interactive,
+		AutoRemove:    v.autoRemove,
+	}
+
+	if spec.Image == "" {
+		return spec, fmt.Errorf("image is required")
+	}
+
+	var err error
+	if spec.Cmd, err = common.SplitShellWords(v.cmd); err != nil {
+		return spec, fmt.Errorf("invalid cmd: %s", err)
+	}
+	if spec.Entrypoint, err = common.SplitShellWords(v.entrypoint); err != nil {
+		return spec, fmt.Errorf("invalid entrypoint: %s", err)
+	}
+	if _, err := docker.ParseRestartPolicy(spec.RestartPolicy); err != nil {
+		return spec, err
+	}
+	if spec.Memory, err = docker.ParseMemory(strings.TrimSpace(v.memory)); err != nil {
+		return spec, fmt.Errorf("invalid memory %s", v.memory)
+	}
+	if cpus := strings.TrimSpace(v.cpus); cpus != "" {
+		if spec.CPUs, err = strconv.ParseFloat(cpus, 64); err != nil || spec.CPUs < 0 {
+			return spec, fmt.Errorf("invalid cpus %s", cpus)
+		}
+	}
+
+	for _, row := range nonEmptyRows(v.ports) {
+		p, err := docker.ParsePort(row)
+		if err != nil {
+			return spec, err
+		}
+		spec.Ports = append(spec.Ports, p)
+	}
+
+	for _, row := range nonEmptyRows(v.mounts) {
+		m, err := parseMountRow(row)
+		if err != nil {
+			return spec, err
+		}
+		spec.Mounts = append(spec.Mounts, m)
+	}
+
+	for _, row := range nonEmptyRows(v.env) {
+		if strings.HasPrefix(row, "=") {
+			return spec, fmt.Errorf("invalid env %s", row)
+		}
+		spec.Env = append(spec.Env, row)
+	}
+
+	for _, row := range nonEmptyRows(v.labels) {
+		key, value, err := docker.ParseLabel(row)
+		if err != nil {
+			return spec, err
+		}
+		if spec.Labels == nil {
+			spec.Labels = make(map[string]string)
+		}
+		spec.Labels[key] = value
+	}
+
+	return spec, nil
+}
+
+// parseMountRow parse mount like docker run --mount or -v
+func parseMountRow(row string) (docker.MountSpec, error) {
+	if strings.Contains(row, "type=") || strings.Contains(row, "target=") || strings.Contains(row, "dst=") {
+		return docker.ParseMount(row)
+	}
+	return docker.ParseVolume(row)
+}
+
+func nonEmptyRows(rows []string) []string {
+	var result []string
+	for _, row := range rows {
+		if row = strings.TrimSpace(row); row != "" {
+			result = append(result, row)
+		}
+	}
+	return result
+}
+
+// containerForm form to edit container spec with repeatable rows
+type containerForm struct {
+	*tview.Form
+	g      *Gui
+	title  string
+	submit string
+	values containerValues
+	// onSubmit called with the parsed spec
+	onSubmit func(spec docker.ContainerSpec)
+	onCancel func()
+}
+
+func newContainerForm(g *Gui, title, submit string, spec docker.ContainerSpec, onSubmit func(docker.ContainerSpec), onCancel func()) *containerForm {
+	f := &containerForm{
+		Form:     tview.NewForm(),
+		g:        g,
+		title:    title,
+		submit:   submit,
+		values:   newContainerValues(spec),
+		onSubmit: onSubmit,
+		onCancel: onCancel,
+	}
+	f.SetBorder(true)
+	f.SetTitle(title)
+	f.SetTitleAlign(tview.AlignLeft)
+
+	f.render()
+	return f
+}
+
+// render rebuild the form from the values
+func (f *containerForm) render() {
+	f.Clear(true)
+
+	input := func(label string, value *string) {
+		f.AddInputField(label, *value, inputWidth, nil, func(text string) {
+			*value = text
+		})
+	}
+	rows := func(label string, values []string) {
+		for i := range values {
+			input(fmt.Sprintf("%s %d", label, i+1), &values[i])
+		}
+	}
+	checkbox := func(label string, value *bool) {
+		f.AddCheckbox(label, *value, func(checked bool) {
+			*value = checked
+		})
+	}
+
+	v := &f.values
+	input("Name", &v.name)
+	input("Image", &v.image)
+	input("Cmd", &v.cmd)
+	input("Entrypoint", &v.entrypoint)
+	input("User", &v.user)
+	input("Working dir", &v.workingDir)
+	rows("Port", v.ports)
+	rows("Mount", v.mounts)
+	rows("Env", v.env)
+	rows("Label", v.labels)
+	input("Restart", &v.restart)
+	input("Network", &v.network)
+	input("Aliases", &v.aliases)
+	input("Memory", &v.memory)
+	input("CPUs", &v.cpus)
+	input("Cap add", &v.capAdd)
+	input("Cap drop", &v.capDrop)
+	checkbox("Privileged", &v.privileged)
+	checkbox("TTY", &v.tty)
+	checkbox("Interactive", &v.interactive)
+	checkbox("Auto remove", &v.autoRemove)
+
+	f.AddButton("Add port", func() { f.addRow("Port", &v.ports) }).
+		AddButton("Add mount", func() { f.addRow("Mount", &v.mounts) }).
+		AddButton("Add env", func() { f.addRow("Env", &v.env) }).
+		AddButton("Add label", func() { f.addRow("Label", &v.labels) }).
+		AddButton(f.submit, func() {
+			spec, err := f.values.spec()
+			if err != nil {
+				f.SetTitle(f.title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+			f.onSubmit(spec)
+		}).
+		AddButton("Cancel", f.onCancel)
+}
+
+// addRow add an empty row and focus it
+func (f *containerForm) addRow(label string, rows *[]string) {
+	*rows = append(*rows, "")
+	f.render()
+	f.SetFocus(f.GetFormItemIndex(fmt.Sprintf("%s %d", label, len(*rows))))
+	f.g.app.SetFocus(f)
+}
+
+func (g *Gui) createContainerForm() {
+	selectedImage := g.selectedImage()
+	if selectedImage == nil {
+		common.Logger.Error("please input image")
+		return
+	}
+
+	spec := docker.ContainerSpec{
+		Image: fmt.Sprintf("%s:%s", selectedImage.Repo, selectedImage.Tag),
+	}
+
+	form := newContainerForm(g, "Create container", "Create", spec, func(spec docker.ContainerSpec) {
+		// values like $HOME are read from the environment of docui
+		for i, env := range spec.Env {
+			spec.Env[i] = common.GetOSenv(env)
+		}
+		g.closeAndSwitchPanel("form", "images")
+		g.createContainer(spec, false)
+	}, func() {
+		g.closeAndSwitchPanel("form", "images")
+	})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 29), true).ShowPage("main")
+}
+
+// createContainer create the container, and start it if start is true
+func (g *Gui) createContainer(spec docker.ContainerSpec, start bool) {
+	g.startTask("create container "+spec.Image, func(ctx context.Context) error {
+		options, err := g.docker.NewContainerOptions(spec)
+		if err != nil {
+			common.Logger.Errorf("cannot create container %s", err)
+			return err
+		}
+
+		id, err := g.docker.CreateContainer(options)
+		if err != nil {
+			common.Logger.Errorf("cannot create container %s", err)
+			return err
+		}
+
+		if start {
+			if err := g.docker.StartContainer(id); err != nil {
+				common.Logger.Errorf("cannot start container %s", err)
+				return err
+			}
+		}
+
+		g.containerPanel().updateEntries(g)
+		return nil
+	})
+}
+
+func (g *Gui) recreateContainerForm() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot recreate container: selected container is null")
+		return
+	}
+
+	c, err := g.docker.InspectContainer(container.ID)
+	if err != nil {
+		common.Logger.Errorf("cannot inspect container %s", err)
+		g.message(err.Error(), "OK", "containers", func() {})
+		return
+	}
+
+	image, err := g.docker.InspectImage(c.Image)
+	if err != nil {
+		common.Logger.Errorf("cannot inspect image %s", err)
+	}
+
+	spec := docker.SpecFromInspect(c, image)
+	form := newContainerForm(g, "Edit & recreate "+container.Name, "Recreate", spec, func(spec docker.ContainerSpec) {
+		g.closeAndSwitchPanel("form", "containers")
+		g.recreateContainer(c.ID, container.Name, spec)
+	}, func() {
+		g.closeAndSwitchPanel("form", "containers")
+	})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 29), true).ShowPage("main")
+}
+
+func (g *Gui) recreateContainer(id, name string, spec docker.ContainerSpec) {
+	g.startProgressTask("recreate container "+name, func(ctx context.Context, progress func(string)) error {
+		if err := docker.RecreateContainer(g.docker, id, spec, progress); err != nil {
+			common.Logger.Errorf("cannot recreate container %s", err)
+			return err
+		}
+
+		g.containerPanel().updateEntries(g)
+		return nil
+	})
+}
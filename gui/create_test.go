@@ -0,0 +1,88 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/skanehira/docui/docker"
+)
+
+func TestContainerValuesSpec(t *testing.T) {
+	values := containerValues{
+		name:       "web",
+		image:      "nginx:latest",
+		cmd:        `sh -c "echo 'hello world'"`,
+		restart:    "on-failure:3",
+		network:    "front",
+		aliases:    "web www",
+		memory:     "512m",
+		cpus:       "1.5",
+		capAdd:     "NET_ADMIN",
+		privileged: true,
+		ports:      []string{"8080:80", "", "127.0.0.1:5353:53/udp"},
+		mounts:     []string{"/srv:/usr/share/nginx/html:ro", "type=tmpfs,target=/tmp"},
+		env:        []string{"A=1", " "},
+		labels:     []string{"app=web", "tier"},
+	}
+
+	want := docker.ContainerSpec{
+		Name:          "web",
+		Image:         "nginx:latest",
+		Cmd:           []string{"sh", "-c", "echo 'hello world'"},
+		Env:           []string{"A=1"},
+		Labels:        map[string]string{"app": "web", "tier": ""},
+		RestartPolicy: "on-failure:3",
+		Network:       "front",
+		Aliases:       []string{"web", "www"},
+		Memory:        512 << 20,
+		CPUs:          1.5,
+		CapAdd:        []string{"NET_ADMIN"},
+		CapDrop:       []string{},
+		Privileged:    true,
+		Ports: []docker.PortBinding{
+			{HostPort: "8080", ContainerPort: "80"},
+			{HostIP: "127.0.0.1", HostPort: "5353", ContainerPort: "53", Protocol: "udp"},
+		},
+		Mounts: []docker.MountSpec{
+			{Type: mount.TypeBind, Source: "/srv", Target: "/usr/share/nginx/html", ReadOnly: true},
+			{Type: mount.TypeTmpfs, Target: "/tmp"},
+		},
+	}
+
+	got, err := values.spec()
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, got)
+	}
+
+	// the form shows the spec as it was parsed
+	roundTrip, err := newContainerValues(got).spec()
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if !reflect.DeepEqual(roundTrip, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, roundTrip)
+	}
+}
+
+func TestContainerValuesSpecError(t *testing.T) {
+	tests := []containerValues{
+		{},
+		{image: "nginx", cmd: `echo "unterminated`},
+		{image: "nginx", ports: []string{"80/icmp"}},
+		{image: "nginx", mounts: []string{"relative"}},
+		{image: "nginx", restart: "sometimes"},
+		{image: "nginx", memory: "lots"},
+		{image: "nginx", cpus: "-1"},
+		{image: "nginx", labels: []string{"=value"}},
+	}
+
+	for _, values := range tests {
+		if _, err := values.spec(); err == nil {
+			t.Errorf("Expected error for %+v.", values)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// changeKinds kinds of changes in display order
+var changeKinds = []struct {
+	kind  uint8
+	label string
+	color tcell.Color
+}{
+	{docker.ChangeAdd, "Added", tcell.ColorLightGreen},
+	{docker.ChangeModify, "Changed", tcell.ColorYellow},
+	{docker.ChangeDelete, "Deleted", tcell.ColorRed},
+}
+
+// changeGroup changed paths of a kind
+type changeGroup struct {
+	kind  uint8
+	paths []string
+}
+
+// groupChanges group paths by kind, paths which don't contain filter are excluded
+func groupChanges(changes []containertypes.ContainerChangeResponseItem, filter string, hidden map[uint8]bool) []changeGroup {
+	var groups []changeGroup
+	for _, k := range changeKinds {
+		if hidden[k.kind] {
+			continue
+		}
+
+		group := changeGroup{kind: k.kind}
+		for _, c := range changes {
+			if c.Kind == k.kind && strings.Contains(c.Path, filter) {
+				group.paths = append(group.paths, c.Path)
+			}
+		}
+		sort.Strings(group.paths)
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+type diffViewer struct {
+	*tview.Flex
+	table     *tview.Table
+	status    *tview.TextView
+	container *container
+	changes   []containertypes.ContainerChangeResponseItem
+	filter    string
+	hidden    map[uint8]bool
+	err       error
+}
+
+func (v *diffViewer) name() string {
+	return "diff"
+}
+
+func (g *Gui) showContainerDiff() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot show container diff: selected container is null")
+		return
+	}
+
+	v := &diffViewer{
+		Flex:      tview.NewFlex().SetDirection(tview.FlexRow),
+		table:     tview.NewTable().SetSelectable(true, false),
+		status:    tview.NewTextView().SetDynamicColors(true),
+		container: container,
+		hidden:    make(map[uint8]bool),
+	}
+	v.table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	v.AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+
+	v.setKeybinding(g)
+	v.refresh(g)
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+}
+
+func (v *diffViewer) setKeybinding(g *Gui) {
+	v.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			g.closeAndSwitchPanel(v.name(), "containers")
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'q':
+			g.closeAndSwitchPanel(v.name(), "containers")
+		case '/':
+			v.filterInput(g)
+		case 'a':
+			v.toggleKind(docker.ChangeAdd)
+		case 'c':
+			v.toggleKind(docker.ChangeModify)
+		case 'd':
+			v.toggleKind(docker.ChangeDelete)
+		case 'e':
+			v.exportForm(g)
+		case 'r':
+			v.refresh(g)
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+func (v *diffViewer) refresh(g *Gui) {
+	changes, err := g.docker.DiffContainer(context.Background(), v.container.ID)
+	if err != nil {
+		common.Logger.Errorf("cannot get container diff %s", err)
+	}
+
+	v.err = err
+	if err == nil {
+		v.changes = changes
+	}
+	v.render()
+}
+
+func (v *diffViewer) toggleKind(kind uint8) {
+	v.hidden[kind] = !v.hidden[kind]
+	v.render()
+}
+
+func (v *diffViewer) render() {
+	title := fmt.Sprintf(" diff of %s ", v.container.Name)
+	if v.filter != "" {
+		title = fmt.Sprintf(" diff of %s (filter: %s) ", v.container.Name, v.filter)
+	}
+	v.table.SetTitle(tview.Escape(title))
+	v.table.Clear()
+
+	row := 0
+	for _, group := range groupChanges(v.changes, v.filter, v.hidden) {
+		var label string
+		var color tcell.Color
+		for _, k := range changeKinds {
+			if k.kind == group.kind {
+				label, color = k.label, k.color
+			}
+		}
+
+		v.table.SetCell(row, 0, &tview.TableCell{
+			Text:            fmt.Sprintf("%s (%d)", label, len(group.paths)),
+			NotSelectable:   true,
+			Color:           tcell.ColorWhite,
+			BackgroundColor: tcell.ColorDefault,
+			Attributes:      tcell.AttrBold,
+		})
+		row++
+
+		for _, p := range group.paths {
+			v.table.SetCell(row, 0, tview.NewTableCell("  "+p).SetTextColor(color).SetExpansion(1))
+			row++
+		}
+	}
+
+	status := fmt.Sprintf(" %d changes | /: filter, a/c/d: show or hide added/changed/deleted, e: export changed files, r: refresh, q: close", len(v.changes))
+	if v.err != nil {
+		status = fmt.Sprintf(" [red]%s[-]", tview.Escape(v.err.Error()))
+	}
+	v.status.SetText(status)
+}
+
+// showPopup display p over the diff
+func (v *diffViewer) showPopup(g *Gui, name string, p tview.Primitive, width, height int) {
+	g.pages.AddAndSwitchToPage(name, g.modal(p, width, height), true).ShowPage(v.name())
+}
+
+func (v *diffViewer) closePopup(g *Gui, name string) {
+	g.pages.RemovePage(name).SwitchToPage(v.name())
+	g.app.SetFocus(v.table)
+}
+
+func (v *diffViewer) filterInput(g *Gui) {
+	viewName := "diffFilter"
+	input := tview.NewInputField().SetLabel("Path").SetText(v.filter)
+	input.SetLabelWidth(6)
+	input.SetTitle("filter").SetTitleAlign(tview.AlignLeft)
+	input.SetBorder(true)
+
+	input.SetChangedFunc(func(text string) {
+		v.filter = text
+		v.render()
+	})
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			v.closePopup(g, viewName)
+		case tcell.KeyEsc:
+			v.filter = ""
+			v.render()
+			v.closePopup(g, viewName)
+		}
+	})
+
+	v.showPopup(g, viewName, input, 80, 3)
+}
+
+func (v *diffViewer) exportForm(g *Gui) {
+	wd, _ := os.Getwd()
+
+	viewName := "diffExport"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Export changed files")
+	form.AddInputField("Path", filepath.Join(wd, v.container.Name+"-changes.tar"), inputWidth, nil, nil).
+		AddButton("Export", func() {
+			path := form.GetFormItemByLabel("Path").(*tview.InputField).GetText()
+			v.closePopup(g, viewName)
+			v.export(g, path)
+		}).
+		AddButton("Cancel", func() {
+			v.closePopup(g, viewName)
+		})
+
+	v.showPopup(g, viewName, form, 80, 7)
+}
+
+func (v *diffViewer) export(g *Gui, path string) {
+	id := v.container.ID
+	g.startProgressTask("export changes of "+v.container.Name, func(ctx context.Context, progress func(string)) error {
+		err := g.docker.ExportChanges(ctx, id, path, func(n int64) {
+			progress(common.ParseBytesToString(uint64(n)))
+		})
+		if err != nil {
+			common.Logger.Errorf("cannot export container changes %s", err)
+		}
+		return err
+	})
+}
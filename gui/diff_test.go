@@ -0,0 +1,37 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/skanehira/docui/docker"
+)
+
+func TestGroupChanges(t *testing.T) {
+	changes := []containertypes.ContainerChangeResponseItem{
+		{Kind: docker.ChangeModify, Path: "/etc"},
+		{Kind: docker.ChangeAdd, Path: "/etc/nginx/conf.d/app.conf"},
+		{Kind: docker.ChangeAdd, Path: "/etc/nginx/conf.d"},
+		{Kind: docker.ChangeDelete, Path: "/var/log/nginx/access.log"},
+	}
+
+	groups := groupChanges(changes, "", nil)
+	want := []changeGroup{
+		{kind: docker.ChangeAdd, paths: []string{"/etc/nginx/conf.d", "/etc/nginx/conf.d/app.conf"}},
+		{kind: docker.ChangeModify, paths: []string{"/etc"}},
+		{kind: docker.ChangeDelete, paths: []string{"/var/log/nginx/access.log"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Expected %v. Got %v.", want, groups)
+	}
+
+	groups = groupChanges(changes, "nginx", map[uint8]bool{docker.ChangeDelete: true})
+	want = []changeGroup{
+		{kind: docker.ChangeAdd, paths: []string{"/etc/nginx/conf.d", "/etc/nginx/conf.d/app.conf"}},
+		{kind: docker.ChangeModify},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Expected %v. Got %v.", want, groups)
+	}
+}
@@ -0,0 +1,325 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// formatReclaimable make the reclaimable size with the percentage, e.g. "1.5GiB (30%)"
+func formatReclaimable(u docker.Usage) string {
+	size := common.ParseBytesToString(uint64(u.Reclaimable))
+	if u.Size <= 0 {
+		return size
+	}
+	return fmt.Sprintf("%s (%d%%)", size, u.Reclaimable*100/u.Size)
+}
+
+type diskUsageViewer struct {
+	*tview.Flex
+	table  *tview.Table
+	status *tview.TextView
+	usage  types.DiskUsage
+	loaded bool
+	err    error
+	// panel panel to switch to when closed
+	panel string
+}
+
+func (v *diskUsageViewer) name() string {
+	return "diskUsage"
+}
+
+func (g *Gui) showDiskUsage() {
+	v := &diskUsageViewer{
+		Flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		table:  tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		status: tview.NewTextView().SetDynamicColors(true),
+		panel:  g.currentPanel().name(),
+	}
+	v.table.SetBorder(true).SetTitle(" disk usage ").SetTitleAlign(tview.AlignLeft)
+	v.AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+
+	v.setKeybinding(g)
+	v.render()
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+
+	go v.refresh(g)
+}
+
+func (v *diskUsageViewer) setKeybinding(g *Gui) {
+	v.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			g.closeAndSwitchPanel(v.name(), v.panel)
+		case event.Key() == tcell.KeyEnter || event.Rune() == 'p':
+			if category := v.selectedCategory(); category != "" {
+				v.pruneForm(g, category)
+			}
+		case event.Rune() == 'c':
+			v.cleanupPolicyForm(g)
+		case event.Rune() == 'r':
+			go v.refresh(g)
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+// refresh get the disk usage, it may take a while because the daemon calculates sizes
+func (v *diskUsageViewer) refresh(g *Gui) {
+	g.app.QueueUpdateDraw(func() {
+		v.status.SetText(" [yellow]calculating disk usage...[-]")
+	})
+
+	usage, err := g.docker.DiskUsage(context.Background())
+	if err != nil {
+		common.Logger.Errorf("cannot get disk usage %s", err)
+	}
+
+	g.app.QueueUpdateDraw(func() {
+		v.err = err
+		if err == nil {
+			v.usage = usage
+			v.loaded = true
+		}
+		v.render()
+	})
+}
+
+func (v *diskUsageViewer) selectedCategory() docker.UsageCategory {
+	row, _ := v.table.GetSelection()
+	if !v.loaded || row < 1 || row > len(docker.UsageCategories) {
+		return ""
+	}
+	return docker.UsageCategories[row-1]
+}
+
+func (v *diskUsageViewer) render() {
+	v.table.Clear()
+
+	headers := []string{"Type", "Total", "Active", "Size", "Reclaimable"}
+	for i, header := range headers {
+		v.table.SetCell(0, i, &tview.TableCell{
+			Text:            header,
+			NotSelectable:   true,
+			Align:           tview.AlignLeft,
+			Color:           tcell.ColorWhite,
+			BackgroundColor: tcell.ColorDefault,
+			Attributes:      tcell.AttrBold,
+		})
+	}
+
+	if v.loaded {
+		for i, u := range docker.SummarizeDiskUsage(v.usage) {
+			cells := []string{
+				string(u.Category),
+				strconv.Itoa(u.Total),
+				strconv.Itoa(u.Active),
+				common.ParseBytesToString(uint64(u.Size)),
+				formatReclaimable(u),
+			}
+			for j, cell := range cells {
+				v.table.SetCell(i+1, j, tview.NewTableCell(cell).
+					SetTextColor(tcell.ColorLightPink).
+					SetExpansion(1))
+			}
+		}
+	}
+
+	if v.err != nil {
+		v.status.SetText(" [red]" + tview.Escape(v.err.Error()) + "[-]")
+		return
+	}
+	v.status.SetText(" p: prune, c: cleanup by policy, r: refresh, q: close")
+}
+
+func (v *diskUsageViewer) showPopup(g *Gui, name string, p tview.Primitive, width, height int) {
+	g.pages.AddAndSwitchToPage(name, g.modal(p, width, height), true).ShowPage(v.name())
+}
+
+func (v *diskUsageViewer) closePopup(g *Gui, name string) {
+	g.pages.RemovePage(name).SwitchToPage(v.name())
+	g.app.SetFocus(v.table)
+}
+
+// pruneForm input the filter, and show the resources to be pruned
+func (v *diskUsageViewer) pruneForm(g *Gui, category docker.UsageCategory) {
+	viewName := "pruneFilter"
+	title := "Prune " + strings.ToLower(string(category))
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+
+	// volumes don't support until, and build cache doesn't support labels
+	height := 7
+	if category != docker.VolumeUsage {
+		form.AddInputField("Until", "", inputWidth, nil, nil)
+		height += 2
+	}
+	if category != docker.BuildCacheUsage {
+		form.AddInputField("Labels", "", inputWidth, nil, nil)
+		height += 2
+	}
+	if category == docker.ImageUsage {
+		form.AddCheckbox("All unused", false, nil)
+		height += 2
+	}
+
+	form.AddButton("Preview", func() {
+		filter, err := pruneFilter(form)
+		var items []docker.PruneItem
+		if err == nil {
+			items, err = docker.PruneCandidates(v.usage, category, filter, time.Now())
+		}
+		if err == nil && len(items) == 0 {
+			err = fmt.Errorf("nothing to prune")
+		}
+		if err != nil {
+			form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+		g.pages.RemovePage(viewName)
+		v.previewPrune(g, category, items)
+	}).
+		AddButton("Cancel", func() {
+			v.closePopup(g, viewName)
+		})
+
+	v.showPopup(g, viewName, form, 80, height)
+}
+
+// pruneFilter make the filter from the prune form
+func pruneFilter(form *tview.Form) (docker.PruneFilter, error) {
+	var filter docker.PruneFilter
+	if item := form.GetFormItemByLabel("Until"); item != nil {
+		filter.Until = strings.TrimSpace(item.(*tview.InputField).GetText())
+	}
+	if item := form.GetFormItemByLabel("Labels"); item != nil {
+		labels, err := common.SplitShellWords(item.(*tview.InputField).GetText())
+		if err != nil {
+			return filter, fmt.Errorf("invalid labels: %s", err)
+		}
+		filter.Labels = labels
+	}
+	if item := form.GetFormItemByLabel("All unused"); item != nil {
+		filter.AllImages = item.(*tview.Checkbox).IsChecked()
+	}
+	return filter, nil
+}
+
+// previewPrune show the dry run list of prune, and remove just them when it is accepted
+func (v *diskUsageViewer) previewPrune(g *Gui, category docker.UsageCategory, items []docker.PruneItem) {
+	viewName := "prunePreview"
+
+	var total int64
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	for i, header := range []string{"ID", "Name", "Size", "Created"} {
+		table.SetCell(0, i, &tview.TableCell{
+			Text:            header,
+			NotSelectable:   true,
+			Align:           tview.AlignLeft,
+			Color:           tcell.ColorWhite,
+			BackgroundColor: tcell.ColorDefault,
+			Attributes:      tcell.AttrBold,
+		})
+	}
+	for i, item := range items {
+		total += item.Size
+		created := ""
+		if !item.Created.IsZero() {
+			created = item.Created.Format("2006/01/02 15:04:05")
+		}
+		cells := []string{
+			shortID(item.ID),
+			item.Name,
+			common.ParseBytesToString(uint64(item.Size)),
+			created,
+		}
+		for j, cell := range cells {
+			table.SetCell(i+1, j, tview.NewTableCell(cell).
+				SetTextColor(tcell.ColorLightPink).
+				SetMaxWidth(1).
+				SetExpansion(1))
+		}
+	}
+	table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	table.SetTitle(fmt.Sprintf(" %d %s to prune, up to %s (dry run) ", len(items),
+		strings.ToLower(string(category)), common.ParseBytesToString(uint64(total))))
+
+	status := tview.NewTextView().SetText(" p: prune, q: cancel")
+	preview := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(status, 1, 0, false)
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			v.closePopup(g, viewName)
+		case event.Rune() == 'p':
+			v.closePopup(g, viewName)
+			v.prune(g, category, items)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	g.pages.AddAndSwitchToPage(viewName, preview, true)
+}
+
+func (v *diskUsageViewer) prune(g *Gui, category docker.UsageCategory, items []docker.PruneItem) {
+	name := strings.ToLower(string(category))
+	g.startProgressTask("prune "+name, func(ctx context.Context, progress func(string)) error {
+		failed := 0
+		var reclaimed int64
+		for i, item := range items {
+			if ctx.Err() != nil {
+				break
+			}
+			progress(fmt.Sprintf("%d/%d", i+1, len(items)))
+			if err := docker.RemovePruneItem(ctx, g.docker, category, item); err != nil {
+				common.Logger.Errorf("cannot prune %s %s", item.Name, err)
+				failed++
+				continue
+			}
+			reclaimed += item.Size
+		}
+		common.Logger.Infof("pruned %d %s, reclaimed up to %s", len(items)-failed, name,
+			common.ParseBytesToString(uint64(reclaimed)))
+
+		switch category {
+		case docker.ImageUsage:
+			g.imagePanel().updateEntries(g)
+		case docker.ContainerUsage:
+			g.containerPanel().updateEntries(g)
+		case docker.VolumeUsage:
+			g.volumePanel().updateEntries(g)
+		}
+		v.refresh(g)
+
+		if failed > 0 {
+			return fmt.Errorf("cannot prune %d of %d %s", failed, len(items), name)
+		}
+		return nil
+	})
+}
+
+// shortID cut the 64 characters id like docker cli, other ids like volume names are not changed
+func shortID(id string) string {
+	if trimmed := strings.TrimPrefix(id, "sha256:"); len(trimmed) == 64 {
+		return trimmed[:12]
+	}
+	return id
+}
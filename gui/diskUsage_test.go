@@ -0,0 +1,71 @@
+package gui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/docker"
+)
+
+func TestFormatReclaimable(t *testing.T) {
+	if got := formatReclaimable(docker.Usage{Size: 2048, Reclaimable: 512}); got != "512B (25%)" {
+		t.Errorf("Expected 512B (25%%). Got %s.", got)
+	}
+	if got := formatReclaimable(docker.Usage{}); got != "0B" {
+		t.Errorf("Expected 0B without the percentage. Got %s.", got)
+	}
+}
+
+func TestShortID(t *testing.T) {
+	id := "sha256:" + strings.Repeat("ab", 32)
+	if got := shortID(id); got != "abababababab" {
+		t.Errorf("Expected 12 characters. Got %s.", got)
+	}
+	if got := shortID("my-long-volume-name"); got != "my-long-volume-name" {
+		t.Errorf("Expected the volume name as it is. Got %s.", got)
+	}
+}
+
+func TestPruneFilter(t *testing.T) {
+	form := tview.NewForm().
+		AddInputField("Until", "24h", inputWidth, nil, nil).
+		AddInputField("Labels", "env=dev '!keep'", inputWidth, nil, nil).
+		AddCheckbox("All unused", true, nil)
+	filter, err := pruneFilter(form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := docker.PruneFilter{Until: "24h", Labels: []string{"env=dev", "!keep"}, AllImages: true}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, filter)
+	}
+
+	// the volume form has no until field
+	form = tview.NewForm().AddInputField("Labels", "'env", inputWidth, nil, nil)
+	if _, err := pruneFilter(form); err == nil {
+		t.Error("Expected error with the unterminated quote.")
+	}
+}
+
+func TestPreviewPrune(t *testing.T) {
+	g := newTestGui(t, newTestFake())
+	g.showDiskUsage()
+	if !g.pages.HasPage("diskUsage") {
+		t.Fatal("Expected the disk usage page.")
+	}
+
+	v := &diskUsageViewer{table: tview.NewTable()}
+	v.pruneForm(g, docker.VolumeUsage)
+	if !g.pages.HasPage("pruneFilter") {
+		t.Fatal("Expected the prune form.")
+	}
+
+	items := []docker.PruneItem{{ID: "cache", Name: "cache", Size: 100}}
+	g.pages.RemovePage("pruneFilter")
+	v.previewPrune(g, docker.VolumeUsage, items)
+	if !g.pages.HasPage("prunePreview") {
+		t.Error("Expected the dry run list.")
+	}
+}
@@ -0,0 +1,179 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// runSummary describe the parsed docker run command for confirmation
+func runSummary(cmd docker.RunCommand) string {
+	var lines []string
+	add := func(label string, values ...string) {
+		if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+			return
+		}
+		for i, value := range values {
+			if i > 0 {
+				label = ""
+			}
+			lines = append(lines, fmt.Sprintf("%-12s %s", label, tview.Escape(value)))
+		}
+	}
+
+	spec := cmd.Spec
+	add("Image:", spec.Image)
+	add("Name:", spec.Name)
+	add("Cmd:", common.JoinShellWords(spec.Cmd))
+	add("Entrypoint:", common.JoinShellWords(spec.Entrypoint))
+	add("User:", spec.User)
+	add("Working dir:", spec.WorkingDir)
+
+	var ports, mounts []string
+	for _, p := range spec.Ports {
+		ports = append(ports, p.String())
+	}
+	for _, m := range spec.Mounts {
+		mounts = append(mounts, m.String())
+	}
+	add("Ports:", ports...)
+	add("Mounts:", mounts...)
+	add("Env:", spec.Env...)
+
+	var keys, labels []string
+	for k := range spec.Labels {
+		keys = append(keys, k)
+	}
+	for _, k := range common.SortKeys(keys) {
+		labels = append(labels, k+"="+spec.Labels[k])
+	}
+	add("Labels:", labels...)
+
+	add("Restart:", spec.RestartPolicy)
+	add("Network:", spec.Network)
+	add("Aliases:", strings.Join(spec.Aliases, " "))
+	if spec.Memory > 0 {
+		add("Memory:", docker.FormatMemory(spec.Memory))
+	}
+	if spec.CPUs > 0 {
+		add("CPUs:", strconv.FormatFloat(spec.CPUs, 'f', -1, 64))
+	}
+	add("Cap add:", strings.Join(spec.CapAdd, " "))
+	add("Cap drop:", strings.Join(spec.CapDrop, " "))
+
+	var flags []string
+	for _, flag := range []struct {
+		name string
+		on   bool
+	}{
+		{"privileged", spec.Privileged},
+		{"tty", spec.Tty},
+		{"interactive", spec.OpenStdin},
+		{"auto remove", spec.AutoRemove},
+	} {
+		if flag.on {
+			flags = append(flags, flag.name)
+		}
+	}
+	add("Flags:", strings.Join(flags, ", "))
+
+	if cmd.Start && !cmd.Detach && (spec.Tty || spec.OpenStdin) {
+		lines = append(lines, "", "[yellow]the container runs in the background, exec it from the containers panel[-]")
+	}
+
+	if len(cmd.Unsupported) > 0 {
+		lines = append(lines, "", "[red]unsupported flags are ignored:[-]")
+		for _, flag := range cmd.Unsupported {
+			lines = append(lines, "  [red]"+tview.Escape(flag)+"[-]")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (g *Gui) runCommandForm() {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Run from command")
+	form.AddInputField("Command", "docker run ", inputWidth, nil, nil).
+		AddButton("Parse", func() {
+			line := form.GetFormItemByLabel("Command").(*tview.InputField).GetText()
+			cmd, err := docker.ParseRunCommand(line)
+			if err != nil {
+				form.SetTitle("Run from command [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.closeAndSwitchPanel("form", "images")
+			g.confirmRunCommand(cmd)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "images")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 7), true).ShowPage("main")
+}
+
+// confirmRunCommand show the parsed command, and create the container when confirmed
+func (g *Gui) confirmRunCommand(cmd docker.RunCommand) {
+	viewName := "run"
+
+	text := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	text.SetText(runSummary(cmd))
+
+	submit := "Create"
+	if cmd.Start {
+		submit = "Run"
+	}
+
+	buttons := tview.NewForm().
+		AddButton(submit, func() {
+			g.closeAndSwitchPanel(viewName, "images")
+			g.createContainer(cmd.Spec, cmd.Start)
+		}).
+		AddButton("Edit", func() {
+			g.closeAndSwitchPanel(viewName, "images")
+			g.editRunCommand(cmd, submit)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel(viewName, "images")
+		})
+	buttons.SetCancelFunc(func() {
+		g.closeAndSwitchPanel(viewName, "images")
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(text, 0, 1, false).
+		AddItem(buttons, 3, 0, true)
+	flex.SetBorder(true).SetTitleAlign(tview.AlignLeft).SetTitle(" parsed command ")
+
+	buttons.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// scroll the summary while focusing the buttons
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			text.InputHandler()(event, func(p tview.Primitive) {})
+			return nil
+		}
+		return event
+	})
+
+	g.pages.AddAndSwitchToPage(viewName, g.modal(flex, 100, 30), true).ShowPage("main")
+}
+
+// editRunCommand open the create container form with the parsed command
+func (g *Gui) editRunCommand(cmd docker.RunCommand, submit string) {
+	form := newContainerForm(g, "Edit command", submit, cmd.Spec, func(spec docker.ContainerSpec) {
+		g.closeAndSwitchPanel("form", "images")
+		g.createContainer(spec, cmd.Start)
+	}, func() {
+		g.closeAndSwitchPanel("form", "images")
+	})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 29), true).ShowPage("main")
+}
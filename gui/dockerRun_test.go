@@ -0,0 +1,30 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skanehira/docui/docker"
+)
+
+func TestRunSummary(t *testing.T) {
+	cmd, err := docker.ParseRunCommand(`docker run -it -p 8080:80 -e A=1 -e B=2 --hostname web nginx echo "[hello]"`)
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	got := runSummary(cmd)
+	for _, want := range []string{
+		"Image:       nginx",
+		"Cmd:         echo '[hello[]'",
+		"Ports:       8080:80",
+		"Env:         A=1\n             B=2",
+		"Flags:       tty, interactive",
+		"the container runs in the background",
+		"[red]--hostname web[-]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in %q.", want, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package gui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/skanehira/docui/common"
+)
+
+// eventDebounce events within this interval are coalesced into one refresh
+var eventDebounce = 200 * time.Millisecond
+
+func (g *Gui) eventPanel(msg events.Message) panel {
+	switch msg.Type {
+	case events.ContainerEventType:
+		// exec events don't change the container list
+		if strings.HasPrefix(msg.Action, "exec_") {
+			return nil
+		}
+		return g.containerPanel()
+	case events.ImageEventType:
+		return g.imagePanel()
+	case events.VolumeEventType:
+		return g.volumePanel()
+	case events.NetworkEventType:
+		return g.networkPanel()
+	}
+	return nil
+}
+
+func (g *Gui) monitoringEvents() {
+	common.Logger.Info("start monitoring events")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := g.state.stopChans["event"]
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	timers := make(map[string]*time.Timer)
+
+	err := g.docker.WatchEvents(ctx, func(msg events.Message) {
+		panel := g.eventPanel(msg)
+		if panel == nil {
+			return
+		}
+
+		if timer, ok := timers[panel.name()]; ok {
+			timer.Reset(eventDebounce)
+			return
+		}
+
+		timers[panel.name()] = time.AfterFunc(eventDebounce, func() {
+			panel.updateEntries(g)
+		})
+	})
+
+	for _, timer := range timers {
+		timer.Stop()
+	}
+
+	if err != nil {
+		common.Logger.Errorf("cannot watch docker events, fallback to polling: %s", err)
+		g.startPolling()
+		return
+	}
+
+	common.Logger.Info("stop monitoring events")
+}
@@ -0,0 +1,165 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// execHistoryLimit number of exec commands remembered per container
+var execHistoryLimit = 20
+
+// addExecHistory add the command to the front of the history, and remove the duplicate
+func addExecHistory(history []string, cmd string) []string {
+	result := []string{cmd}
+	for _, c := range history {
+		if c != cmd && len(result) < execHistoryLimit {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// execOptions parse input values of the exec form
+func execOptions(cmd, user, workingDir, env string, privileged, tty bool) (docker.ExecOptions, error) {
+	opt := docker.ExecOptions{
+		User:       strings.TrimSpace(user),
+		WorkingDir: strings.TrimSpace(workingDir),
+		Privileged: privileged,
+		Tty:        tty,
+	}
+
+	var err error
+	if opt.Cmd, err = common.SplitShellWords(cmd); err != nil {
+		return opt, fmt.Errorf("invalid cmd: %s", err)
+	}
+	if len(opt.Cmd) == 0 {
+		return opt, fmt.Errorf("cmd is required")
+	}
+
+	if opt.Env, err = common.SplitShellWords(env); err != nil {
+		return opt, fmt.Errorf("invalid env: %s", err)
+	}
+	for _, e := range opt.Env {
+		if !strings.Contains(e, "=") || strings.HasPrefix(e, "=") {
+			return opt, fmt.Errorf("invalid env %s", e)
+		}
+	}
+
+	return opt, nil
+}
+
+func (g *Gui) attachContainerForm() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot exec container: selected container is null")
+		return
+	}
+
+	history := g.state.execHistory[container.ID]
+	cmd := ""
+	if len(history) > 0 {
+		cmd = history[0]
+	}
+
+	title := "Exec container " + container.Name
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+
+	cmdField := tview.NewInputField().SetLabel("Cmd").SetText(cmd).SetFieldWidth(inputWidth)
+	form.AddFormItem(cmdField)
+	if len(history) > 0 {
+		form.AddDropDown("History", history, 0, func(option string, optionIndex int) {
+			cmdField.SetText(option)
+		})
+	}
+
+	text := func(label string) string {
+		return form.GetFormItemByLabel(label).(*tview.InputField).GetText()
+	}
+	checked := func(label string) bool {
+		return form.GetFormItemByLabel(label).(*tview.Checkbox).IsChecked()
+	}
+
+	form.AddInputField("User", "", inputWidth, nil, nil).
+		AddInputField("Working dir", "", inputWidth, nil, nil).
+		AddInputField("Env", "", inputWidth, nil, nil).
+		AddCheckbox("Privileged", false, nil).
+		AddCheckbox("TTY", true, nil).
+		AddButton("Exec", func() {
+			opt, err := execOptions(cmdField.GetText(), text("User"), text("Working dir"), text("Env"), checked("Privileged"), checked("TTY"))
+			if err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.state.execHistory[container.ID] = addExecHistory(history, common.JoinShellWords(opt.Cmd))
+			g.attachContainer(container, opt)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "containers")
+		})
+
+	height := 17
+	if len(history) > 0 {
+		height += 2
+	}
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, height), true).ShowPage("main")
+}
+
+// attachContainer open the exec session in the terminal pane
+func (g *Gui) attachContainer(container *container, opt docker.ExecOptions) {
+	g.closeAndSwitchPanel("form", "containers")
+	g.openTerminal(container, opt)
+}
+
+func (g *Gui) attachMainProcessForm() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot attach container: selected container is null")
+		return
+	}
+
+	title := "Attach container " + container.Name
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+	form.AddInputField("Detach keys", g.state.detachKeys, inputWidth, nil, nil).
+		AddButton("Attach", func() {
+			keys := form.GetFormItemByLabel("Detach keys").(*tview.InputField).GetText()
+			if err := docker.ValidateDetachKeys(keys); err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.state.detachKeys = keys
+			g.attachMainProcess(container.ID, keys)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "containers")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 7), true).ShowPage("main")
+}
+
+// attachMainProcess attach to PID 1 of the container until it exits or is detached
+func (g *Gui) attachMainProcess(container, detachKeys string) {
+	g.closeAndSwitchPanel("form", "containers")
+
+	if !g.app.Suspend(func() {
+		g.stopMonitoring()
+		if err := g.docker.AttachContainer(container, detachKeys); err != nil {
+			common.Logger.Errorf("cannot attach container %s", err)
+		}
+
+		g.startMonitoring()
+	}) {
+		common.Logger.Error("cannot suspend tview")
+	}
+}
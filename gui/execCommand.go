@@ -0,0 +1,210 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// commandResult result of the command in a container
+type commandResult struct {
+	container *container
+	result    docker.ExecResult
+	err       error
+	done      bool
+}
+
+// formatCommandResults make the output grouped per container
+func formatCommandResults(results []*commandResult) string {
+	var b strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		header := "[yellow]== %s[-] "
+		switch {
+		case !r.done:
+			header += "running..."
+		case r.err != nil:
+			header += "[red]error[-]"
+		case r.result.ExitCode != 0:
+			header += fmt.Sprintf("[red]exit %d[-]", r.result.ExitCode)
+		default:
+			header += "[green]exit 0[-]"
+		}
+		fmt.Fprintf(&b, header+"\n", tview.Escape(r.container.Name))
+
+		if r.err != nil {
+			fmt.Fprintf(&b, "[red]%s[-]\n", tview.Escape(r.err.Error()))
+			continue
+		}
+		b.WriteString(tview.Escape(r.result.Stdout))
+		if r.result.Stdout != "" && !strings.HasSuffix(r.result.Stdout, "\n") {
+			b.WriteString("\n")
+		}
+		if r.result.Stderr != "" {
+			b.WriteString("[red]" + tview.Escape(strings.TrimSuffix(r.result.Stderr, "\n")) + "[-]\n")
+		}
+	}
+	return b.String()
+}
+
+type commandViewer struct {
+	*tview.Flex
+	text    *tview.TextView
+	status  *tview.TextView
+	opt     docker.ExecOptions
+	results []*commandResult
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+}
+
+func (v *commandViewer) name() string {
+	return "command"
+}
+
+func (g *Gui) execCommandForm() {
+	containers := g.selectedContainers()
+	if len(containers) == 0 {
+		common.Logger.Errorf("cannot run command: selected container is null")
+		return
+	}
+
+	history := g.state.execHistory[containers[0].ID]
+	cmd := ""
+	if len(history) > 0 {
+		cmd = history[0]
+	}
+
+	var names []string
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+
+	title := "Run command in " + strings.Join(names, ", ")
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+
+	cmdField := tview.NewInputField().SetLabel("Cmd").SetText(cmd).SetFieldWidth(inputWidth)
+	form.AddFormItem(cmdField)
+	if len(history) > 0 {
+		form.AddDropDown("History", history, 0, func(option string, optionIndex int) {
+			cmdField.SetText(option)
+		})
+	}
+
+	text := func(label string) string {
+		return form.GetFormItemByLabel(label).(*tview.InputField).GetText()
+	}
+
+	form.AddInputField("User", "", inputWidth, nil, nil).
+		AddInputField("Working dir", "", inputWidth, nil, nil).
+		AddInputField("Env", "", inputWidth, nil, nil).
+		AddCheckbox("Privileged", false, nil).
+		AddButton("Run", func() {
+			opt, err := execOptions(cmdField.GetText(), text("User"), text("Working dir"), text("Env"),
+				form.GetFormItemByLabel("Privileged").(*tview.Checkbox).IsChecked(), false)
+			if err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			for _, c := range containers {
+				g.state.execHistory[c.ID] = addExecHistory(g.state.execHistory[c.ID], common.JoinShellWords(opt.Cmd))
+			}
+			g.pages.RemovePage("form")
+			g.showCommandOutput(containers, opt)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "containers")
+		})
+
+	height := 15
+	if len(history) > 0 {
+		height += 2
+	}
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, height), true).ShowPage("main")
+}
+
+// showCommandOutput run the command in the containers in parallel, and show the output
+func (g *Gui) showCommandOutput(containers []*container, opt docker.ExecOptions) {
+	v := &commandViewer{
+		Flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		text:   tview.NewTextView().SetDynamicColors(true).SetScrollable(true),
+		status: tview.NewTextView().SetDynamicColors(true),
+		opt:    opt,
+	}
+	v.text.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	v.text.SetTitle(tview.Escape(" " + common.JoinShellWords(opt.Cmd) + " "))
+	v.AddItem(v.text, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+	v.status.SetText(" r: run again, q: close")
+
+	for _, c := range containers {
+		v.results = append(v.results, &commandResult{container: c})
+	}
+
+	v.text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			v.cancel()
+			g.closeAndSwitchPanel(v.name(), "containers")
+		case event.Rune() == 'r':
+			v.run(g)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+	v.run(g)
+}
+
+func (v *commandViewer) run(g *Gui) {
+	if v.cancel != nil {
+		v.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel = cancel
+
+	v.mu.Lock()
+	for i, r := range v.results {
+		v.results[i] = &commandResult{container: r.container}
+	}
+	results := v.results
+	v.mu.Unlock()
+	v.render()
+
+	for _, r := range results {
+		go func(r *commandResult) {
+			result, err := g.docker.ExecCommand(ctx, r.container.ID, v.opt)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				common.Logger.Errorf("cannot run command in %s %s", r.container.Name, err)
+			}
+
+			v.mu.Lock()
+			r.result, r.err, r.done = result, err, true
+			v.mu.Unlock()
+			g.app.QueueUpdateDraw(v.render)
+		}(r)
+	}
+}
+
+func (v *commandViewer) render() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.text.SetText(formatCommandResults(v.results))
+}
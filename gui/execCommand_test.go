@@ -0,0 +1,64 @@
+package gui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skanehira/docui/docker"
+)
+
+func TestFormatCommandResults(t *testing.T) {
+	results := []*commandResult{
+		{container: &container{Name: "web"}, result: docker.ExecResult{Stdout: "A=1\nB=2"}, done: true},
+		{container: &container{Name: "db"}, result: docker.ExecResult{Stderr: "not found\n", ExitCode: 127}, done: true},
+		{container: &container{Name: "cache"}, err: errors.New("container cache is not running"), done: true},
+		{container: &container{Name: "api"}},
+	}
+
+	expect := "[yellow]== web[-] [green]exit 0[-]\nA=1\nB=2\n" +
+		"\n[yellow]== db[-] [red]exit 127[-]\n[red]not found[-]\n" +
+		"\n[yellow]== cache[-] [red]error[-]\n[red]container cache is not running[-]\n" +
+		"\n[yellow]== api[-] running...\n"
+	if got := formatCommandResults(results); got != expect {
+		t.Errorf("Expected %q. Got %q.", expect, got)
+	}
+}
+
+func TestShowCommandOutput(t *testing.T) {
+	fake := newTestFake()
+	fake.ExecResults["1111111111111111"] = docker.ExecResult{Stdout: "127.0.0.1 localhost\n"}
+	g := newTestGui(t, fake)
+
+	g.showCommandOutput(g.state.resources.containers, docker.ExecOptions{Cmd: []string{"cat", "/etc/hosts"}})
+
+	_, page := g.pages.GetFrontPage()
+	v := page.(*commandViewer)
+
+	done := func() bool {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		for _, r := range v.results {
+			if !r.done {
+				return false
+			}
+		}
+		return true
+	}
+	deadline := time.Now().Add(time.Second)
+	for !done() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	v.mu.Lock()
+	output := formatCommandResults(v.results)
+	v.mu.Unlock()
+	if !strings.Contains(output, "127.0.0.1 localhost") {
+		t.Errorf("Expected the output of web. Got %q.", output)
+	}
+	if !strings.Contains(output, "container db is not running") {
+		t.Errorf("Expected the error of db. Got %q.", output)
+	}
+	v.cancel()
+}
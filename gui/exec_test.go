@@ -0,0 +1,54 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/skanehira/docui/docker"
+)
+
+func TestAddExecHistory(t *testing.T) {
+	history := addExecHistory(nil, "sh")
+	history = addExecHistory(history, "ls /")
+	history = addExecHistory(history, "sh")
+
+	want := []string{"sh", "ls /"}
+	if !reflect.DeepEqual(history, want) {
+		t.Errorf("Expected %v. Got %v.", want, history)
+	}
+
+	for i := 0; i < execHistoryLimit+5; i++ {
+		history = addExecHistory(history, string(rune('a'+i)))
+	}
+	if len(history) != execHistoryLimit {
+		t.Errorf("Expected %d. Got %d.", execHistoryLimit, len(history))
+	}
+}
+
+func TestExecOptions(t *testing.T) {
+	got, err := execOptions(`sh -c "ls /"`, "root", "/tmp", `A=1 "B=hello world"`, true, false)
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	want := docker.ExecOptions{
+		Cmd:        []string{"sh", "-c", "ls /"},
+		User:       "root",
+		WorkingDir: "/tmp",
+		Env:        []string{"A=1", "B=hello world"},
+		Privileged: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v. Got %+v.", want, got)
+	}
+
+	for _, tt := range []struct{ cmd, env string }{
+		{"", ""},
+		{`sh -c "ls`, ""},
+		{"sh", "A"},
+	} {
+		if _, err := execOptions(tt.cmd, "", "", tt.env, false, true); err == nil {
+			t.Errorf("Expected error for %+v.", tt)
+		}
+	}
+}
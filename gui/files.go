@@ -0,0 +1,395 @@
+package gui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// fileViewLimit max bytes of the file shown inline
+var fileViewLimit int64 = 1024 * 1024
+
+type fileBrowser struct {
+	*tview.Flex
+	table  *tview.Table
+	status *tview.TextView
+	// id container to copy files from
+	id    string
+	label string
+	// root directory in the container, paths are shown relative to it
+	root     string
+	readOnly bool
+	// panel to switch to when the browser is closed
+	panel   string
+	onClose func()
+	dir     string
+	entries []docker.FileEntry
+	loading bool
+	err     error
+	cancel  context.CancelFunc
+}
+
+func (b *fileBrowser) name() string {
+	return "files"
+}
+
+func (g *Gui) showContainerFiles() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot browse container files: selected container is null")
+		return
+	}
+
+	g.showFiles(&fileBrowser{
+		id:    container.ID,
+		label: container.Name,
+		root:  "/",
+		panel: "containers",
+	})
+}
+
+// showFiles show the file browser
+func (g *Gui) showFiles(b *fileBrowser) {
+	b.Flex = tview.NewFlex().SetDirection(tview.FlexRow)
+	b.table = tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	b.status = tview.NewTextView().SetDynamicColors(true)
+	b.cancel = func() {}
+	b.table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	b.AddItem(b.table, 0, 1, true).
+		AddItem(b.status, 1, 0, false)
+
+	b.setKeybinding(g)
+	g.pages.AddAndSwitchToPage(b.name(), b, true)
+	b.open(g, "/")
+}
+
+func (b *fileBrowser) setKeybinding(g *Gui) {
+	b.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// only close works until the first directory is loaded
+		if b.dir == "" && event.Key() != tcell.KeyEsc && event.Rune() != 'q' {
+			return nil
+		}
+
+		switch event.Key() {
+		case tcell.KeyEsc:
+			b.close(g)
+			return nil
+		case tcell.KeyEnter:
+			b.enter(g)
+			return nil
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			b.open(g, path.Dir(b.dir))
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'q':
+			b.close(g)
+		case 'v':
+			if e := b.selected(); e != nil && !e.IsDir() {
+				b.view(g, path.Join(b.dir, e.Name))
+			}
+		case 'd':
+			b.downloadForm(g)
+		case 'u':
+			if !b.readOnly {
+				b.uploadForm(g)
+			}
+		case 'r':
+			b.open(g, b.dir)
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+func (b *fileBrowser) close(g *Gui) {
+	b.cancel()
+	if b.onClose != nil {
+		b.onClose()
+	}
+	g.closeAndSwitchPanel(b.name(), b.panel)
+}
+
+// containerPath return the path in the container of the path shown in the browser
+func (b *fileBrowser) containerPath(p string) string {
+	return path.Join(b.root, p)
+}
+
+// selected return the selected entry, nil when ".." is selected
+func (b *fileBrowser) selected() *docker.FileEntry {
+	row, _ := b.table.GetSelection()
+	i := row - 1
+	if b.dir != "/" {
+		i--
+	}
+	if i < 0 || i >= len(b.entries) {
+		return nil
+	}
+	return &b.entries[i]
+}
+
+// selectedPath return the path of the selected entry, or the current directory for ".."
+func (b *fileBrowser) selectedPath() string {
+	if e := b.selected(); e != nil {
+		return path.Join(b.dir, e.Name)
+	}
+	return b.dir
+}
+
+func (b *fileBrowser) enter(g *Gui) {
+	e := b.selected()
+	switch {
+	case e == nil:
+		b.open(g, path.Dir(b.dir))
+	case e.IsDir() || e.Mode&os.ModeSymlink != 0:
+		// links are opened as directory, and shown when they point to a file
+		b.open(g, path.Join(b.dir, e.Name))
+	default:
+		b.view(g, path.Join(b.dir, e.Name))
+	}
+}
+
+// open list the directory in background
+func (b *fileBrowser) open(g *Gui, dir string) {
+	b.cancel()
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.loading = true
+	b.render(dir)
+
+	go func() {
+		entries, err := g.docker.ListDir(ctx, b.id, b.containerPath(dir))
+		if ctx.Err() != nil {
+			return
+		}
+
+		g.app.QueueUpdateDraw(func() {
+			b.loading = false
+			if err != nil {
+				common.Logger.Errorf("cannot list container files %s", err)
+				// the link may point to a file
+				if b.dir != dir {
+					if e := b.selected(); e != nil && e.Mode&os.ModeSymlink != 0 && path.Join(b.dir, e.Name) == dir {
+						b.render(b.dir)
+						b.view(g, dir)
+						return
+					}
+				}
+				b.err = err
+				b.render(b.dir)
+				return
+			}
+
+			b.err = nil
+			b.dir = dir
+			b.entries = entries
+			b.table.Select(1, 0)
+			b.render(dir)
+		})
+	}()
+}
+
+func (b *fileBrowser) render(dir string) {
+	b.table.SetTitle(fmt.Sprintf(" files of %s: %s ", tview.Escape(b.label), tview.Escape(dir)))
+	b.table.Clear()
+
+	for i, header := range []string{"Name", "Size", "Mode", "Modified"} {
+		b.table.SetCell(0, i, &tview.TableCell{
+			Text:            header,
+			NotSelectable:   true,
+			Align:           tview.AlignLeft,
+			Color:           tcell.ColorWhite,
+			BackgroundColor: tcell.ColorDefault,
+			Attributes:      tcell.AttrBold,
+		})
+	}
+
+	// b.dir is empty until the first directory is loaded
+	row := 1
+	if b.dir != "" && b.dir != "/" {
+		b.table.SetCell(row, 0, tview.NewTableCell("..").SetTextColor(tcell.ColorLightSkyBlue))
+		row++
+	}
+
+	for _, e := range b.entries {
+		name, size, color := e.Name, common.ParseBytesToString(uint64(e.Size)), tcell.ColorLightGreen
+		switch {
+		case e.IsDir():
+			name, size, color = name+"/", "-", tcell.ColorLightSkyBlue
+		case e.Mode&os.ModeSymlink != 0:
+			name, size, color = name+" -> "+e.LinkTarget, "-", tcell.ColorAqua
+		}
+
+		b.table.SetCell(row, 0, tview.NewTableCell(name).SetTextColor(color).SetExpansion(1))
+		b.table.SetCell(row, 1, tview.NewTableCell(size).SetTextColor(tcell.ColorLightGreen))
+		b.table.SetCell(row, 2, tview.NewTableCell(e.Mode.String()).SetTextColor(tcell.ColorLightGreen))
+		b.table.SetCell(row, 3, tview.NewTableCell(common.ParseDateToString(e.ModTime.Unix())).SetTextColor(tcell.ColorLightGreen))
+		row++
+	}
+
+	status := " Enter: open, Backspace: parent, v: view, d: download, u: upload, r: refresh, q: close"
+	if b.readOnly {
+		status = " Enter: open, Backspace: parent, v: view, d: download, r: refresh, q: close"
+	}
+	switch {
+	case b.loading:
+		status = fmt.Sprintf(" loading %s...", tview.Escape(dir))
+	case b.err != nil:
+		status = fmt.Sprintf(" [red]%s[-]", tview.Escape(b.err.Error()))
+	}
+	b.status.SetText(status)
+}
+
+// view read the text file in background, and show it
+func (b *fileBrowser) view(g *Gui, file string) {
+	b.cancel()
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.loading = false
+	b.status.SetText(fmt.Sprintf(" loading %s...", tview.Escape(file)))
+
+	go func() {
+		data, truncated, err := g.docker.ReadFile(ctx, b.id, b.containerPath(file), fileViewLimit)
+		if ctx.Err() != nil {
+			return
+		}
+
+		g.app.QueueUpdateDraw(func() {
+			b.showFile(g, file, data, truncated, err)
+		})
+	}()
+}
+
+// showFile show the text file read by view
+func (b *fileBrowser) showFile(g *Gui, file string, data []byte, truncated bool, err error) {
+	if err != nil {
+		common.Logger.Errorf("cannot read container file %s", err)
+		b.err = err
+		b.render(b.dir)
+		return
+	}
+
+	if bytes.IndexByte(data, 0) >= 0 {
+		b.err = fmt.Errorf("%s is a binary file, please download it", file)
+		b.render(b.dir)
+		return
+	}
+
+	b.err = nil
+	b.render(b.dir)
+
+	title := " " + file + " "
+	if truncated {
+		title = fmt.Sprintf(" %s (first %s) ", file, common.ParseBytesToString(uint64(fileViewLimit)))
+	}
+
+	viewName := "fileContent"
+	text := tview.NewTextView().SetText(string(data))
+	text.SetBorder(true).SetTitle(tview.Escape(title)).SetTitleAlign(tview.AlignLeft)
+	text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+			g.pages.RemovePage(viewName).SwitchToPage(b.name())
+			g.app.SetFocus(b.table)
+			return nil
+		}
+		return event
+	})
+
+	g.pages.AddAndSwitchToPage(viewName, text, true)
+}
+
+// showPopup display p over the file list
+func (b *fileBrowser) showPopup(g *Gui, name string, p tview.Primitive, width, height int) {
+	g.pages.AddAndSwitchToPage(name, g.modal(p, width, height), true).ShowPage(b.name())
+}
+
+func (b *fileBrowser) closePopup(g *Gui, name string) {
+	g.pages.RemovePage(name).SwitchToPage(b.name())
+	g.app.SetFocus(b.table)
+}
+
+func (b *fileBrowser) downloadForm(g *Gui) {
+	src := b.selectedPath()
+	wd, _ := os.Getwd()
+
+	viewName := "fileDownload"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Download " + tview.Escape(src))
+	form.AddInputField("Host path", filepath.Join(wd, path.Base(src)), inputWidth, nil, nil).
+		AddButton("Download", func() {
+			dst := form.GetFormItemByLabel("Host path").(*tview.InputField).GetText()
+			b.closePopup(g, viewName)
+			b.download(g, src, dst)
+		}).
+		AddButton("Cancel", func() {
+			b.closePopup(g, viewName)
+		})
+
+	b.showPopup(g, viewName, form, 80, 7)
+}
+
+func (b *fileBrowser) download(g *Gui, src, dst string) {
+	id, containerPath := b.id, b.containerPath(src)
+	g.startProgressTask(fmt.Sprintf("download %s:%s", b.label, src), func(ctx context.Context, progress func(string)) error {
+		err := g.docker.DownloadFromContainer(ctx, id, containerPath, dst, func(n int64) {
+			progress(common.ParseBytesToString(uint64(n)))
+		})
+		if err != nil {
+			common.Logger.Errorf("cannot download container file %s", err)
+		}
+		return err
+	})
+}
+
+func (b *fileBrowser) uploadForm(g *Gui) {
+	viewName := "fileUpload"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Upload")
+	form.AddInputField("Host path", "", inputWidth, nil, nil).
+		AddInputField("Container dir", b.dir, inputWidth, nil, nil).
+		AddButton("Upload", func() {
+			src := form.GetFormItemByLabel("Host path").(*tview.InputField).GetText()
+			dstDir := form.GetFormItemByLabel("Container dir").(*tview.InputField).GetText()
+			b.closePopup(g, viewName)
+			b.upload(g, src, dstDir)
+		}).
+		AddButton("Cancel", func() {
+			b.closePopup(g, viewName)
+		})
+
+	b.showPopup(g, viewName, form, 80, 9)
+}
+
+func (b *fileBrowser) upload(g *Gui, src, dstDir string) {
+	id, containerDir := b.id, b.containerPath(dstDir)
+	g.startProgressTask(fmt.Sprintf("upload %s to %s:%s", src, b.label, dstDir), func(ctx context.Context, progress func(string)) error {
+		err := g.docker.UploadToContainer(ctx, id, src, containerDir, func(n int64) {
+			progress(common.ParseBytesToString(uint64(n)))
+		})
+		if err != nil {
+			common.Logger.Errorf("cannot upload file to container %s", err)
+			return err
+		}
+
+		g.app.QueueUpdateDraw(func() {
+			if b.dir == path.Clean(dstDir) {
+				b.open(g, b.dir)
+			}
+		})
+		return nil
+	})
+}
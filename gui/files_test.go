@@ -0,0 +1,60 @@
+package gui
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/docker"
+)
+
+func TestFileBrowserRenderBeforeLoad(t *testing.T) {
+	b := &fileBrowser{
+		table:   tview.NewTable().SetSelectable(true, false),
+		status:  tview.NewTextView(),
+		label:   "web",
+		root:    "/",
+		loading: true,
+	}
+	b.render("/")
+
+	if rows := b.table.GetRowCount(); rows != 1 {
+		t.Errorf("Expected only the header before the first load. Got %d rows.", rows)
+	}
+}
+
+func TestFileBrowserSelected(t *testing.T) {
+	b := &fileBrowser{
+		table:  tview.NewTable().SetSelectable(true, false),
+		status: tview.NewTextView(),
+		label:  "web",
+		root:   "/",
+		dir:    "/etc",
+		entries: []docker.FileEntry{
+			{Name: "nginx", Mode: os.ModeDir | 0755},
+			{Name: "hosts", Mode: 0644},
+		},
+	}
+	b.render(b.dir)
+
+	b.table.Select(1, 0)
+	if e := b.selected(); e != nil {
+		t.Errorf("Expected .. to be selected. Got %v.", e)
+	}
+	if got := b.selectedPath(); got != "/etc" {
+		t.Errorf("Expected /etc. Got %s.", got)
+	}
+
+	b.table.Select(3, 0)
+	if got := b.selectedPath(); got != "/etc/hosts" {
+		t.Errorf("Expected /etc/hosts. Got %s.", got)
+	}
+
+	// root doesn't have ..
+	b.dir = "/"
+	b.render(b.dir)
+	b.table.Select(1, 0)
+	if got := b.selectedPath(); got != "/nginx" {
+		t.Errorf("Expected /nginx. Got %s.", got)
+	}
+}
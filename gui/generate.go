@@ -0,0 +1,174 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// generateKind kind of the generated text
+type generateKind int
+
+const (
+	generateRun generateKind = iota
+	generateCompose
+)
+
+type generateViewer struct {
+	*tview.Flex
+	text      *tview.TextView
+	status    *tview.TextView
+	container *container
+	spec      docker.ContainerSpec
+	kind      generateKind
+}
+
+func (v *generateViewer) name() string {
+	return "generate"
+}
+
+// content return the generated text of the current kind
+func (v *generateViewer) content() string {
+	if v.kind == generateCompose {
+		return docker.ComposeService(v.spec)
+	}
+	return docker.RunCommandLine(v.spec) + "\n"
+}
+
+// fileName return the default file name of the current kind
+func (v *generateViewer) fileName() string {
+	if v.kind == generateCompose {
+		return "docker-compose.yml"
+	}
+	return v.container.Name + ".sh"
+}
+
+func (g *Gui) showContainerGenerate() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot generate command: selected container is null")
+		return
+	}
+
+	c, err := g.docker.InspectContainer(container.ID)
+	if err != nil {
+		common.Logger.Errorf("cannot inspect container %s", err)
+		g.message(err.Error(), "OK", "containers", func() {})
+		return
+	}
+
+	// image defaults are kept when the image is already removed
+	image, err := g.docker.InspectImage(c.Image)
+	if err != nil {
+		common.Logger.Errorf("cannot inspect image %s", err)
+	}
+
+	v := &generateViewer{
+		Flex:      tview.NewFlex().SetDirection(tview.FlexRow),
+		text:      tview.NewTextView().SetScrollable(true),
+		status:    tview.NewTextView().SetDynamicColors(true),
+		container: container,
+		spec:      docker.SpecFromInspect(c, image),
+	}
+	v.text.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	v.AddItem(v.text, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+
+	v.text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			g.closeAndSwitchPanel(v.name(), "containers")
+		case event.Rune() == 'r':
+			v.kind = generateRun
+			v.render()
+		case event.Rune() == 'c':
+			v.kind = generateCompose
+			v.render()
+		case event.Rune() == 'w':
+			v.writeForm(g)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	v.render()
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+}
+
+func (v *generateViewer) render() {
+	title := " docker run of %s "
+	if v.kind == generateCompose {
+		title = " compose service of %s "
+	}
+	v.text.SetTitle(tview.Escape(fmt.Sprintf(title, v.container.Name)))
+	v.text.SetText(v.content()).ScrollToBeginning()
+	v.status.SetText(" r: docker run, c: compose, w: write to file, q: close")
+}
+
+func (v *generateViewer) writeForm(g *Gui) {
+	wd, _ := os.Getwd()
+
+	viewName := "generateWrite"
+	closeForm := func() {
+		g.pages.RemovePage(viewName).SwitchToPage(v.name())
+		g.app.SetFocus(v.text)
+	}
+
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Write to file")
+	form.AddInputField("Path", filepath.Join(wd, v.fileName()), inputWidth, nil, nil).
+		AddButton("Write", func() {
+			path := form.GetFormItemByLabel("Path").(*tview.InputField).GetText()
+			closeForm()
+			v.write(g, path)
+		}).
+		AddButton("Cancel", closeForm)
+
+	g.pages.AddAndSwitchToPage(viewName, g.modal(form, 80, 7), true).ShowPage(v.name())
+}
+
+func (v *generateViewer) write(g *Gui, path string) {
+	content := v.content()
+	mode := os.FileMode(0644)
+	if v.kind == generateRun {
+		content = "#!/bin/sh\n" + content
+		mode = 0755
+	}
+
+	g.startTask("write "+path, func(ctx context.Context) error {
+		err := writeNewFile(path, []byte(content), mode)
+		g.app.QueueUpdateDraw(func() {
+			if err != nil {
+				v.status.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+			} else {
+				v.status.SetText(tview.Escape(" wrote " + path))
+			}
+		})
+		if err != nil {
+			common.Logger.Errorf("cannot write file %s", err)
+		}
+		return err
+	})
+}
+
+// writeNewFile write data to the file which must not exist
+func writeNewFile(path string, data []byte, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,34 @@
+package gui
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteNewFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docui-generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "docker-compose.yml")
+	if err := writeNewFile(path, []byte("services:\n"), 0644); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "services:\n" {
+		t.Errorf("Expected services:. Got %s.", data)
+	}
+
+	// existing file is not overwritten
+	if err := writeNewFile(path, []byte("other"), 0644); err == nil {
+		t.Errorf("Expected error for existing file.")
+	}
+}
@@ -2,9 +2,11 @@ package gui
 
 import (
 	"context"
+	"time"
 
 	"github.com/rivo/tview"
 	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
 )
 
 type panels struct {
@@ -25,27 +27,45 @@ type state struct {
 	panels    panels
 	navigate  *navigate
 	resources resources
-	stopChans map[string]chan int
+	stats     *statsMonitor
+	psArgs    string
+	// execHistory exec commands, key is container id
+	execHistory map[string][]string
+	detachKeys  string
+	stopChans   map[string]chan int
+	terminal    *terminal
+	// policyFile cleanup policy file
+	policyFile string
+	// stopTimeout seconds of the last stop or restart, empty is the default of the container
+	stopTimeout string
+	killSignal  string
 }
 
 func newState() *state {
 	return &state{
-		stopChans: make(map[string]chan int),
+		stats:       newStatsMonitor(),
+		psArgs:      defaultPsArgs,
+		execHistory: make(map[string][]string),
+		detachKeys:  docker.DefaultDetachKeys,
+		killSignal:  "SIGKILL",
+		stopChans:   make(map[string]chan int),
 	}
 }
 
 // Gui have all panels
 type Gui struct {
-	app   *tview.Application
-	pages *tview.Pages
-	state *state
+	app    *tview.Application
+	pages  *tview.Pages
+	state  *state
+	docker docker.Engine
 }
 
 // New create new gui
-func New() *Gui {
+func New(engine docker.Engine) *Gui {
 	return &Gui{
-		app:   tview.NewApplication(),
-		state: newState(),
+		app:    tview.NewApplication(),
+		state:  newState(),
+		docker: engine,
 	}
 }
 
@@ -101,9 +121,13 @@ LOOP:
 		select {
 		case task := <-g.taskPanel().tasks:
 			go func() {
-				if err := task.Func(task.Ctx); err != nil {
+				err := task.Func(task.Ctx)
+				switch {
+				case task.Ctx.Err() != nil:
+					task.Status = cancel
+				case err != nil:
 					task.Status = err.Error()
-				} else {
+				default:
 					task.Status = success
 				}
 				g.updateTask()
@@ -116,25 +140,41 @@ LOOP:
 }
 
 func (g *Gui) startTask(taskName string, f func(ctx context.Context) error) {
+	g.startProgressTask(taskName, func(ctx context.Context, progress func(string)) error {
+		return f(ctx)
+	})
+}
+
+// startProgressTask start task which can report its progress to the tasks panel
+func (g *Gui) startProgressTask(taskName string, f func(ctx context.Context, progress func(string)) error) *task {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	task := &task{
 		Name:    taskName,
 		Status:  executing,
 		Created: common.DateNow(),
-		Func:    f,
 		Ctx:     ctx,
 		Cancel:  cancel,
 	}
 
+	task.Func = func(ctx context.Context) error {
+		return f(ctx, func(progress string) {
+			g.updateTaskProgress(task, progress)
+		})
+	}
+
 	g.state.resources.tasks = append(g.state.resources.tasks, task)
 	g.updateTask()
 	g.taskPanel().tasks <- task
+	return task
 }
 
 func (g *Gui) cancelTask() {
 	taskPanel := g.taskPanel()
 	row, _ := taskPanel.GetSelection()
+	if row < 1 || row > len(g.state.resources.tasks) {
+		return
+	}
 
 	task := g.state.resources.tasks[row-1]
 	if task.Status == executing {
@@ -150,13 +190,21 @@ func (g *Gui) updateTask() {
 	})
 }
 
+func (g *Gui) updateTaskProgress(task *task, progress string) {
+	task.setProgress(progress)
+	go g.app.QueueUpdateDraw(func() {
+		task.applyProgress()
+		g.taskPanel().setEntries(g)
+	})
+}
+
 func (g *Gui) initPanels() {
 	tasks := newTasks(g)
 	images := newImages(g)
 	containers := newContainers(g)
 	volumes := newVolumes(g)
 	networks := newNetworks(g)
-	info := newInfo()
+	info := newInfo(g)
 	navi := newNavigate()
 
 	g.state.panels.panel = append(g.state.panels.panel, tasks)
@@ -183,13 +231,22 @@ func (g *Gui) initPanels() {
 }
 
 func (g *Gui) startMonitoring() {
-	stop := make(chan int, 1)
+	// all monitors share the stop channel, and it is closed to stop them
+	stop := make(chan int)
 	g.state.stopChans["task"] = stop
+	g.state.stopChans["event"] = stop
 	g.state.stopChans["image"] = stop
 	g.state.stopChans["volume"] = stop
 	g.state.stopChans["network"] = stop
 	g.state.stopChans["container"] = stop
+	g.state.stopChans["stats"] = stop
 	go g.monitoringTask()
+	go g.monitoringEvents()
+	go g.monitoringStats()
+}
+
+// startPolling start to refresh panels periodically, it is used when docker events are unavailable
+func (g *Gui) startPolling() {
 	go g.imagePanel().monitoringImages(g)
 	go g.networkPanel().monitoringNetworks(g)
 	go g.volumePanel().monitoringVolumes(g)
@@ -197,17 +254,16 @@ func (g *Gui) startMonitoring() {
 }
 
 func (g *Gui) stopMonitoring() {
-	g.state.stopChans["task"] <- 1
-	g.state.stopChans["image"] <- 1
-	g.state.stopChans["volume"] <- 1
-	g.state.stopChans["network"] <- 1
-	g.state.stopChans["container"] <- 1
+	close(g.state.stopChans["task"])
+	g.state.stats.stopAll()
 }
 
 // Start start application
 func (g *Gui) Start() error {
 	g.initPanels()
 	g.startMonitoring()
+	// helpers are left behind when docui crashed while browsing a volume
+	go g.removeVolumeHelpers()
 	if err := g.app.Run(); err != nil {
 		g.app.Stop()
 		return err
@@ -219,10 +275,19 @@ func (g *Gui) Start() error {
 // Stop stop application
 func (g *Gui) Stop() error {
 	g.stopMonitoring()
+	g.removeVolumeHelpers()
 	g.app.Stop()
 	return nil
 }
 
+func (g *Gui) removeVolumeHelpers() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := g.docker.RemoveVolumeHelpers(ctx); err != nil {
+		common.Logger.Errorf("cannot remove volume helper containers %s", err)
+	}
+}
+
 func (g *Gui) selectedImage() *image {
 	row, _ := g.imagePanel().GetSelection()
 	if len(g.state.resources.images) == 0 {
@@ -247,6 +312,26 @@ func (g *Gui) selectedContainer() *container {
 	return g.state.resources.containers[row-1]
 }
 
+// selectedContainers return marked containers, or the selected container when nothing is marked
+func (g *Gui) selectedContainers() []*container {
+	marked := g.containerPanel().marked
+
+	var containers []*container
+	for _, c := range g.state.resources.containers {
+		if marked[c.ID] {
+			containers = append(containers, c)
+		}
+	}
+
+	if len(containers) == 0 {
+		if c := g.selectedContainer(); c != nil {
+			containers = append(containers, c)
+		}
+	}
+
+	return containers
+}
+
 func (g *Gui) selectedVolume() *volume {
 	row, _ := g.volumePanel().GetSelection()
 	if len(g.state.resources.volumes) == 0 {
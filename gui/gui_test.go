@@ -0,0 +1,171 @@
+package gui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/gdamore/tcell/v2"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+func newTestFake() *docker.Fake {
+	fake := docker.NewFake()
+	fake.ContainerItems = []types.Container{
+		{ID: "1111111111111111", Names: []string{"/web"}, Image: "nginx:latest", State: "running", Status: "Up 2 hours"},
+		{ID: "2222222222222222", Names: []string{"/db"}, Image: "postgres:11", State: "exited", Status: "Exited (0)"},
+	}
+	fake.ImageItems = []types.ImageSummary{
+		{ID: "sha256:aaaaaaaaaaaaaaaaaaaa", RepoTags: []string{"nginx:latest"}},
+		{ID: "sha256:bbbbbbbbbbbbbbbbbbbb", RepoTags: []string{"postgres:11", "postgres:latest"}},
+	}
+	fake.VolumeItems = []*types.Volume{
+		{Name: "pgdata", Driver: "local"},
+		{Name: "cache", Driver: "local"},
+	}
+	fake.NetworkItems = []types.NetworkResource{
+		{ID: "cccccccccccccccc", Name: "bridge", Driver: "bridge", Scope: "local"},
+	}
+	return fake
+}
+
+func newTestGui(t *testing.T, engine docker.Engine) *Gui {
+	t.Helper()
+	common.NewLogger("error", "")
+	g := New(engine)
+	g.initPanels()
+	return g
+}
+
+func TestPanelEntries(t *testing.T) {
+	g := newTestGui(t, newTestFake())
+
+	if got := len(g.state.resources.containers); got != 2 {
+		t.Errorf("Expected 2 containers. Got %d.", got)
+	}
+	if got := len(g.state.resources.images); got != 3 {
+		t.Errorf("Expected 3 images. Got %d.", got)
+	}
+	if got := g.state.resources.volumes[0].Name; got != "cache" {
+		t.Errorf("Expected sorted volumes. Got first %s.", got)
+	}
+	if got := len(g.state.resources.networks); got != 1 {
+		t.Errorf("Expected 1 network. Got %d.", got)
+	}
+}
+
+func TestContainersFilter(t *testing.T) {
+	g := newTestGui(t, newTestFake())
+
+	c := g.containerPanel()
+	c.setFilterWord("we")
+	c.setEntries(g)
+
+	if got := len(g.state.resources.containers); got != 1 {
+		t.Fatalf("Expected 1 container. Got %d.", got)
+	}
+	if got := g.state.resources.containers[0].Name; got != "web" {
+		t.Errorf("Expected container web. Got %s.", got)
+	}
+}
+
+func TestEntriesKeepStateOnError(t *testing.T) {
+	fake := newTestFake()
+	g := newTestGui(t, fake)
+
+	fake.SetError("Images", errors.New("daemon is down"))
+	g.imagePanel().setEntries(g)
+
+	if got := len(g.state.resources.images); got != 3 {
+		t.Errorf("Expected images to be kept. Got %d.", got)
+	}
+}
+
+func TestFakeContainerLifecycle(t *testing.T) {
+	fake := newTestFake()
+
+	if err := fake.StopContainer("web", nil); err != nil {
+		t.Fatal(err)
+	}
+	running, err := fake.Containers(types.ContainerListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(running) != 0 {
+		t.Errorf("Expected no running containers. Got %d.", len(running))
+	}
+
+	fake.SetError("RemoveContainer", errors.New("conflict"))
+	if err := fake.RemoveContainer("db"); err == nil {
+		t.Error("Expected error. Got nil.")
+	}
+	if got := len(fake.CallsOf("RemoveContainer")); got != 1 {
+		t.Errorf("Expected 1 call. Got %d.", got)
+	}
+}
+
+func TestNavigateFitsBar(t *testing.T) {
+	n := newNavigate()
+	for panel := range panelKeybindings {
+		n.update(panel)
+		lines := strings.Split(strings.TrimRight(n.GetText(false), "\n"), "\n")
+		if len(lines) != 2 {
+			t.Errorf("Expected 2 lines for %s. Got %q.", panel, lines)
+		}
+		for _, line := range lines {
+			if len(line) > 80 {
+				t.Errorf("Expected the line of %s fits 80 columns. Got %d: %s", panel, len(line), line)
+			}
+		}
+	}
+}
+
+func TestShowHelp(t *testing.T) {
+	g := newTestGui(t, newTestFake())
+	g.switchPanel("containers")
+
+	g.showHelp()
+	if !g.pages.HasPage("help") {
+		t.Fatal("Expected help page.")
+	}
+	_, page := g.pages.GetFrontPage()
+	v := page.(*helpViewer)
+
+	var keys []string
+	for row := 0; row < v.GetRowCount(); row++ {
+		if cell := v.GetCell(row, 0); cell != nil {
+			keys = append(keys, strings.TrimSpace(cell.Text))
+		}
+	}
+	for _, want := range []string{"containers", "E", "x", "all panels", "U", "?"} {
+		found := false
+		for _, key := range keys {
+			if key == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected %s in the help. Got %v.", want, keys)
+		}
+	}
+
+	v.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone))
+	if g.pages.HasPage("help") {
+		t.Error("Expected help page is closed.")
+	}
+}
+
+func TestTaskProgressLatest(t *testing.T) {
+	task := &task{}
+	task.setProgress("50%")
+	task.setProgress("100%")
+
+	// draws of both updates run after the last update, in any order
+	task.applyProgress()
+	task.applyProgress()
+	if task.Progress != "100%" {
+		t.Errorf("Expected 100%%. Got %s.", task.Progress)
+	}
+}
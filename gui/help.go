@@ -0,0 +1,51 @@
+package gui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type helpViewer struct {
+	*tview.Table
+	// panel panel to switch to when closed
+	panel string
+}
+
+func (v *helpViewer) name() string {
+	return "help"
+}
+
+// showHelp show all keys of the current panel and the global keys
+func (g *Gui) showHelp() {
+	v := &helpViewer{
+		Table: tview.NewTable(),
+		panel: g.currentPanel().name(),
+	}
+	v.SetBorder(true).SetTitle(" help ").SetTitleAlign(tview.AlignLeft)
+
+	row := 0
+	section := func(title string, keys []keybinding) {
+		v.SetCell(row, 0, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow))
+		row++
+		for _, k := range keys {
+			v.SetCell(row, 0, tview.NewTableCell(" "+k.key).SetTextColor(tcell.ColorLightGreen))
+			v.SetCell(row, 1, tview.NewTableCell(k.operation))
+			row++
+		}
+		row++
+	}
+	section(v.panel, panelKeybindings[v.panel])
+	section("all panels", globalKeybindings)
+
+	v.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q' || event.Rune() == '?':
+			g.closeAndSwitchPanel(v.name(), v.panel)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+}
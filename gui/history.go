@@ -0,0 +1,202 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	imagetypes "github.com/docker/docker/api/types/image"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// largestLayerCount number of highlighted layers
+var largestLayerCount = 3
+
+// largestLayers return indexes of the n largest layers which have size
+func largestLayers(history []imagetypes.HistoryResponseItem, n int) map[int]bool {
+	indexes := make([]int, len(history))
+	for i := range history {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return history[indexes[a]].Size > history[indexes[b]].Size
+	})
+
+	largest := make(map[int]bool)
+	for _, i := range indexes {
+		if len(largest) == n || history[i].Size == 0 {
+			break
+		}
+		largest[i] = true
+	}
+	return largest
+}
+
+type historyViewer struct {
+	*tview.Flex
+	table   *tview.Table
+	status  *tview.TextView
+	image   *image
+	history []imagetypes.HistoryResponseItem
+}
+
+func (v *historyViewer) name() string {
+	return "history"
+}
+
+func (g *Gui) showImageHistory() {
+	img := g.selectedImage()
+	if img == nil {
+		common.Logger.Errorf("cannot show image history: selected image is null")
+		return
+	}
+
+	history, err := g.docker.HistoryImage(context.Background(), img.ID)
+	if err != nil {
+		common.Logger.Errorf("cannot get image history %s", err)
+		g.message(err.Error(), "OK", "images", func() {})
+		return
+	}
+
+	v := &historyViewer{
+		Flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		table:   tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		status:  tview.NewTextView().SetDynamicColors(true),
+		image:   img,
+		history: history,
+	}
+	v.table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	v.table.SetTitle(tview.Escape(fmt.Sprintf(" layers of %s:%s ", img.Repo, img.Tag)))
+	v.AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+
+	v.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc || event.Rune() == 'q':
+			g.closeAndSwitchPanel(v.name(), "images")
+		case event.Key() == tcell.KeyEnter:
+			v.showLayer(g)
+		case event.Rune() == 'f':
+			v.showDockerfile(g)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	v.render()
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+}
+
+func (v *historyViewer) render() {
+	headers := []string{"#", "Created by", "Size", "%", "Age"}
+	for i, header := range headers {
+		v.table.SetCell(0, i, &tview.TableCell{
+			Text:            header,
+			NotSelectable:   true,
+			Align:           tview.AlignLeft,
+			Color:           tcell.ColorWhite,
+			BackgroundColor: tcell.ColorDefault,
+			Attributes:      tcell.AttrBold,
+		})
+	}
+
+	var total int64
+	for _, layer := range v.history {
+		total += layer.Size
+	}
+
+	largest := largestLayers(v.history, largestLayerCount)
+
+	// the oldest layer is shown first like Dockerfile
+	for row, i := 1, len(v.history)-1; i >= 0; row, i = row+1, i-1 {
+		layer := v.history[i]
+
+		createdBy := docker.Instruction(layer.CreatedBy)
+		if createdBy == "" {
+			createdBy = "<missing>"
+		}
+
+		var percent float64
+		if total > 0 {
+			percent = float64(layer.Size) / float64(total) * 100
+		}
+
+		color := tcell.ColorLightGreen
+		if largest[i] {
+			color = tcell.ColorOrangeRed
+		}
+
+		cells := []string{
+			fmt.Sprintf("%d", row),
+			createdBy,
+			common.ParseBytesToString(uint64(layer.Size)),
+			fmt.Sprintf("%.1f", percent),
+			common.ParseAgeToString(layer.Created),
+		}
+		for j, text := range cells {
+			cell := tview.NewTableCell(text).SetTextColor(color)
+			if j == 1 {
+				cell.SetMaxWidth(1).SetExpansion(1)
+			}
+			v.table.SetCell(row, j, cell)
+		}
+	}
+
+	v.status.SetText(fmt.Sprintf(" %d layers, %s | [orangered]largest layers[-] | Enter: layer detail, f: Dockerfile, q: close",
+		len(v.history), common.ParseBytesToString(uint64(total))))
+}
+
+// selectedLayer return the selected layer
+func (v *historyViewer) selectedLayer() *imagetypes.HistoryResponseItem {
+	row, _ := v.table.GetSelection()
+	i := len(v.history) - row
+	if row < 1 || i < 0 {
+		return nil
+	}
+	return &v.history[i]
+}
+
+func (v *historyViewer) showText(g *Gui, viewName, title, text string) {
+	view := tview.NewTextView().SetText(text)
+	view.SetBorder(true).SetTitle(tview.Escape(title)).SetTitleAlign(tview.AlignLeft)
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+			g.pages.RemovePage(viewName).SwitchToPage(v.name())
+			g.app.SetFocus(v.table)
+			return nil
+		}
+		return event
+	})
+
+	g.pages.AddAndSwitchToPage(viewName, view, true)
+}
+
+func (v *historyViewer) showLayer(g *Gui) {
+	layer := v.selectedLayer()
+	if layer == nil {
+		return
+	}
+
+	text := strings.Join([]string{
+		"ID:         " + layer.ID,
+		"Created:    " + common.ParseDateToString(layer.Created),
+		"Size:       " + common.ParseBytesToString(uint64(layer.Size)),
+		"Tags:       " + strings.Join(layer.Tags, ", "),
+		"Comment:    " + layer.Comment,
+		"Created by:",
+		"",
+		layer.CreatedBy,
+	}, "\n")
+
+	v.showText(g, "layer", " layer ", text)
+}
+
+func (v *historyViewer) showDockerfile(g *Gui) {
+	v.showText(g, "dockerfile", fmt.Sprintf(" approximate Dockerfile of %s:%s ", v.image.Repo, v.image.Tag), docker.Dockerfile(v.history))
+}
@@ -0,0 +1,28 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	imagetypes "github.com/docker/docker/api/types/image"
+)
+
+func TestLargestLayers(t *testing.T) {
+	history := []imagetypes.HistoryResponseItem{
+		{Size: 10},
+		{Size: 0},
+		{Size: 300},
+		{Size: 20},
+		{Size: 5},
+	}
+
+	want := map[int]bool{2: true, 3: true, 0: true}
+	if got := largestLayers(history, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v. Got %v.", want, got)
+	}
+
+	// empty layers are not highlighted
+	if got := largestLayers(history[1:2], 3); len(got) != 0 {
+		t.Errorf("Expected no layers. Got %v.", got)
+	}
+}
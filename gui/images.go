@@ -8,7 +8,6 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/skanehira/docui/common"
-	"github.com/skanehira/docui/docker"
 )
 
 type image struct {
@@ -63,6 +62,12 @@ func (i *images) setKeybinding(g *Gui) {
 			g.importImageForm()
 		case 's':
 			g.saveImageForm()
+		case 'H':
+			g.showImageHistory()
+		case 'b':
+			g.buildImageForm()
+		case 'r':
+			g.runCommandForm()
 		case 'f':
 			newSearchInputField(g)
 		}
@@ -72,7 +77,7 @@ func (i *images) setKeybinding(g *Gui) {
 }
 
 func (i *images) entries(g *Gui) {
-	images, err := docker.Client.Images(types.ImageListOptions{})
+	images, err := g.docker.Images(types.ImageListOptions{})
 	if err != nil {
 		return
 	}
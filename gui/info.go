@@ -7,7 +7,6 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
-	"github.com/skanehira/docui/docker"
 )
 
 type info struct {
@@ -54,14 +53,14 @@ func newHostInfo() *hostInfo {
 	}
 }
 
-func newDockerInfo() *dockerInfo {
-	info, err := docker.Client.Info(context.TODO())
+func newDockerInfo(g *Gui) *dockerInfo {
+	info, err := g.docker.Info(context.TODO())
 	if err != nil {
 		return nil
 	}
 
 	var apiVersion string
-	if v, err := docker.Client.ServerVersion(context.TODO()); err != nil {
+	if v, err := g.docker.ServerVersion(context.TODO()); err != nil {
 		apiVersion = ""
 	} else {
 		apiVersion = v.APIVersion
@@ -74,17 +73,17 @@ func newDockerInfo() *dockerInfo {
 		KernelVersion: info.KernelVersion,
 		OSType:        info.OSType,
 		Architecture:  info.Architecture,
-		Endpoint:      docker.Client.DaemonHost(),
+		Endpoint:      g.docker.DaemonHost(),
 		Containers:    info.Containers,
 		Images:        info.Images,
 		MemTotal:      fmt.Sprintf("%dMB", info.MemTotal/1024/1024),
 	}
 }
 
-func newInfo() *info {
+func newInfo(g *Gui) *info {
 	i := &info{
 		TextView: tview.NewTextView(),
-		Docker:   newDockerInfo(),
+		Docker:   newDockerInfo(g),
 		Host:     newHostInfo(),
 		Docui:    newDocuiInfo(),
 	}
@@ -4,13 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"os"
-	"os/signal"
 	"path/filepath"
 
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/skanehira/docui/common"
@@ -29,6 +25,10 @@ func (g *Gui) setGlobalKeybinding(event *tcell.EventKey) {
 		g.Stop()
 	case '/':
 		g.filter()
+	case 'U':
+		g.showDiskUsage()
+	case '?':
+		g.showHelp()
 	}
 
 	switch event.Key() {
@@ -99,87 +99,6 @@ func (g *Gui) prevPanel() {
 	g.switchPanel(g.state.panels.panel[idx].name())
 }
 
-func (g *Gui) createContainerForm() {
-	selectedImage := g.selectedImage()
-	if selectedImage == nil {
-		common.Logger.Error("please input image")
-		return
-	}
-
-	image := fmt.Sprintf("%s:%s", selectedImage.Repo, selectedImage.Tag)
-
-	form := tview.NewForm()
-	form.SetBorder(true)
-	form.SetTitle("Create container")
-	form.SetTitleAlign(tview.AlignLeft)
-
-	form.AddInputField("Name", "", inputWidth, nil, nil).
-		AddInputField("HostIP", "", inputWidth, nil, nil).
-		AddInputField("HostPort", "", inputWidth, nil, nil).
-		AddInputField("Port", "", inputWidth, nil, nil).
-		AddDropDown("VolumeType", []string{"bind", "volume"}, 0, func(option string, optionIndex int) {}).
-		AddInputField("HostVolume", "", inputWidth, nil, nil).
-		AddInputField("Volume", "", inputWidth, nil, nil).
-		AddInputField("Image", image, inputWidth, nil, nil).
-		AddInputField("User", "", inputWidth, nil, nil).
-		AddCheckbox("Attach", false, nil).
-		AddInputField("Env", "", inputWidth, nil, nil).
-		AddInputField("Cmd", "", inputWidth, nil, nil).
-		AddButton("Create", func() {
-			g.createContainer(form, image)
-		}).
-		AddButton("Cancel", func() {
-			g.closeAndSwitchPanel("form", "images")
-		})
-
-	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 29), true).ShowPage("main")
-}
-
-func (g *Gui) createContainer(form *tview.Form, image string) {
-	g.startTask("create container "+image, func(ctx context.Context) error {
-		inputLabels := []string{
-			"Name",
-			"HostIP",
-			"Port",
-			"HostVolume",
-			"Volume",
-			"Image",
-			"User",
-		}
-
-		var data = make(map[string]string)
-
-		for _, label := range inputLabels {
-			data[label] = form.GetFormItemByLabel(label).(*tview.InputField).GetText()
-		}
-
-		_, volumeType := form.GetFormItemByLabel("VolumeType").(*tview.DropDown).
-			GetCurrentOption()
-		data["VolumeType"] = volumeType
-
-		isAttach := form.GetFormItemByLabel("Attach").(*tview.Checkbox).IsChecked()
-
-		options, err := docker.Client.NewContainerOptions(data, isAttach)
-		if err != nil {
-			common.Logger.Errorf("cannot create container %s", err)
-			return err
-		}
-
-		err = docker.Client.CreateContainer(options)
-		if err != nil {
-			common.Logger.Errorf("cannot create container %s", err)
-			return err
-		}
-
-		g.closeAndSwitchPanel("form", "images")
-		go g.app.QueueUpdateDraw(func() {
-			g.containerPanel().setEntries(g)
-		})
-
-		return nil
-	})
-}
-
 func (g *Gui) pullImageForm() {
 	form := tview.NewForm()
 	form.SetBorder(true)
@@ -198,9 +117,11 @@ func (g *Gui) pullImageForm() {
 }
 
 func (g *Gui) pullImage(image, closePanel, switchPanel string) {
-	g.startTask("Pull image "+image, func(ctx context.Context) error {
+	g.startProgressTask("Pull image "+image, func(ctx context.Context, progress func(string)) error {
 		g.closeAndSwitchPanel(closePanel, switchPanel)
-		err := docker.Client.PullImage(image)
+		err := g.docker.PullImage(ctx, image, func(p docker.PullProgress) {
+			progress(p.String())
+		})
 		if err != nil {
 			common.Logger.Errorf("cannot pull an image %s", err)
 			return err
@@ -231,7 +152,7 @@ func (g *Gui) displayInspect(data, page string) {
 func (g *Gui) inspectImage() {
 	image := g.selectedImage()
 
-	inspect, err := docker.Client.InspectImage(image.ID)
+	inspect, err := g.docker.InspectImage(image.ID)
 	if err != nil {
 		common.Logger.Errorf("cannot inspect image %s", err)
 		return
@@ -267,7 +188,7 @@ func (g *Gui) renameContainer(newName, closePanel, switchPanel string) {
 			return err
 		}
 
-		err := docker.Client.RenameContainer(oldContainer.ID, newName)
+		err := g.docker.RenameContainer(oldContainer.ID, newName)
 		if err != nil {
 			common.Logger.Errorf("cannot create container %s", err)
 			return err
@@ -282,7 +203,7 @@ func (g *Gui) renameContainer(newName, closePanel, switchPanel string) {
 func (g *Gui) inspectContainer() {
 	container := g.selectedContainer()
 
-	inspect, err := docker.Client.InspectContainer(container.ID)
+	inspect, err := g.docker.InspectContainer(container.ID)
 	if err != nil {
 		common.Logger.Errorf("cannot inspect container %s", err)
 		return
@@ -294,7 +215,7 @@ func (g *Gui) inspectContainer() {
 func (g *Gui) inspectVolume() {
 	volume := g.selectedVolume()
 
-	inspect, err := docker.Client.InspectVolume(volume.Name)
+	inspect, err := g.docker.InspectVolume(volume.Name)
 	if err != nil {
 		common.Logger.Errorf("cannot inspect volume %s", err)
 		return
@@ -306,7 +227,7 @@ func (g *Gui) inspectVolume() {
 func (g *Gui) inspectNetwork() {
 	network := g.selectedNetwork()
 
-	inspect, err := docker.Client.InspectNetwork(network.ID)
+	inspect, err := g.docker.InspectNetwork(network.ID)
 	if err != nil {
 		common.Logger.Errorf("cannot inspect network %s", err)
 		return
@@ -320,7 +241,7 @@ func (g *Gui) removeImage() {
 
 	g.confirm("Do you want to remove the image?", "Done", "images", func() {
 		g.startTask(fmt.Sprintf("remove image %s:%s", image.Repo, image.Tag), func(ctx context.Context) error {
-			if err := docker.Client.RemoveImage(image.ID); err != nil {
+			if err := g.docker.RemoveImage(image.ID); err != nil {
 				common.Logger.Errorf("cannot remove the image %s", err)
 				return err
 			}
@@ -335,7 +256,7 @@ func (g *Gui) removeContainer() {
 
 	g.confirm("Do you want to remove the container?", "Done", "containers", func() {
 		g.startTask(fmt.Sprintf("remove container %s", container.Name), func(ctx context.Context) error {
-			if err := docker.Client.RemoveContainer(container.ID); err != nil {
+			if err := g.docker.RemoveContainer(container.ID); err != nil {
 				common.Logger.Errorf("cannot remove the container %s", err)
 				return err
 			}
@@ -350,7 +271,7 @@ func (g *Gui) removeVolume() {
 
 	g.confirm("Do you want to remove the volume?", "Done", "volumes", func() {
 		g.startTask(fmt.Sprintf("remove volume %s", volume.Name), func(ctx context.Context) error {
-			if err := docker.Client.RemoveVolume(volume.Name); err != nil {
+			if err := g.docker.RemoveVolume(volume.Name); err != nil {
 				common.Logger.Errorf("cannot remove the volume %s", err)
 				return err
 			}
@@ -365,7 +286,7 @@ func (g *Gui) removeNetwork() {
 
 	g.confirm("Do you want to remove the network?", "Done", "networks", func() {
 		g.startTask(fmt.Sprintf("remove network %s", network.Name), func(ctx context.Context) error {
-			if err := docker.Client.RemoveNetwork(network.ID); err != nil {
+			if err := g.docker.RemoveNetwork(network.ID); err != nil {
 				common.Logger.Errorf("cannot remove the network %s", err)
 				return err
 			}
@@ -375,35 +296,6 @@ func (g *Gui) removeNetwork() {
 	})
 }
 
-func (g *Gui) startContainer() {
-	container := g.selectedContainer()
-
-	g.startTask(fmt.Sprintf("start container %s", container.Name), func(ctx context.Context) error {
-		if err := docker.Client.StartContainer(container.ID); err != nil {
-			common.Logger.Errorf("cannot start container %s", err)
-			return err
-		}
-
-		g.containerPanel().updateEntries(g)
-		return nil
-	})
-}
-
-func (g *Gui) stopContainer() {
-	container := g.selectedContainer()
-
-	g.startTask(fmt.Sprintf("stop container %s", container.Name), func(ctx context.Context) error {
-
-		if err := docker.Client.StopContainer(container.ID); err != nil {
-			common.Logger.Errorf("cannot stop container %s", err)
-			return err
-		}
-
-		g.containerPanel().updateEntries(g)
-		return nil
-	})
-}
-
 func (g *Gui) exportContainerForm() {
 	inputWidth := 70
 
@@ -430,7 +322,7 @@ func (g *Gui) exportContainerForm() {
 func (g *Gui) exportContainer(path, container string) {
 	g.startTask("export container "+container, func(ctx context.Context) error {
 		g.closeAndSwitchPanel("form", "containers")
-		err := docker.Client.ExportContainer(container, path)
+		err := g.docker.ExportContainer(container, path)
 		if err != nil {
 			common.Logger.Errorf("cannot export container %s", err)
 			return err
@@ -460,7 +352,7 @@ func (g *Gui) loadImageForm() {
 func (g *Gui) loadImage(path string) {
 	g.startTask("load image "+filepath.Base(path), func(ctx context.Context) error {
 		g.closeAndSwitchPanel("form", "images")
-		if err := docker.Client.LoadImage(path); err != nil {
+		if err := g.docker.LoadImage(path); err != nil {
 			common.Logger.Errorf("cannot load image %s", err)
 			return err
 		}
@@ -495,7 +387,7 @@ func (g *Gui) importImage(file, repo, tag string) {
 	g.startTask("import image "+file, func(ctx context.Context) error {
 		g.closeAndSwitchPanel("form", "images")
 
-		if err := docker.Client.ImportImage(repo, tag, file); err != nil {
+		if err := g.docker.ImportImage(repo, tag, file); err != nil {
 			common.Logger.Errorf("cannot load image %s", err)
 			return err
 		}
@@ -532,7 +424,7 @@ func (g *Gui) saveImage(image, path string) {
 	g.startTask("save image "+image, func(ctx context.Context) error {
 		g.closeAndSwitchPanel("form", "images")
 
-		if err := docker.Client.SaveImage([]string{image}, path); err != nil {
+		if err := g.docker.SaveImage([]string{image}, path); err != nil {
 			common.Logger.Errorf("cannot save image %s", err)
 			return err
 		}
@@ -568,7 +460,7 @@ func (g *Gui) commitContainer(repo, tag, container string) {
 	g.startTask("commit container "+container, func(ctx context.Context) error {
 		g.closeAndSwitchPanel("form", "containers")
 
-		if err := docker.Client.CommitContainer(container, types.ContainerCommitOptions{Reference: repo + ":" + tag}); err != nil {
+		if err := g.docker.CommitContainer(container, types.ContainerCommitOptions{Reference: repo + ":" + tag}); err != nil {
 			common.Logger.Errorf("cannot commit container %s", err)
 			return err
 		}
@@ -578,38 +470,6 @@ func (g *Gui) commitContainer(repo, tag, container string) {
 	})
 }
 
-func (g *Gui) attachContainerForm() {
-	form := tview.NewForm()
-	form.SetBorder(true)
-	form.SetTitleAlign(tview.AlignLeft)
-	form.SetTitle("Exec container")
-	form.AddInputField("Cmd", "", inputWidth, nil, nil).
-		AddButton("Exec", func() {
-			cmd := form.GetFormItemByLabel("Cmd").(*tview.InputField).GetText()
-			g.attachContainer(g.selectedContainer().ID, cmd)
-		}).
-		AddButton("Cancel", func() {
-			g.closeAndSwitchPanel("form", "containers")
-		})
-
-	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 7), true).ShowPage("main")
-}
-
-func (g *Gui) attachContainer(container, cmd string) {
-	g.closeAndSwitchPanel("form", "containers")
-
-	if !g.app.Suspend(func() {
-		g.stopMonitoring()
-		if err := docker.Client.AttachExecContainer(container, cmd); err != nil {
-			common.Logger.Errorf("cannot attach container %s", err)
-		}
-
-		g.startMonitoring()
-	}) {
-		common.Logger.Error("cannot suspend tview")
-	}
-}
-
 func (g *Gui) createVolumeForm() {
 	form := tview.NewForm()
 	form.SetBorder(true)
@@ -643,9 +503,9 @@ func (g *Gui) createVolume(form *tview.Form) {
 	}
 
 	g.startTask("create volume "+data["Name"], func(ctx context.Context) error {
-		options := docker.Client.NewCreateVolumeOptions(data)
+		options := g.docker.NewCreateVolumeOptions(data)
 
-		if err := docker.Client.CreateVolume(options); err != nil {
+		if err := g.docker.CreateVolume(options); err != nil {
 			common.Logger.Errorf("cannot create volume %s", err)
 			return err
 		}
@@ -658,67 +518,3 @@ func (g *Gui) createVolume(form *tview.Form) {
 		return nil
 	})
 }
-
-func (g *Gui) tailContainerLog() {
-	container := g.selectedContainer()
-	if container == nil {
-		common.Logger.Errorf("cannot start tail container: selected container is null")
-		return
-	}
-
-	if !g.app.Suspend(func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
-		errCh := make(chan error)
-
-		var reader io.ReadCloser
-		var err error
-
-		go func() {
-			reader, err = docker.Client.ContainerLogStream(container.ID)
-			if err != nil {
-				common.Logger.Error(err)
-				errCh <- err
-			}
-			defer reader.Close()
-
-			_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, reader)
-			if err != nil {
-				common.Logger.Error(err)
-				errCh <- err
-			}
-			return
-		}()
-
-		select {
-		case err := <-errCh:
-			common.Logger.Error(err)
-			reader.Close()
-			return
-		case <-sigint:
-			reader.Close()
-			return
-		}
-	}) {
-		common.Logger.Error("cannot suspend tview")
-	}
-}
-
-func (g *Gui) killContainer() {
-	container := g.selectedContainer()
-	if container == nil {
-		common.Logger.Errorf("cannot kill container: selected container is null")
-		return
-	}
-
-	g.confirm("Do you want to kill the container?", "Done", "containers", func() {
-		g.startTask(fmt.Sprintf("kill container %s", container.Name), func(ctx context.Context) error {
-			if err := docker.Client.KillContainer(container.ID); err != nil {
-				common.Logger.Errorf("cannot kill the container %s", err)
-				return err
-			}
-			g.containerPanel().updateEntries(g)
-			return nil
-		})
-	})
-}
@@ -0,0 +1,214 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// containerStateColor color of the status column per container state
+func containerStateColor(state string) tcell.Color {
+	switch state {
+	case "running":
+		return tcell.ColorLightGreen
+	case "paused":
+		return tcell.ColorYellow
+	case "restarting":
+		return tcell.ColorOrange
+	case "created":
+		return tcell.ColorLightSkyBlue
+	case "exited", "dead":
+		return tcell.ColorRed
+	}
+	return tcell.ColorGray
+}
+
+// parseStopTimeout parse the timeout in seconds, empty is the default of the container
+func parseStopTimeout(text string) (*time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+
+	sec, err := strconv.Atoi(text)
+	if err != nil || sec < 0 {
+		return nil, fmt.Errorf("invalid timeout %s", text)
+	}
+	timeout := time.Duration(sec) * time.Second
+	return &timeout, nil
+}
+
+// containerNames join names of containers for titles
+func containerNames(containers []*container) string {
+	var names []string
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// containersTask start the task for each container
+func (g *Gui) containersTask(action string, containers []*container, f func(c *container) error) {
+	for _, c := range containers {
+		c := c
+		g.startTask(fmt.Sprintf("%s container %s", action, c.Name), func(ctx context.Context) error {
+			if err := f(c); err != nil {
+				common.Logger.Errorf("cannot %s container %s", action, err)
+				return err
+			}
+
+			g.containerPanel().updateEntries(g)
+			return nil
+		})
+	}
+}
+
+func (g *Gui) startContainer() {
+	containers := g.selectedContainers()
+	if len(containers) == 0 {
+		common.Logger.Errorf("cannot start container: selected container is null")
+		return
+	}
+
+	g.containersTask("start", containers, func(c *container) error {
+		return g.docker.StartContainer(c.ID)
+	})
+}
+
+func (g *Gui) pauseContainer() {
+	containers := g.selectedContainers()
+	if len(containers) == 0 {
+		common.Logger.Errorf("cannot pause container: selected container is null")
+		return
+	}
+
+	g.containersTask("pause", containers, func(c *container) error {
+		return g.docker.PauseContainer(c.ID)
+	})
+}
+
+func (g *Gui) unpauseContainer() {
+	containers := g.selectedContainers()
+	if len(containers) == 0 {
+		common.Logger.Errorf("cannot unpause container: selected container is null")
+		return
+	}
+
+	g.containersTask("unpause", containers, func(c *container) error {
+		return g.docker.UnpauseContainer(c.ID)
+	})
+}
+
+func (g *Gui) stopContainerForm() {
+	containers := g.selectedContainers()
+	if len(containers) == 0 {
+		common.Logger.Errorf("cannot stop container: selected container is null")
+		return
+	}
+
+	g.stopTimeoutForm("Stop "+containerNames(containers), "Stop", func(timeout *time.Duration) {
+		g.containersTask("stop", containers, func(c *container) error {
+			return g.docker.StopContainer(c.ID, timeout)
+		})
+	})
+}
+
+func (g *Gui) restartContainerForm() {
+	containers := g.selectedContainers()
+	if len(containers) == 0 {
+		common.Logger.Errorf("cannot restart container: selected container is null")
+		return
+	}
+
+	g.stopTimeoutForm("Restart "+containerNames(containers), "Restart", func(timeout *time.Duration) {
+		g.containersTask("restart", containers, func(c *container) error {
+			return g.docker.RestartContainer(c.ID, timeout)
+		})
+	})
+}
+
+// stopTimeoutForm input seconds to wait before the container is killed
+func (g *Gui) stopTimeoutForm(title, doneLabel string, done func(timeout *time.Duration)) {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+
+	field := tview.NewInputField().SetLabel("Timeout (sec)").
+		SetText(g.state.stopTimeout).
+		SetPlaceholder("default of the container").
+		SetFieldWidth(inputWidth)
+	form.AddFormItem(field).
+		AddButton(doneLabel, func() {
+			timeout, err := parseStopTimeout(field.GetText())
+			if err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.state.stopTimeout = strings.TrimSpace(field.GetText())
+			g.closeAndSwitchPanel("form", "containers")
+			done(timeout)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "containers")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 7), true).ShowPage("main")
+}
+
+// killContainerForm send the signal to the main process of containers
+func (g *Gui) killContainerForm() {
+	containers := g.selectedContainers()
+	if len(containers) == 0 {
+		common.Logger.Errorf("cannot kill container: selected container is null")
+		return
+	}
+
+	title := "Kill " + containerNames(containers)
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+
+	field := tview.NewInputField().SetLabel("Signal").
+		SetText(g.state.killSignal).
+		SetFieldWidth(inputWidth)
+	field.SetAutocompleteFunc(func(text string) []string {
+		var signals []string
+		prefix := strings.ToUpper(strings.TrimSpace(text))
+		for _, s := range docker.KillSignals {
+			if strings.HasPrefix(s, prefix) || strings.HasPrefix(s, "SIG"+prefix) {
+				signals = append(signals, s)
+			}
+		}
+		return signals
+	})
+
+	form.AddFormItem(field).
+		AddButton("Kill", func() {
+			signal := strings.TrimSpace(field.GetText())
+			if _, err := docker.ParseSignal(signal); err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.state.killSignal = signal
+			g.closeAndSwitchPanel("form", "containers")
+			g.containersTask("kill", containers, func(c *container) error {
+				return g.docker.KillContainer(c.ID, signal)
+			})
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "containers")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 7), true).ShowPage("main")
+}
@@ -0,0 +1,64 @@
+package gui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStopTimeout(t *testing.T) {
+	if timeout, err := parseStopTimeout(" "); err != nil || timeout != nil {
+		t.Errorf("Expected the default timeout. Got %v, %v.", timeout, err)
+	}
+	if timeout, err := parseStopTimeout("30"); err != nil || *timeout != 30*time.Second {
+		t.Errorf("Expected 30s. Got %v, %v.", timeout, err)
+	}
+	for _, text := range []string{"-1", "10s"} {
+		if _, err := parseStopTimeout(text); err == nil {
+			t.Errorf("Expected error for %q.", text)
+		}
+	}
+}
+
+func TestContainerStateColor(t *testing.T) {
+	states := []string{"running", "paused", "restarting", "created", "exited"}
+	colors := make(map[interface{}]string)
+	for _, state := range states {
+		color := containerStateColor(state)
+		if other, ok := colors[color]; ok {
+			t.Errorf("Expected distinct colors. %s and %s are the same.", state, other)
+		}
+		colors[color] = state
+	}
+}
+
+func TestPauseMarkedContainers(t *testing.T) {
+	fake := newTestFake()
+	fake.ContainerItems[1].State = "running"
+	g := newTestGui(t, fake)
+
+	stop := make(chan int)
+	done := make(chan struct{})
+	g.state.stopChans["task"] = stop
+	go func() {
+		g.monitoringTask()
+		close(done)
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	c := g.containerPanel()
+	for _, container := range g.state.resources.containers {
+		c.marked[container.ID] = true
+	}
+	g.pauseContainer()
+
+	deadline := time.Now().Add(time.Second)
+	for len(fake.CallsOf("PauseContainer")) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if calls := fake.CallsOf("PauseContainer"); len(calls) != 2 {
+		t.Errorf("Expected both marked containers are paused. Got %v.", calls)
+	}
+}
@@ -0,0 +1,551 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+var (
+	// logBufferSize max number of lines kept in the log viewer
+	logBufferSize = 10000
+	// logRenderInterval interval to render new lines
+	logRenderInterval = 200 * time.Millisecond
+	// logTimeFormat timestamp format of log lines
+	logTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
+// logSource container whose logs are displayed
+type logSource struct {
+	id        string
+	name      string
+	color     string
+	muted     bool
+	streaming bool
+}
+
+// logEntry log line with its source
+type logEntry struct {
+	docker.LogLine
+	source *logSource
+}
+
+// logBuffer bounded ring buffer of log lines
+type logBuffer struct {
+	mu      sync.Mutex
+	lines   []logEntry
+	start   int
+	size    int
+	version int
+}
+
+func newLogBuffer(capacity int) *logBuffer {
+	return &logBuffer{
+		lines: make([]logEntry, capacity),
+	}
+}
+
+// add line, the oldest line is dropped when the buffer is full
+func (b *logBuffer) add(line logEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	end := (b.start + b.size) % len(b.lines)
+	b.lines[end] = line
+	if b.size < len(b.lines) {
+		b.size++
+	} else {
+		b.start = (b.start + 1) % len(b.lines)
+	}
+	b.version++
+}
+
+// snapshot return lines from oldest to newest and the buffer version
+func (b *logBuffer) snapshot() ([]logEntry, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := make([]logEntry, b.size)
+	for i := 0; i < b.size; i++ {
+		lines[i] = b.lines[(b.start+i)%len(b.lines)]
+	}
+	return lines, b.version
+}
+
+func (b *logBuffer) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.start = 0
+	b.size = 0
+	b.version++
+}
+
+type logViewer struct {
+	*tview.Flex
+	text       *tview.TextView
+	status     *tview.TextView
+	title      string
+	sources    []*logSource
+	matcher    *logMatcher
+	buffer     *logBuffer
+	options    docker.LogOptions
+	follow     bool
+	timestamps bool
+	searchWord string
+	matches    int
+	current    int
+	rendered   int
+	err        error
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stop       chan struct{}
+}
+
+func newLogViewer(g *Gui, title string, sources []*logSource) *logViewer {
+	v := &logViewer{
+		Flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		text:    tview.NewTextView(),
+		status:  tview.NewTextView(),
+		title:   title,
+		sources: sources,
+		buffer:  newLogBuffer(logBufferSize),
+		options: docker.LogOptions{
+			Tail:   "1000",
+			Follow: true,
+		},
+		follow: true,
+		stop:   make(chan struct{}),
+	}
+
+	v.text.SetDynamicColors(true).SetRegions(true).SetWrap(false)
+	v.text.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	v.status.SetDynamicColors(true)
+
+	v.AddItem(v.text, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+
+	v.setKeybinding(g)
+	return v
+}
+
+// merged whether the viewer displays logs of several containers
+func (v *logViewer) merged() bool {
+	return v.matcher != nil || len(v.sources) > 1
+}
+
+func (v *logViewer) name() string {
+	return "logs"
+}
+
+func (v *logViewer) setKeybinding(g *Gui) {
+	v.text.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			v.close(g)
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'q':
+			v.close(g)
+		case 'f':
+			v.follow = !v.follow
+			v.render()
+		case 't':
+			v.timestamps = !v.timestamps
+			v.render()
+		case '/':
+			v.searchInput(g)
+		case 'n':
+			v.nextMatch(1)
+		case 'N':
+			v.nextMatch(-1)
+		case 'o':
+			v.optionsForm(g)
+		case 'w':
+			v.saveForm(g)
+		case 'm':
+			if v.merged() {
+				v.muteList(g)
+			}
+		default:
+			return event
+		}
+
+		return nil
+	})
+}
+
+// start stream logs of all sources with current options
+func (v *logViewer) start(g *Gui) {
+	if v.cancel != nil {
+		v.cancel()
+	}
+
+	v.ctx, v.cancel = context.WithCancel(context.Background())
+	v.buffer.reset()
+	v.err = nil
+
+	for _, src := range v.sources {
+		v.streamSource(g, src, v.options)
+	}
+
+	if v.matcher != nil {
+		go v.watchSources(g, v.ctx)
+	}
+}
+
+// streamSource stream logs of src into the buffer, it must be called on the ui goroutine
+func (v *logViewer) streamSource(g *Gui, src *logSource, options docker.LogOptions) {
+	ctx := v.ctx
+	src.streaming = true
+
+	go func() {
+		err := g.docker.StreamLogs(ctx, src.id, options, func(line docker.LogLine) {
+			v.buffer.add(logEntry{LogLine: line, source: src})
+		})
+		if err != nil {
+			common.Logger.Errorf("cannot stream container logs %s", err)
+		}
+
+		g.app.QueueUpdateDraw(func() {
+			// the viewer is restarted, and src is streamed again
+			if ctx != v.ctx {
+				return
+			}
+			src.streaming = false
+			if err != nil {
+				v.err = fmt.Errorf("%s: %s", src.name, err)
+				v.render()
+			}
+		})
+	}()
+}
+
+// renderLoop render new lines while following
+func (v *logViewer) renderLoop(g *Gui) {
+	ticker := time.NewTicker(logRenderInterval)
+	defer ticker.Stop()
+
+	last := 0
+	for {
+		select {
+		case <-ticker.C:
+			_, version := v.buffer.snapshot()
+			if version == last {
+				continue
+			}
+			last = version
+			g.app.QueueUpdateDraw(func() {
+				if v.follow && version != v.rendered {
+					v.render()
+				}
+			})
+		case <-v.stop:
+			return
+		}
+	}
+}
+
+func (v *logViewer) close(g *Gui) {
+	if v.cancel != nil {
+		v.cancel()
+	}
+	close(v.stop)
+	g.closeAndSwitchPanel(v.name(), "containers")
+}
+
+// prefixWidth width of source name prefix of merged logs
+func (v *logViewer) prefixWidth() int {
+	width := 0
+	for _, src := range v.sources {
+		if len(src.name) > width {
+			width = len(src.name)
+		}
+	}
+	return width
+}
+
+// visibleLines return lines of unmuted sources, merged logs are ordered by timestamp
+func (v *logViewer) visibleLines() []logEntry {
+	lines, version := v.buffer.snapshot()
+	v.rendered = version
+
+	if !v.merged() {
+		return lines
+	}
+
+	visible := lines[:0]
+	for _, line := range lines {
+		if !line.source.muted {
+			visible = append(visible, line)
+		}
+	}
+
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].Time.Before(visible[j].Time)
+	})
+
+	return visible
+}
+
+func (v *logViewer) formatLine(line logEntry, width int) string {
+	text := line.Text
+	if v.merged() {
+		text = fmt.Sprintf("%-*s | %s", width, line.source.name, text)
+	}
+	if v.timestamps && !line.Time.IsZero() {
+		text = line.Time.Format(logTimeFormat) + " " + text
+	}
+	return text
+}
+
+// render display buffered lines with highlighted matches
+func (v *logViewer) render() {
+	lines := v.visibleLines()
+	width := v.prefixWidth()
+	v.matches = 0
+
+	var text strings.Builder
+	for _, line := range lines {
+		if v.timestamps && !line.Time.IsZero() {
+			text.WriteString("[gray]" + line.Time.Format(logTimeFormat) + "[-] ")
+		}
+
+		if v.merged() {
+			text.WriteString(fmt.Sprintf("[%s]%-*s |[-] ", line.source.color, width, tview.Escape(line.source.name)))
+		}
+
+		if line.Stderr {
+			text.WriteString("[red]")
+		}
+		text.WriteString(v.highlight(line.Text))
+		if line.Stderr {
+			text.WriteString("[-]")
+		}
+		text.WriteString("\n")
+	}
+
+	v.text.SetText(text.String())
+	if v.follow {
+		v.text.ScrollToEnd()
+	}
+
+	if v.current >= v.matches {
+		v.current = 0
+	}
+
+	v.updateStatus(len(lines))
+}
+
+// highlight escape text and mark matches of search word as regions
+func (v *logViewer) highlight(text string) string {
+	if v.searchWord == "" {
+		return tview.Escape(text)
+	}
+
+	var result strings.Builder
+	lower := strings.ToLower(text)
+	word := strings.ToLower(v.searchWord)
+
+	// fall back to case sensitive search when lowering changes the length
+	if len(lower) != len(text) || len(word) != len(v.searchWord) {
+		lower, word = text, v.searchWord
+	}
+
+	for {
+		i := strings.Index(lower, word)
+		if i < 0 {
+			break
+		}
+
+		result.WriteString(tview.Escape(text[:i]))
+		result.WriteString(fmt.Sprintf(`["match-%d"][black:yellow]%s[-:-][""]`, v.matches, tview.Escape(text[i:i+len(word)])))
+		v.matches++
+
+		text = text[i+len(word):]
+		lower = lower[i+len(word):]
+	}
+
+	result.WriteString(tview.Escape(text))
+	return result.String()
+}
+
+func (v *logViewer) nextMatch(step int) {
+	if v.matches == 0 {
+		return
+	}
+
+	// stop following, otherwise the view jumps to the end
+	v.follow = false
+	v.current = (v.current + step + v.matches) % v.matches
+	v.text.Highlight(fmt.Sprintf("match-%d", v.current)).ScrollToHighlight()
+	v.updateStatus(-1)
+}
+
+func (v *logViewer) updateStatus(lines int) {
+	title := fmt.Sprintf(" logs %s ", tview.Escape(v.title))
+	if v.follow {
+		title += "(following) "
+	} else {
+		title += "(paused) "
+	}
+	if lines >= 0 {
+		title += fmt.Sprintf("%d lines ", lines)
+	}
+	v.text.SetTitle(title)
+
+	status := " f: follow/pause, t: timestamps, /: search, n/N: next/prev match, o: options, w: save, q: close"
+	if v.merged() {
+		status += ", m: mute"
+	}
+	if v.searchWord != "" {
+		status = fmt.Sprintf(" search %q: %d matches |", v.searchWord, v.matches) + status
+	}
+	if v.err != nil {
+		status = "[red] " + tview.Escape(v.err.Error()) + "[-] |" + status
+	}
+	v.status.SetText(status)
+}
+
+// showPopup display p over the log viewer
+func (v *logViewer) showPopup(g *Gui, name string, p tview.Primitive, width, height int) {
+	g.pages.AddAndSwitchToPage(name, g.modal(p, width, height), true).ShowPage(v.name())
+}
+
+func (v *logViewer) closePopup(g *Gui, name string) {
+	g.pages.RemovePage(name).SwitchToPage(v.name())
+	g.app.SetFocus(v.text)
+}
+
+func (v *logViewer) searchInput(g *Gui) {
+	viewName := "logSearch"
+	input := tview.NewInputField().SetLabel("Word").SetText(v.searchWord)
+	input.SetLabelWidth(6)
+	input.SetTitle("search").SetTitleAlign(tview.AlignLeft)
+	input.SetBorder(true)
+
+	input.SetChangedFunc(func(text string) {
+		v.searchWord = text
+		v.current = 0
+		v.render()
+	})
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			v.closePopup(g, viewName)
+			v.nextMatch(0)
+		case tcell.KeyEsc:
+			v.searchWord = ""
+			v.render()
+			v.closePopup(g, viewName)
+		}
+	})
+
+	v.showPopup(g, viewName, input, 80, 3)
+}
+
+func (v *logViewer) optionsForm(g *Gui) {
+	viewName := "logOptions"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Log options")
+	form.AddInputField("Since", v.options.Since, inputWidth, nil, nil).
+		AddInputField("Until", v.options.Until, inputWidth, nil, nil).
+		AddInputField("Tail", v.options.Tail, inputWidth, nil, nil).
+		AddButton("Apply", func() {
+			v.options.Since = form.GetFormItemByLabel("Since").(*tview.InputField).GetText()
+			v.options.Until = form.GetFormItemByLabel("Until").(*tview.InputField).GetText()
+			v.options.Tail = form.GetFormItemByLabel("Tail").(*tview.InputField).GetText()
+			// logs after until never come
+			v.options.Follow = v.options.Until == ""
+			v.follow = true
+			v.closePopup(g, viewName)
+			v.start(g)
+		}).
+		AddButton("Cancel", func() {
+			v.closePopup(g, viewName)
+		})
+
+	v.showPopup(g, viewName, form, 80, 11)
+}
+
+func (v *logViewer) saveForm(g *Gui) {
+	viewName := "logSave"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Save logs")
+	form.AddInputField("Path", "", inputWidth, nil, nil).
+		AddButton("Save", func() {
+			path := form.GetFormItemByLabel("Path").(*tview.InputField).GetText()
+			v.closePopup(g, viewName)
+			v.save(g, path)
+		}).
+		AddButton("Cancel", func() {
+			v.closePopup(g, viewName)
+		})
+
+	v.showPopup(g, viewName, form, 80, 7)
+}
+
+// save write the visible lines to path
+func (v *logViewer) save(g *Gui, path string) {
+	lines := v.visibleLines()
+	width := v.prefixWidth()
+	text := make([]string, len(lines))
+	for i, line := range lines {
+		text[i] = v.formatLine(line, width)
+	}
+
+	g.startTask("save logs "+v.title, func(ctx context.Context) error {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if err != nil {
+			common.Logger.Errorf("cannot save logs %s", err)
+			return err
+		}
+		defer file.Close()
+
+		if _, err := file.WriteString(strings.Join(text, "\n") + "\n"); err != nil {
+			common.Logger.Errorf("cannot save logs %s", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (g *Gui) openLogViewer(v *logViewer) {
+	v.render()
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+	v.start(g)
+	go v.renderLoop(g)
+}
+
+func (g *Gui) showContainerLogs() {
+	if len(g.containerPanel().marked) > 0 {
+		g.mergedLogsForm()
+		return
+	}
+
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot show container logs: selected container is null")
+		return
+	}
+
+	g.openLogViewer(newLogViewer(g, container.Name, []*logSource{
+		{id: container.ID, name: container.Name},
+	}))
+}
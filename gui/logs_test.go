@@ -0,0 +1,104 @@
+package gui
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/skanehira/docui/docker"
+)
+
+func TestLogBuffer(t *testing.T) {
+	b := newLogBuffer(3)
+	for i := 0; i < 5; i++ {
+		b.add(logEntry{LogLine: docker.LogLine{Text: fmt.Sprint(i)}})
+	}
+
+	lines, version := b.snapshot()
+	if version != 5 {
+		t.Errorf("Expected version 5. Got %d.", version)
+	}
+
+	if len(lines) != 3 || lines[0].Text != "2" || lines[2].Text != "4" {
+		t.Errorf("Expected newest 3 lines. Got %+v.", lines)
+	}
+
+	b.reset()
+	if lines, _ := b.snapshot(); len(lines) != 0 {
+		t.Errorf("Expected empty buffer. Got %+v.", lines)
+	}
+}
+
+func TestLogViewerHighlight(t *testing.T) {
+	v := &logViewer{searchWord: "err"}
+
+	got := v.highlight("Error: [err]")
+	want := `["match-0"][black:yellow]Err[-:-][""]or: [["match-1"][black:yellow]err[-:-][""]]`
+	if got != want {
+		t.Errorf("Expected %s. Got %s.", want, got)
+	}
+
+	if v.matches != 2 {
+		t.Errorf("Expected 2 matches. Got %d.", v.matches)
+	}
+}
+
+func TestMergedLogLines(t *testing.T) {
+	web := &logSource{id: "1", name: "web", color: "green"}
+	db := &logSource{id: "2", name: "db", color: "yellow"}
+
+	v := &logViewer{
+		sources: []*logSource{web, db},
+		buffer:  newLogBuffer(10),
+	}
+
+	base := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	v.buffer.add(logEntry{LogLine: docker.LogLine{Time: base.Add(2 * time.Second), Text: "web 2"}, source: web})
+	v.buffer.add(logEntry{LogLine: docker.LogLine{Time: base.Add(1 * time.Second), Text: "db 1"}, source: db})
+	v.buffer.add(logEntry{LogLine: docker.LogLine{Time: base.Add(3 * time.Second), Text: "db 3"}, source: db})
+
+	var got []string
+	for _, line := range v.visibleLines() {
+		got = append(got, v.formatLine(line, v.prefixWidth()))
+	}
+
+	want := []string{"db  | db 1", "web | web 2", "db  | db 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v. Got %v.", want, got)
+	}
+
+	db.muted = true
+	if lines := v.visibleLines(); len(lines) != 1 || lines[0].source != web {
+		t.Errorf("Expected only web lines. Got %+v.", lines)
+	}
+}
+
+func TestLogMatcher(t *testing.T) {
+	m, err := newLogMatcher(namePattern([]string{"web.1", "db"}), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.match("web.1", nil) || !m.match("db", nil) {
+		t.Error("Expected selected names to match.")
+	}
+	if m.match("webx1", nil) || m.match("db2", nil) {
+		t.Error("Expected other names not to match.")
+	}
+
+	m, err = newLogMatcher("", "com.docker.compose.project=app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.match("any", map[string]string{"com.docker.compose.project": "app"}) {
+		t.Error("Expected label to match.")
+	}
+	if m.match("any", map[string]string{"com.docker.compose.project": "other"}) {
+		t.Error("Expected label value not to match.")
+	}
+
+	if _, err := newLogMatcher("", ""); err == nil {
+		t.Error("Expected error for empty matcher.")
+	}
+}
@@ -0,0 +1,262 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+)
+
+// logColors colors of log sources
+var logColors = []string{
+	"green",
+	"yellow",
+	"aqua",
+	"fuchsia",
+	"orange",
+	"lime",
+	"skyblue",
+	"pink",
+}
+
+// logMatcher select containers whose logs are merged
+type logMatcher struct {
+	name *regexp.Regexp
+	// label label key or key=value
+	label string
+}
+
+func newLogMatcher(name, label string) (*logMatcher, error) {
+	if name == "" && label == "" {
+		return nil, errors.New("please input name pattern or label")
+	}
+
+	m := &logMatcher{label: label}
+	if name != "" {
+		re, err := regexp.Compile(name)
+		if err != nil {
+			return nil, err
+		}
+		m.name = re
+	}
+
+	return m, nil
+}
+
+func (m *logMatcher) match(name string, labels map[string]string) bool {
+	if m.name != nil && !m.name.MatchString(name) {
+		return false
+	}
+
+	if m.label != "" {
+		kv := strings.SplitN(m.label, "=", 2)
+		value, ok := labels[kv[0]]
+		if !ok {
+			return false
+		}
+		if len(kv) > 1 && value != kv[1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *logMatcher) String() string {
+	var s []string
+	if m.name != nil {
+		s = append(s, m.name.String())
+	}
+	if m.label != "" {
+		s = append(s, "label "+m.label)
+	}
+	return strings.Join(s, " ")
+}
+
+// namePattern make regexp which matches the names exactly
+func namePattern(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+func (g *Gui) mergedLogsForm() {
+	var names []string
+	for id := range g.containerPanel().marked {
+		for _, c := range g.state.resources.containers {
+			if c.ID == id {
+				names = append(names, c.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Merged logs")
+	form.AddInputField("Name", namePattern(names), inputWidth, nil, nil).
+		AddInputField("Label", "", inputWidth, nil, nil).
+		AddButton("Show", func() {
+			name := form.GetFormItemByLabel("Name").(*tview.InputField).GetText()
+			label := form.GetFormItemByLabel("Label").(*tview.InputField).GetText()
+
+			matcher, err := newLogMatcher(name, label)
+			if err != nil {
+				g.message(err.Error(), "OK", "containers", func() {})
+				return
+			}
+
+			g.closeAndSwitchPanel("form", "containers")
+			g.showMergedLogs(matcher)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "containers")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 9), true).ShowPage("main")
+}
+
+func (g *Gui) showMergedLogs(matcher *logMatcher) {
+	containers, err := g.docker.Containers(types.ContainerListOptions{All: true})
+	if err != nil {
+		common.Logger.Errorf("cannot get containers %s", err)
+		g.message(err.Error(), "OK", "containers", func() {})
+		return
+	}
+
+	v := newLogViewer(g, matcher.String(), nil)
+	v.matcher = matcher
+
+	for _, c := range containers {
+		name := c.Names[0][1:]
+		if matcher.match(name, c.Labels) {
+			v.addSource(c.ID[:12], name)
+		}
+	}
+
+	sort.Slice(v.sources, func(i, j int) bool {
+		return v.sources[i].name < v.sources[j].name
+	})
+	for i, src := range v.sources {
+		src.color = logColors[i%len(logColors)]
+	}
+
+	g.openLogViewer(v)
+}
+
+func (v *logViewer) addSource(id, name string) *logSource {
+	src := &logSource{
+		id:    id,
+		name:  name,
+		color: logColors[len(v.sources)%len(logColors)],
+	}
+	v.sources = append(v.sources, src)
+	return src
+}
+
+func (v *logViewer) source(id string) *logSource {
+	for _, src := range v.sources {
+		if strings.HasPrefix(id, src.id) {
+			return src
+		}
+	}
+	return nil
+}
+
+// watchSources stream logs of containers which match and are started after the viewer is opened
+func (v *logViewer) watchSources(g *Gui, ctx context.Context) {
+	err := g.docker.WatchEvents(ctx, func(msg events.Message) {
+		if msg.Type != events.ContainerEventType || msg.Action != "start" {
+			return
+		}
+
+		// attributes of container event have name and labels
+		name := msg.Actor.Attributes["name"]
+		if !v.matcher.match(name, msg.Actor.Attributes) {
+			return
+		}
+
+		options := v.options
+		options.Since = fmt.Sprintf("%d", time.Unix(0, msg.TimeNano).Unix())
+		options.Tail = "all"
+
+		g.app.QueueUpdateDraw(func() {
+			if ctx != v.ctx {
+				return
+			}
+
+			id := msg.Actor.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+
+			src := v.source(id)
+			if src == nil {
+				src = v.addSource(id, name)
+			} else if src.streaming {
+				return
+			}
+
+			v.streamSource(g, src, options)
+			v.render()
+		})
+	})
+
+	if err != nil {
+		common.Logger.Errorf("cannot watch new containers %s", err)
+		g.app.QueueUpdateDraw(func() {
+			v.err = fmt.Errorf("cannot watch new containers: %s", err)
+			v.render()
+		})
+	}
+}
+
+func (v *logViewer) muteList(g *Gui) {
+	viewName := "logMute"
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true)
+	list.SetTitle("mute (Enter: toggle, q: close)").SetTitleAlign(tview.AlignLeft)
+
+	label := func(src *logSource) string {
+		mark := "[x]"
+		if src.muted {
+			mark = "[ ]"
+		}
+		return fmt.Sprintf("%s [%s]%s[-]", tview.Escape(mark), src.color, tview.Escape(src.name))
+	}
+
+	for i, src := range v.sources {
+		i, src := i, src
+		list.AddItem(label(src), "", 0, func() {
+			src.muted = !src.muted
+			list.SetItemText(i, label(src), "")
+			v.render()
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+			v.closePopup(g, viewName)
+			return nil
+		}
+		return event
+	})
+
+	v.showPopup(g, viewName, list, 60, len(v.sources)+2)
+}
@@ -5,6 +5,88 @@ import (
 	"github.com/rivo/tview"
 )
 
+// keybinding key and its operation shown in the help
+type keybinding struct {
+	key       string
+	operation string
+}
+
+// globalKeybindings keys working in all panels
+var globalKeybindings = []keybinding{
+	{"Tab / l", "next panel"},
+	{"Shift+Tab / h", "previous panel"},
+	{"/", "filter"},
+	{"U", "disk usage"},
+	{"?", "help"},
+	{"q", "quit"},
+}
+
+// panelKeybindings all keys of each panel, the navigate shows only frequently used keys
+var panelKeybindings = map[string][]keybinding{
+	"tasks": {
+		{"c", "cancel task"},
+	},
+	"images": {
+		{"p", "pull image"},
+		{"f", "search images"},
+		{"d", "remove image"},
+		{"c", "create container"},
+		{"Enter", "inspect image"},
+		{"s", "save image"},
+		{"i", "import image"},
+		{"Ctrl+l", "load image"},
+		{"Ctrl+r", "refresh image list"},
+		{"H", "show layers"},
+		{"b", "build image"},
+		{"r", "run from command"},
+	},
+	"containers": {
+		{"Enter", "inspect container"},
+		{"d", "remove container"},
+		{"u", "start container"},
+		{"s", "stop with timeout"},
+		{"R", "restart with timeout"},
+		{"p", "pause container"},
+		{"P", "unpause container"},
+		{"Ctrl+k", "kill with signal"},
+		{"e", "export container"},
+		{"c", "commit container"},
+		{"r", "rename container"},
+		{"Ctrl+e", "exec container cmd"},
+		{"Ctrl+l", "show container logs"},
+		{"Ctrl+r", "refresh container list"},
+		{"space", "mark container"},
+		{"L", "merged logs"},
+		{"S", "stats"},
+		{"t", "processes"},
+		{"b", "browse files"},
+		{"D", "diff"},
+		{"g", "generate run/compose"},
+		{"E", "edit & recreate"},
+		{"a", "attach"},
+		{"T", "terminal"},
+		{"x", "run command"},
+	},
+	"volumes": {
+		{"c", "create volume"},
+		{"d", "remove volume"},
+		{"Enter", "inspect volume"},
+		{"b", "browse files"},
+		{"B", "backup volume"},
+		{"R", "restore volume"},
+		{"C", "clone volume"},
+		{"Ctrl+r", "refresh volume list"},
+	},
+	"networks": {
+		{"c", "create network"},
+		{"d", "remove network"},
+		{"Enter", "inspect network"},
+		{"a", "connect container"},
+		{"D", "disconnect container"},
+		{"Ctrl+r", "refresh network list"},
+	},
+}
+
 type navigate struct {
 	*tview.TextView
 	keybindings map[string]string
@@ -14,14 +96,18 @@ func newNavigate() *navigate {
 	return &navigate{
 		TextView: tview.NewTextView().SetTextColor(tcell.ColorYellow),
 		keybindings: map[string]string{
-			"images":     " p: pull image, i: import image, s: save image, Ctrl+l: load image, f: search image, /: filter d: remove image,\n c: create container, Enter: inspect image, Ctrl+r: refresh images list",
-			"containers": " e: export container, c: commit container, /: filter, Ctrl+e: exec container cmd u: start container, s: stop container,\n Ctrl+k: kill container, d: remove container, Enter: inspect container, Ctrl+r: refresh container list, Ctrl+l: show container logs",
-			"networks":   " d: remove network, Enter: inspect network, /: filter",
-			"volumes":    " c: create volume, d: remove volume\n /: filter, Enter: inspect volume, Ctrl+r: refresh volume list",
+			"images":     " p: pull, c: create container, d: remove, b: build, H: layers, Enter: inspect",
+			"containers": " u: start, s: stop, d: remove, Ctrl+l: logs, T: terminal, Enter: inspect",
+			"tasks":      " c: cancel task",
+			"networks":   " c: create network, d: remove network, a: connect, D: disconnect, Enter: inspect",
+			"volumes":    " c: create volume, d: remove volume, b: browse files, B: backup, Enter: inspect",
 		},
 	}
 }
 
+// globalNavigate second line of the navigate
+const globalNavigate = " Tab/h/l: switch panel, /: filter, U: disk usage, ?: all keys, q: quit"
+
 func (n *navigate) update(panel string) {
-	n.SetText(n.keybindings[panel])
+	n.SetText(n.keybindings[panel] + "\n" + globalNavigate)
 }
@@ -0,0 +1,191 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// networkOptions parse labels and driver options separated by spaces, and make options to create network
+func networkOptions(spec docker.NetworkSpec, labels, options string) (types.NetworkCreate, error) {
+	var err error
+	if spec.Labels, err = common.SplitShellWords(labels); err != nil {
+		return types.NetworkCreate{}, fmt.Errorf("invalid labels: %s", err)
+	}
+	if spec.Options, err = common.SplitShellWords(options); err != nil {
+		return types.NetworkCreate{}, fmt.Errorf("invalid options: %s", err)
+	}
+	return docker.NewNetworkOptions(spec)
+}
+
+func (g *Gui) createNetworkForm() {
+	title := "Create network"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+	checked := func(label string) bool {
+		return form.GetFormItemByLabel(label).(*tview.Checkbox).IsChecked()
+	}
+
+	form.AddInputField("Name", "", inputWidth, nil, nil).
+		AddInputField("Driver", "bridge", inputWidth, nil, nil).
+		AddInputField("Subnet", "", inputWidth, nil, nil).
+		AddInputField("Gateway", "", inputWidth, nil, nil).
+		AddInputField("IP range", "", inputWidth, nil, nil).
+		AddInputField("Labels", "", inputWidth, nil, nil).
+		AddInputField("Options", "", inputWidth, nil, nil).
+		AddCheckbox("Internal", false, nil).
+		AddCheckbox("Attachable", false, nil).
+		AddCheckbox("IPv6", false, nil).
+		AddButton("Create", func() {
+			spec := docker.NetworkSpec{
+				Name:       text("Name"),
+				Driver:     text("Driver"),
+				Subnet:     text("Subnet"),
+				Gateway:    text("Gateway"),
+				IPRange:    text("IP range"),
+				Internal:   checked("Internal"),
+				Attachable: checked("Attachable"),
+				IPv6:       checked("IPv6"),
+			}
+
+			opt, err := networkOptions(spec, text("Labels"), text("Options"))
+			if err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.closeAndSwitchPanel("form", "networks")
+			g.startTask("create network "+spec.Name, func(ctx context.Context) error {
+				if _, err := g.docker.CreateNetwork(spec.Name, opt); err != nil {
+					common.Logger.Errorf("cannot create network %s", err)
+					return err
+				}
+				g.networkPanel().updateEntries(g)
+				return nil
+			})
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "networks")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 25), true).ShowPage("main")
+}
+
+func (g *Gui) connectNetworkForm() {
+	network := g.selectedNetwork()
+	if network == nil {
+		common.Logger.Errorf("cannot connect network: selected network is null")
+		return
+	}
+
+	containers := g.state.resources.containers
+	if len(containers) == 0 {
+		g.message("There are no containers", "OK", "networks", func() {})
+		return
+	}
+
+	var names []string
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+
+	title := "Connect container to " + network.Name
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+
+	form.AddDropDown("Container", names, 0, nil).
+		AddInputField("Aliases", "", inputWidth, nil, nil).
+		AddInputField("IP address", "", inputWidth, nil, nil).
+		AddButton("Connect", func() {
+			i, _ := form.GetFormItemByLabel("Container").(*tview.DropDown).GetCurrentOption()
+			container := containers[i]
+
+			aliases, err := common.SplitShellWords(text("Aliases"))
+			if err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape("invalid aliases: "+err.Error()) + "[-]")
+				return
+			}
+			settings, err := docker.NewEndpointSettings(aliases, text("IP address"))
+			if err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+
+			g.closeAndSwitchPanel("form", "networks")
+			g.startTask(fmt.Sprintf("connect %s to %s", container.Name, network.Name), func(ctx context.Context) error {
+				if err := g.docker.ConnectNetwork(network.ID, container.ID, settings); err != nil {
+					common.Logger.Errorf("cannot connect network %s", err)
+					return err
+				}
+				g.networkPanel().updateEntries(g)
+				return nil
+			})
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "networks")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 11), true).ShowPage("main")
+}
+
+func (g *Gui) disconnectNetworkForm() {
+	network := g.selectedNetwork()
+	if network == nil {
+		common.Logger.Errorf("cannot disconnect network: selected network is null")
+		return
+	}
+
+	if len(network.endpoints) == 0 {
+		g.message("No containers are connected to "+network.Name, "OK", "networks", func() {})
+		return
+	}
+
+	var names []string
+	for _, e := range network.endpoints {
+		names = append(names, e.Name)
+	}
+
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Disconnect container from " + network.Name)
+	form.AddDropDown("Container", names, 0, nil).
+		AddCheckbox("Force", false, nil).
+		AddButton("Disconnect", func() {
+			i, _ := form.GetFormItemByLabel("Container").(*tview.DropDown).GetCurrentOption()
+			endpoint := network.endpoints[i]
+			force := form.GetFormItemByLabel("Force").(*tview.Checkbox).IsChecked()
+
+			g.closeAndSwitchPanel("form", "networks")
+			g.startTask(fmt.Sprintf("disconnect %s from %s", endpoint.Name, network.Name), func(ctx context.Context) error {
+				if err := g.docker.DisconnectNetwork(network.ID, endpoint.ID, force); err != nil {
+					common.Logger.Errorf("cannot disconnect network %s", err)
+					return err
+				}
+				g.networkPanel().updateEntries(g)
+				return nil
+			})
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "networks")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 9), true).ShowPage("main")
+}
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -9,7 +10,6 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/skanehira/docui/common"
-	"github.com/skanehira/docui/docker"
 )
 
 type network struct {
@@ -18,6 +18,44 @@ type network struct {
 	Driver     string
 	Scope      string
 	containers string
+	endpoints  []endpoint
+}
+
+// endpoint container attached to the network
+type endpoint struct {
+	ID      string
+	Name    string
+	Address string
+}
+
+// networkEndpoints return attached containers sorted by name
+func networkEndpoints(containers map[string]types.EndpointResource) []endpoint {
+	endpoints := make([]endpoint, 0, len(containers))
+	for id, e := range containers {
+		address := e.IPv4Address
+		if address == "" {
+			address = e.IPv6Address
+		}
+		endpoints = append(endpoints, endpoint{ID: id, Name: e.Name, Address: address})
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Name < endpoints[j].Name
+	})
+	return endpoints
+}
+
+// formatEndpoints format endpoints like "db(172.18.0.2/16) web(172.18.0.3/16)"
+func formatEndpoints(endpoints []endpoint) string {
+	var containers []string
+	for _, e := range endpoints {
+		if e.Address == "" {
+			containers = append(containers, e.Name)
+			continue
+		}
+		containers = append(containers, fmt.Sprintf("%s(%s)", e.Name, e.Address))
+	}
+	return strings.Join(containers, " ")
 }
 
 type networks struct {
@@ -54,6 +92,12 @@ func (n *networks) setKeybinding(g *Gui) {
 		switch event.Rune() {
 		case 'd':
 			g.removeNetwork()
+		case 'c':
+			g.createNetworkForm()
+		case 'a':
+			g.connectNetworkForm()
+		case 'D':
+			g.disconnectNetworkForm()
 		}
 
 		return event
@@ -61,7 +105,7 @@ func (n *networks) setKeybinding(g *Gui) {
 }
 
 func (n *networks) entries(g *Gui) {
-	networks, err := docker.Client.Networks(types.NetworkListOptions{})
+	networks, err := g.docker.Networks(types.NetworkListOptions{})
 	if err != nil {
 		common.Logger.Error(err)
 		return
@@ -75,24 +119,20 @@ func (n *networks) entries(g *Gui) {
 			continue
 		}
 
-		var containers string
-
-		net, err := docker.Client.InspectNetwork(net.ID)
+		net, err := g.docker.InspectNetwork(net.ID)
 		if err != nil {
 			common.Logger.Error(err)
 			continue
 		}
 
-		for _, endpoint := range net.Containers {
-			containers += fmt.Sprintf("%s ", endpoint.Name)
-		}
-
+		endpoints := networkEndpoints(net.Containers)
 		tmpMap[net.ID[:12]] = &network{
 			ID:         net.ID,
 			Name:       net.Name,
 			Driver:     net.Driver,
 			Scope:      net.Scope,
-			containers: containers,
+			containers: formatEndpoints(endpoints),
+			endpoints:  endpoints,
 		}
 
 		keys = append(keys, net.ID[:12])
@@ -0,0 +1,59 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/skanehira/docui/docker"
+)
+
+func TestFormatEndpoints(t *testing.T) {
+	endpoints := networkEndpoints(map[string]types.EndpointResource{
+		"2": {Name: "web", IPv4Address: "172.18.0.3/16"},
+		"1": {Name: "db", IPv4Address: "172.18.0.2/16"},
+		"3": {Name: "v6", IPv6Address: "fd00::3/64"},
+	})
+
+	expect := "db(172.18.0.2/16) v6(fd00::3/64) web(172.18.0.3/16)"
+	if got := formatEndpoints(endpoints); got != expect {
+		t.Errorf("Expected %s. Got %s.", expect, got)
+	}
+}
+
+func TestNetworkOptions(t *testing.T) {
+	opt, err := networkOptions(docker.NetworkSpec{Name: "backend"}, "env=dev 'team=a b'", "mtu=1400")
+	if err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	if opt.Labels["team"] != "a b" || opt.Options["mtu"] != "1400" {
+		t.Errorf("Unexpected options %+v.", opt)
+	}
+
+	if _, err := networkOptions(docker.NetworkSpec{Name: "backend"}, "'env=dev", ""); err == nil {
+		t.Error("Expected error for unterminated quote.")
+	}
+}
+
+func TestNetworkPanelEndpoints(t *testing.T) {
+	fake := newTestFake()
+	g := newTestGui(t, fake)
+
+	settings, _ := docker.NewEndpointSettings([]string{"www"}, "172.18.0.10")
+	if err := fake.ConnectNetwork("bridge", "web", settings); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	g.networkPanel().setEntries(g)
+
+	n := g.state.resources.networks[0]
+	if n.containers != "web(172.18.0.10/16)" {
+		t.Errorf("Expected web with its address. Got %s.", n.containers)
+	}
+
+	if err := fake.DisconnectNetwork("bridge", n.endpoints[0].ID, false); err != nil {
+		t.Fatalf("Expected no error. Got %s.", err)
+	}
+	g.networkPanel().setEntries(g)
+	if n := g.state.resources.networks[0]; len(n.endpoints) != 0 {
+		t.Errorf("Expected no endpoints. Got %+v.", n.endpoints)
+	}
+}
@@ -0,0 +1,312 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+)
+
+var (
+	// defaultPsArgs ps arguments used when the processes page is opened at first
+	defaultPsArgs = "aux"
+	// processRefreshInterval interval to refresh the process list
+	processRefreshInterval = 2 * time.Second
+)
+
+// processColumn find the column of the process list, e.g. "%CPU", "PID"
+func processColumn(titles []string, name string) int {
+	for i, title := range titles {
+		if strings.EqualFold(title, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortProcesses sort processes by the column in descending order
+func sortProcesses(titles []string, processes [][]string, column string) error {
+	i := processColumn(titles, column)
+	if i < 0 {
+		return fmt.Errorf("no %s column, please change ps arguments", column)
+	}
+
+	value := func(p []string) float64 {
+		if i >= len(p) {
+			return 0
+		}
+		v, _ := strconv.ParseFloat(p[i], 64)
+		return v
+	}
+
+	sort.SliceStable(processes, func(a, b int) bool {
+		return value(processes[a]) > value(processes[b])
+	})
+	return nil
+}
+
+type processViewer struct {
+	*tview.Flex
+	table     *tview.Table
+	status    *tview.TextView
+	container *container
+	psArgs    string
+	// sortColumn column to sort by, empty keeps the order of ps
+	sortColumn string
+	titles     []string
+	processes  [][]string
+	err        error
+	stop       chan struct{}
+}
+
+func (v *processViewer) name() string {
+	return "processes"
+}
+
+func (g *Gui) showContainerProcesses() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot show container processes: selected container is null")
+		return
+	}
+
+	v := &processViewer{
+		Flex:      tview.NewFlex().SetDirection(tview.FlexRow),
+		table:     tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		status:    tview.NewTextView().SetDynamicColors(true),
+		container: container,
+		psArgs:    g.state.psArgs,
+		stop:      make(chan struct{}),
+	}
+	v.table.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	v.AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 0, false)
+
+	v.setKeybinding(g)
+	v.render()
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+
+	go v.refreshLoop(g)
+}
+
+func (v *processViewer) setKeybinding(g *Gui) {
+	v.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			v.close(g)
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'q':
+			v.close(g)
+		case 'c':
+			v.toggleSort("%CPU")
+		case 'm':
+			v.toggleSort("%MEM")
+		case 'a':
+			v.psArgsForm(g)
+		case 'k':
+			v.signalForm(g)
+		case 'r':
+			go v.refresh(g)
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+func (v *processViewer) close(g *Gui) {
+	close(v.stop)
+	g.closeAndSwitchPanel(v.name(), "containers")
+}
+
+func (v *processViewer) refreshLoop(g *Gui) {
+	v.refresh(g)
+
+	ticker := time.NewTicker(processRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			v.refresh(g)
+		case <-v.stop:
+			return
+		}
+	}
+}
+
+func (v *processViewer) refresh(g *Gui) {
+	g.app.QueueUpdate(func() {
+		psArgs := v.psArgs
+
+		go func() {
+			top, err := g.docker.TopContainer(context.Background(), v.container.ID, psArgs)
+			if err != nil {
+				common.Logger.Errorf("cannot get container processes %s", err)
+			}
+
+			g.app.QueueUpdateDraw(func() {
+				v.err = err
+				if err == nil {
+					v.titles = top.Titles
+					v.processes = top.Processes
+				}
+				v.render()
+			})
+		}()
+	})
+}
+
+func (v *processViewer) toggleSort(column string) {
+	if v.sortColumn == column {
+		v.sortColumn = ""
+	} else {
+		v.sortColumn = column
+	}
+	v.render()
+}
+
+// selectedPID return the PID of the selected process
+func (v *processViewer) selectedPID() string {
+	row, _ := v.table.GetSelection()
+	i := processColumn(v.titles, "PID")
+	if i < 0 || row < 1 || row > v.table.GetRowCount()-1 {
+		return ""
+	}
+	return v.table.GetCell(row, i).Text
+}
+
+func (v *processViewer) render() {
+	pid := v.selectedPID()
+
+	processes := make([][]string, len(v.processes))
+	copy(processes, v.processes)
+
+	var sortErr error
+	if v.sortColumn != "" {
+		sortErr = sortProcesses(v.titles, processes, v.sortColumn)
+	}
+
+	v.table.SetTitle(fmt.Sprintf(" processes of %s (ps %s) ", tview.Escape(v.container.Name), tview.Escape(v.psArgs)))
+	v.table.Clear()
+
+	for i, title := range v.titles {
+		if title == v.sortColumn {
+			title += " ▼"
+		}
+		v.table.SetCell(0, i, &tview.TableCell{
+			Text:            title,
+			NotSelectable:   true,
+			Align:           tview.AlignLeft,
+			Color:           tcell.ColorWhite,
+			BackgroundColor: tcell.ColorDefault,
+			Attributes:      tcell.AttrBold,
+		})
+	}
+
+	pidColumn := processColumn(v.titles, "PID")
+	selected := 1
+	for i, process := range processes {
+		for j, text := range process {
+			cell := tview.NewTableCell(text).SetTextColor(tcell.ColorLightGreen)
+			// command is the last column
+			if j == len(process)-1 {
+				cell.SetExpansion(1)
+			}
+			v.table.SetCell(i+1, j, cell)
+		}
+		if pidColumn >= 0 && pidColumn < len(process) && process[pidColumn] == pid {
+			selected = i + 1
+		}
+	}
+	v.table.Select(selected, 0)
+
+	status := fmt.Sprintf(" %d processes | c: sort by cpu, m: sort by memory, a: ps args, k: send signal, r: refresh, q: close", len(processes))
+	switch {
+	case v.err != nil:
+		status = fmt.Sprintf(" [red]%s[-]", tview.Escape(v.err.Error()))
+	case sortErr != nil:
+		status = fmt.Sprintf(" [red]%s[-]", tview.Escape(sortErr.Error()))
+	}
+	v.status.SetText(status)
+}
+
+// showPopup display p over the process list
+func (v *processViewer) showPopup(g *Gui, name string, p tview.Primitive, width, height int) {
+	g.pages.AddAndSwitchToPage(name, g.modal(p, width, height), true).ShowPage(v.name())
+}
+
+func (v *processViewer) closePopup(g *Gui, name string) {
+	g.pages.RemovePage(name).SwitchToPage(v.name())
+	g.app.SetFocus(v.table)
+}
+
+func (v *processViewer) psArgsForm(g *Gui) {
+	viewName := "processArgs"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("ps arguments")
+	form.AddInputField("Args", v.psArgs, inputWidth, nil, nil).
+		AddButton("Apply", func() {
+			v.psArgs = form.GetFormItemByLabel("Args").(*tview.InputField).GetText()
+			g.state.psArgs = v.psArgs
+			v.closePopup(g, viewName)
+			v.render()
+			go v.refresh(g)
+		}).
+		AddButton("Cancel", func() {
+			v.closePopup(g, viewName)
+		})
+
+	v.showPopup(g, viewName, form, 80, 7)
+}
+
+func (v *processViewer) signalForm(g *Gui) {
+	viewName := "processSignal"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Send signal")
+	form.AddInputField("PID", v.selectedPID(), inputWidth, nil, nil).
+		AddInputField("Signal", "TERM", inputWidth, nil, nil).
+		AddButton("Send", func() {
+			pidText := form.GetFormItemByLabel("PID").(*tview.InputField).GetText()
+			signal := form.GetFormItemByLabel("Signal").(*tview.InputField).GetText()
+			v.closePopup(g, viewName)
+
+			pid, err := strconv.Atoi(strings.TrimSpace(pidText))
+			if err != nil {
+				v.err = fmt.Errorf("invalid pid %s", pidText)
+				v.render()
+				return
+			}
+
+			g.startTask(fmt.Sprintf("send %s to %d in %s", signal, pid, v.container.Name), func(ctx context.Context) error {
+				// ps shows PIDs on the host, kill in the container needs the PID in the container
+				containerPID, err := g.docker.ContainerPID(ctx, v.container.ID, pid)
+				if err != nil {
+					common.Logger.Errorf("cannot find the process in the container %s", err)
+					return err
+				}
+				if err := g.docker.SignalProcess(ctx, v.container.ID, containerPID, signal); err != nil {
+					common.Logger.Errorf("cannot send signal %s", err)
+					return err
+				}
+				v.refresh(g)
+				return nil
+			})
+		}).
+		AddButton("Cancel", func() {
+			v.closePopup(g, viewName)
+		})
+
+	v.showPopup(g, viewName, form, 80, 9)
+}
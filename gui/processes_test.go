@@ -0,0 +1,34 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortProcesses(t *testing.T) {
+	titles := []string{"USER", "PID", "%CPU", "%MEM", "COMMAND"}
+	processes := [][]string{
+		{"root", "1", "0.5", "1.0", "nginx"},
+		{"www", "7", "12.0", "0.2", "nginx: worker"},
+		{"www", "8", "3.1", "4.5", "nginx: worker"},
+	}
+
+	if err := sortProcesses(titles, processes, "%CPU"); err != nil {
+		t.Fatal(err)
+	}
+	if got := []string{processes[0][1], processes[1][1], processes[2][1]}; !reflect.DeepEqual(got, []string{"7", "8", "1"}) {
+		t.Errorf("Expected sorted by cpu. Got %v.", got)
+	}
+
+	if err := sortProcesses(titles, processes, "%MEM"); err != nil {
+		t.Fatal(err)
+	}
+	if got := processes[0][1]; got != "8" {
+		t.Errorf("Expected pid 8 first. Got %s.", got)
+	}
+
+	// ps -ef doesn't have %CPU column
+	if err := sortProcesses([]string{"UID", "PID", "CMD"}, processes, "%CPU"); err == nil {
+		t.Error("Expected error without the column.")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/skanehira/docui/common"
-	"github.com/skanehira/docui/docker"
 )
 
 type searchImageResult struct {
@@ -98,7 +97,7 @@ func (s *searchImageResults) setKeybinding(g *Gui) {
 }
 
 func (s *searchImageResults) entries(g *Gui) {
-	images, err := docker.Client.SearchImage(s.keyword)
+	images, err := g.docker.SearchImage(s.keyword)
 
 	if err != nil {
 		// TODO display error message
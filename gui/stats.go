@@ -0,0 +1,304 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+var (
+	// statsHistorySize number of samples kept for sparklines
+	statsHistorySize = 60
+	// statsRenderInterval interval to render new samples
+	statsRenderInterval = time.Second
+	sparkBlocks         = []rune("▁▂▃▄▅▆▇█")
+)
+
+type containerStats struct {
+	latest docker.Stats
+	// history of cpu%, memory%, network and block I/O bytes per second
+	cpu     []float64
+	mem     []float64
+	netIO   []float64
+	blockIO []float64
+	cancel  context.CancelFunc
+}
+
+// statsMonitor stream stats of running containers
+type statsMonitor struct {
+	mu      sync.Mutex
+	stats   map[string]*containerStats
+	updated bool
+}
+
+func newStatsMonitor() *statsMonitor {
+	return &statsMonitor{
+		stats: make(map[string]*containerStats),
+	}
+}
+
+func appendHistory(history []float64, value float64) []float64 {
+	history = append(history, value)
+	if len(history) > statsHistorySize {
+		history = history[len(history)-statsHistorySize:]
+	}
+	return history
+}
+
+// sync start streaming stats of running containers, and stop the others
+func (m *statsMonitor) sync(g *Gui, running []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	isRunning := make(map[string]bool)
+	for _, id := range running {
+		isRunning[id] = true
+		if _, ok := m.stats[id]; ok {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cs := &containerStats{cancel: cancel}
+		m.stats[id] = cs
+
+		go func(id string) {
+			if err := g.docker.WatchStats(ctx, id, func(s docker.Stats) {
+				m.add(id, s)
+			}); err != nil {
+				common.Logger.Errorf("cannot get container stats %s", err)
+			}
+			// the stream ends when the container is restarted or the daemon fails,
+			// so the entry is removed to start streaming again at the next sync
+			m.remove(id, cs)
+		}(id)
+	}
+
+	for id, s := range m.stats {
+		if !isRunning[id] {
+			s.cancel()
+			delete(m.stats, id)
+			m.updated = true
+		}
+	}
+}
+
+// remove delete the entry of the stopped stream, unless it is already replaced
+func (m *statsMonitor) remove(id string, cs *containerStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cs.cancel()
+	if m.stats[id] == cs {
+		delete(m.stats, id)
+		m.updated = true
+	}
+}
+
+func (m *statsMonitor) add(id string, s docker.Stats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cs, ok := m.stats[id]
+	if !ok {
+		return
+	}
+
+	var netIO, blockIO float64
+	if prev := cs.latest; !prev.Read.IsZero() {
+		if elapsed := s.Read.Sub(prev.Read).Seconds(); elapsed > 0 {
+			netIO = math.Max(0, float64(s.NetworkRx+s.NetworkTx)-float64(prev.NetworkRx+prev.NetworkTx)) / elapsed
+			blockIO = math.Max(0, float64(s.BlockRead+s.BlockWrite)-float64(prev.BlockRead+prev.BlockWrite)) / elapsed
+		}
+	}
+
+	cs.latest = s
+	cs.cpu = appendHistory(cs.cpu, s.CPUPercent)
+	cs.mem = appendHistory(cs.mem, s.MemoryPercent)
+	cs.netIO = appendHistory(cs.netIO, netIO)
+	cs.blockIO = appendHistory(cs.blockIO, blockIO)
+	m.updated = true
+}
+
+// get return copy of the container stats, or nil when no stats
+func (m *statsMonitor) get(id string) *containerStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cs, ok := m.stats[id]
+	if !ok || cs.latest.Read.IsZero() {
+		return nil
+	}
+
+	return &containerStats{
+		latest:  cs.latest,
+		cpu:     append([]float64(nil), cs.cpu...),
+		mem:     append([]float64(nil), cs.mem...),
+		netIO:   append([]float64(nil), cs.netIO...),
+		blockIO: append([]float64(nil), cs.blockIO...),
+	}
+}
+
+// takeUpdated return whether new samples were added since the last call
+func (m *statsMonitor) takeUpdated() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated := m.updated
+	m.updated = false
+	return updated
+}
+
+func (m *statsMonitor) stopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.stats {
+		s.cancel()
+		delete(m.stats, id)
+	}
+}
+
+func (g *Gui) monitoringStats() {
+	common.Logger.Info("start monitoring stats")
+	ticker := time.NewTicker(statsRenderInterval)
+
+LOOP:
+	for {
+		select {
+		case <-ticker.C:
+			if g.state.stats.takeUpdated() {
+				g.app.QueueUpdateDraw(func() {
+					g.containerPanel().setStats(g)
+				})
+			}
+		case <-g.state.stopChans["stats"]:
+			ticker.Stop()
+			break LOOP
+		}
+	}
+	common.Logger.Info("stop monitoring stats")
+}
+
+// sparkline draw values as bars, values are scaled to max.
+// when max is 0, the largest value is used.
+func sparkline(values []float64, max float64) string {
+	if max <= 0 {
+		for _, v := range values {
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	var line strings.Builder
+	for _, v := range values {
+		i := 0
+		if max > 0 {
+			i = int(v / max * float64(len(sparkBlocks)-1))
+		}
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(sparkBlocks) {
+			i = len(sparkBlocks) - 1
+		}
+		line.WriteRune(sparkBlocks[i])
+	}
+
+	return line.String()
+}
+
+func last(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+type statsViewer struct {
+	*tview.TextView
+	container *container
+	stop      chan struct{}
+}
+
+func (v *statsViewer) name() string {
+	return "stats"
+}
+
+func (v *statsViewer) render(g *Gui) {
+	s := g.state.stats.get(v.container.ID)
+	if s == nil {
+		v.SetText(" waiting for stats...")
+		return
+	}
+
+	cpuMax := 100.0
+	for _, cpu := range s.cpu {
+		cpuMax = math.Max(cpuMax, cpu)
+	}
+
+	rows := []string{
+		fmt.Sprintf(" [white::b]CPU[-::-]       %.2f%%", s.latest.CPUPercent),
+		" [green]" + sparkline(s.cpu, cpuMax) + "[-]",
+		"",
+		fmt.Sprintf(" [white::b]Memory[-::-]    %s / %s (%.2f%%)", common.ParseBytesToString(s.latest.MemoryUsage), common.ParseBytesToString(s.latest.MemoryLimit), s.latest.MemoryPercent),
+		" [yellow]" + sparkline(s.mem, 100) + "[-]",
+		"",
+		fmt.Sprintf(" [white::b]Net I/O[-::-]   rx %s / tx %s (%s/s)", common.ParseBytesToString(s.latest.NetworkRx), common.ParseBytesToString(s.latest.NetworkTx), common.ParseBytesToString(uint64(last(s.netIO)))),
+		" [aqua]" + sparkline(s.netIO, 0) + "[-]",
+		"",
+		fmt.Sprintf(" [white::b]Block I/O[-::-] read %s / write %s (%s/s)", common.ParseBytesToString(s.latest.BlockRead), common.ParseBytesToString(s.latest.BlockWrite), common.ParseBytesToString(uint64(last(s.blockIO)))),
+		" [fuchsia]" + sparkline(s.blockIO, 0) + "[-]",
+	}
+
+	v.SetText(strings.Join(rows, "\n"))
+}
+
+func (g *Gui) showContainerStats() {
+	container := g.selectedContainer()
+	if container == nil {
+		common.Logger.Errorf("cannot show container stats: selected container is null")
+		return
+	}
+
+	v := &statsViewer{
+		TextView:  tview.NewTextView().SetDynamicColors(true),
+		container: container,
+		stop:      make(chan struct{}),
+	}
+	v.SetBorder(true).SetTitle(fmt.Sprintf(" stats %s (last %d samples) ", container.Name, statsHistorySize)).SetTitleAlign(tview.AlignLeft)
+
+	v.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || event.Rune() == 'q' {
+			close(v.stop)
+			g.closeAndSwitchPanel(v.name(), "containers")
+		}
+		return event
+	})
+
+	v.render(g)
+	g.pages.AddAndSwitchToPage(v.name(), v, true)
+
+	go func() {
+		ticker := time.NewTicker(statsRenderInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				g.app.QueueUpdateDraw(func() {
+					v.render(g)
+				})
+			case <-v.stop:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,95 @@
+package gui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skanehira/docui/docker"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 50, 100, 200}, 100); got != "▁▄██" {
+		t.Errorf("Expected ▁▄██. Got %s.", got)
+	}
+	if got := sparkline([]float64{0, 1, 2}, 0); got != "▁▄█" {
+		t.Errorf("Expected scaled to largest value. Got %s.", got)
+	}
+	if got := sparkline([]float64{0, 0}, 0); got != "▁▁" {
+		t.Errorf("Expected ▁▁. Got %s.", got)
+	}
+}
+
+func TestStatsMonitor(t *testing.T) {
+	fake := newTestFake()
+	now := time.Now()
+	fake.StatsItems["111111111111"] = []docker.Stats{
+		{Read: now, CPUPercent: 10, NetworkRx: 100, BlockRead: 0},
+		{Read: now.Add(2 * time.Second), CPUPercent: 20, NetworkRx: 300, BlockRead: 50},
+	}
+	g := newTestGui(t, fake)
+	defer g.state.stats.stopAll()
+
+	var s *containerStats
+	for i := 0; i < 100 && (s == nil || len(s.cpu) < 2); i++ {
+		time.Sleep(10 * time.Millisecond)
+		s = g.state.stats.get("111111111111")
+	}
+	if s == nil || len(s.cpu) != 2 {
+		t.Fatalf("Expected 2 samples. Got %v.", s)
+	}
+	if s.latest.CPUPercent != 20 {
+		t.Errorf("Expected latest cpu 20%%. Got %v.", s.latest.CPUPercent)
+	}
+	if got := s.netIO[1]; got != 100 {
+		t.Errorf("Expected network 100 bytes/s. Got %v.", got)
+	}
+	if got := s.blockIO[1]; got != 25 {
+		t.Errorf("Expected block 25 bytes/s. Got %v.", got)
+	}
+
+	// stopped container is not streamed
+	if got := g.state.stats.get("222222222222"); got != nil {
+		t.Errorf("Expected no stats of stopped container. Got %v.", got)
+	}
+
+	g.state.stats.sync(g, nil)
+	if got := g.state.stats.get("111111111111"); got != nil {
+		t.Errorf("Expected stats to be removed. Got %v.", got)
+	}
+}
+
+func TestStatsMonitorRestartStream(t *testing.T) {
+	fake := newTestFake()
+	fake.SetError("WatchStats", errors.New("unexpected EOF"))
+	fake.StatsItems["111111111111"] = []docker.Stats{{Read: time.Now(), CPUPercent: 10}}
+	g := newTestGui(t, fake)
+	defer g.state.stats.stopAll()
+
+	hasEntry := func() bool {
+		g.state.stats.mu.Lock()
+		defer g.state.stats.mu.Unlock()
+		_, ok := g.state.stats.stats["111111111111"]
+		return ok
+	}
+
+	// the failed stream is removed
+	for i := 0; i < 100 && hasEntry(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if hasEntry() {
+		t.Fatal("Expected the entry of the ended stream to be removed.")
+	}
+
+	fake.SetError("WatchStats", nil)
+	g.state.stats.sync(g, []string{"111111111111"})
+
+	var s *containerStats
+	for i := 0; i < 100 && s == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+		s = g.state.stats.get("111111111111")
+	}
+	if s == nil {
+		t.Error("Expected stats after the stream is started again.")
+	}
+}
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"context"
+	"sync"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -14,12 +15,31 @@ var (
 )
 
 type task struct {
-	Name    string
-	Status  string
-	Created string
-	Func    func(ctx context.Context) error
-	Ctx     context.Context
-	Cancel  context.CancelFunc
+	Name     string
+	Status   string
+	Progress string
+	Created  string
+	Func     func(ctx context.Context) error
+	Ctx      context.Context
+	Cancel   context.CancelFunc
+
+	// latestProgress progress reported by the task goroutine, it is copied to Progress when drawn
+	mu             sync.Mutex
+	latestProgress string
+}
+
+// setProgress store the progress reported by the task
+func (t *task) setProgress(progress string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latestProgress = progress
+}
+
+// applyProgress copy the latest progress to Progress, so draws queued out of order show the latest one
+func (t *task) applyProgress() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Progress = t.latestProgress
 }
 
 type tasks struct {
@@ -48,11 +68,9 @@ func (t *tasks) setKeybinding(g *Gui) {
 	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		g.setGlobalKeybinding(event)
 
-		// TODO cancel task
-		switch event.Key() {
-		}
-
 		switch event.Rune() {
+		case 'c':
+			g.cancelTask()
 		}
 
 		return event
@@ -70,6 +88,7 @@ func (t *tasks) setEntries(g *Gui) {
 	headers := []string{
 		"Name",
 		"Status",
+		"Progress",
 		"Created",
 	}
 
@@ -95,7 +114,12 @@ func (t *tasks) setEntries(g *Gui) {
 			SetMaxWidth(1).
 			SetExpansion(1))
 
-		table.SetCell(i+1, 2, tview.NewTableCell(task.Created).
+		table.SetCell(i+1, 2, tview.NewTableCell(task.Progress).
+			SetTextColor(tcell.ColorLightGreen).
+			SetMaxWidth(1).
+			SetExpansion(1))
+
+		table.SetCell(i+1, 3, tview.NewTableCell(task.Created).
 			SetTextColor(tcell.ColorLightGreen).
 			SetMaxWidth(1).
 			SetExpansion(1))
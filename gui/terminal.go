@@ -0,0 +1,288 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// terminalPrefixKey prefix key of the terminal pane commands
+var terminalPrefixKey = tcell.KeyCtrlRightSq
+
+// terminalSession exec session shown as a tab of the terminal pane
+type terminalSession struct {
+	title   string
+	session docker.ExecSession
+	vt      *vterm
+	tty     bool
+	exited  bool
+	// width and height the tty size sent to docker
+	width, height int
+}
+
+// terminal pane which shows exec sessions as tabs
+type terminal struct {
+	*tview.Flex
+	tabs     *tview.TextView
+	view     *terminalView
+	status   *tview.TextView
+	sessions []*terminalSession
+	current  int
+	// prefix the prefix key was pressed
+	prefix bool
+}
+
+func (t *terminal) name() string {
+	return "terminal"
+}
+
+// terminalView draw the screen of the current session
+type terminalView struct {
+	*tview.Box
+	t *terminal
+	g *Gui
+}
+
+func (g *Gui) terminal() *terminal {
+	if g.state.terminal != nil {
+		return g.state.terminal
+	}
+
+	t := &terminal{
+		Flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		tabs:   tview.NewTextView().SetDynamicColors(true).SetWrap(false),
+		status: tview.NewTextView().SetDynamicColors(true),
+	}
+	t.view = &terminalView{Box: tview.NewBox(), t: t, g: g}
+	t.AddItem(t.tabs, 1, 0, false).
+		AddItem(t.view, 0, 1, true).
+		AddItem(t.status, 1, 0, false)
+
+	// Ctrl+C stops the application unless it is captured here
+	g.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyCtrlC && t.view.HasFocus() {
+			t.view.InputHandler()(event, func(tview.Primitive) {})
+			return nil
+		}
+		return event
+	})
+
+	g.state.terminal = t
+	return t
+}
+
+// openTerminal start exec session and show it as a new tab
+func (g *Gui) openTerminal(container *container, opt docker.ExecOptions) {
+	session, err := g.docker.StartExecSession(container.ID, opt)
+	if err != nil {
+		common.Logger.Errorf("cannot exec container %s", err)
+		g.message(err.Error(), "OK", "containers", func() {})
+		return
+	}
+
+	t := g.terminal()
+	s := &terminalSession{
+		title:   container.Name + " " + common.JoinShellWords(opt.Cmd),
+		session: session,
+		vt:      newVterm(80, 24),
+		tty:     opt.Tty,
+	}
+	s.vt.newline = !opt.Tty
+
+	t.sessions = append(t.sessions, s)
+	t.current = len(t.sessions) - 1
+	go t.readSession(g, s)
+
+	g.showTerminal()
+}
+
+// showTerminal show the terminal pane
+func (g *Gui) showTerminal() {
+	t := g.terminal()
+	if len(t.sessions) == 0 {
+		return
+	}
+	t.render()
+	g.pages.AddAndSwitchToPage(t.name(), t, true)
+	g.app.SetFocus(t.view)
+}
+
+// hideTerminal hide the terminal pane, sessions keep running
+func (g *Gui) hideTerminal() {
+	g.closeAndSwitchPanel(g.terminal().name(), "containers")
+}
+
+func (t *terminal) readSession(g *Gui, s *terminalSession) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.session.Read(buf)
+		if n > 0 {
+			s.vt.Write(buf[:n])
+			if replies := s.vt.takeReplies(); len(replies) > 0 {
+				if _, err := s.session.Write(replies); err != nil {
+					common.Logger.Errorf("cannot write to exec session %s", err)
+				}
+			}
+			g.app.Draw()
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	g.app.QueueUpdateDraw(func() {
+		s.exited = true
+		s.session.Close()
+		t.render()
+	})
+}
+
+func (t *terminal) currentSession() *terminalSession {
+	if t.current < 0 || t.current >= len(t.sessions) {
+		return nil
+	}
+	return t.sessions[t.current]
+}
+
+func (t *terminal) render() {
+	var tabs []string
+	for i, s := range t.sessions {
+		title := fmt.Sprintf(" %d:%s ", i+1, s.title)
+		if s.exited {
+			title += "(exited) "
+		}
+		title = tview.Escape(title)
+		if i == t.current {
+			title = "[::r]" + title + "[::-]"
+		}
+		tabs = append(tabs, title)
+	}
+	t.tabs.SetText(strings.Join(tabs, "|"))
+
+	status := " Ctrl+] then n: next tab, p: previous tab, x: close session, q: hide, ]: send Ctrl+]"
+	if t.prefix {
+		status = " [yellow]Ctrl+][-] n: next tab, p: previous tab, x: close session, q: hide, ]: send Ctrl+]"
+	}
+	if s := t.currentSession(); s != nil && s.exited {
+		status = " [red]session exited[-], Ctrl+] then x: close session, q: hide"
+	}
+	t.status.SetText(status)
+}
+
+// closeSession close the current session and remove its tab
+func (t *terminal) closeSession(g *Gui) {
+	s := t.currentSession()
+	if s == nil {
+		return
+	}
+	if err := s.session.Close(); err != nil {
+		common.Logger.Errorf("cannot close exec session %s", err)
+	}
+
+	t.sessions = append(t.sessions[:t.current], t.sessions[t.current+1:]...)
+	if t.current >= len(t.sessions) {
+		t.current = len(t.sessions) - 1
+	}
+	if len(t.sessions) == 0 {
+		g.hideTerminal()
+		return
+	}
+	t.render()
+}
+
+// command run the pane command after the prefix key
+func (t *terminal) command(g *Gui, r rune) {
+	switch r {
+	case 'n':
+		if len(t.sessions) > 0 {
+			t.current = (t.current + 1) % len(t.sessions)
+		}
+	case 'p':
+		if len(t.sessions) > 0 {
+			t.current = (t.current + len(t.sessions) - 1) % len(t.sessions)
+		}
+	case 'x':
+		t.closeSession(g)
+		return
+	case 'q':
+		g.hideTerminal()
+		return
+	case ']':
+		t.send([]byte{byte(terminalPrefixKey)}, nil)
+	}
+	t.render()
+}
+
+// send write the input to the current session, the input is echoed locally without tty
+func (t *terminal) send(b []byte, event *tcell.EventKey) {
+	s := t.currentSession()
+	if s == nil || s.exited || len(b) == 0 {
+		return
+	}
+
+	if !s.tty {
+		switch {
+		case event != nil && event.Key() == tcell.KeyEnter:
+			b = []byte("\n")
+			s.vt.Write(b)
+		case event != nil && (event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2):
+			s.vt.Write([]byte("\b \b"))
+		default:
+			s.vt.Write(b)
+		}
+	}
+
+	if _, err := s.session.Write(b); err != nil {
+		common.Logger.Errorf("cannot write to exec session %s", err)
+	}
+}
+
+func (v *terminalView) Draw(screen tcell.Screen) {
+	v.Box.DrawForSubclass(screen, v)
+	x, y, width, height := v.GetInnerRect()
+
+	s := v.t.currentSession()
+	if s == nil || width <= 0 || height <= 0 {
+		return
+	}
+
+	if s.width != width || s.height != height {
+		s.width, s.height = width, height
+		s.vt.resize(width, height)
+		session := s.session
+		go func() {
+			if err := session.Resize(uint(height), uint(width)); err != nil {
+				common.Logger.Errorf("cannot resize exec session %s", err)
+			}
+		}()
+	}
+
+	s.vt.draw(screen, x, y, v.HasFocus() && !s.exited)
+}
+
+func (v *terminalView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return v.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		t := v.t
+		if t.prefix {
+			t.prefix = false
+			t.command(v.g, event.Rune())
+			return
+		}
+
+		if event.Key() == terminalPrefixKey {
+			t.prefix = true
+			t.render()
+			return
+		}
+
+		s := t.currentSession()
+		if s == nil {
+			return
+		}
+		t.send(keyBytes(event, s.vt.applicationCursor()), event)
+	})
+}
@@ -0,0 +1,61 @@
+package gui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skanehira/docui/docker"
+)
+
+func TestTerminalSession(t *testing.T) {
+	fake := newTestFake()
+	g := newTestGui(t, fake)
+
+	container := g.state.resources.containers[0]
+	g.openTerminal(container, docker.ExecOptions{Cmd: []string{"cat"}, Tty: true})
+
+	term := g.terminal()
+	if len(term.sessions) != 1 {
+		t.Fatalf("Expected 1 session. Got %d.", len(term.sessions))
+	}
+	s := term.currentSession()
+	if s.title != "web cat" {
+		t.Errorf("Expected title web cat. Got %s.", s.title)
+	}
+
+	// the fake session echoes back the input
+	term.send([]byte("hello"), nil)
+	deadline := time.Now().Add(time.Second)
+	for s.vt.text() != "hello" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := s.vt.text(); got != "hello" {
+		t.Errorf("Expected hello. Got %q.", got)
+	}
+
+	term.closeSession(g)
+	if len(term.sessions) != 0 {
+		t.Errorf("Expected the session is closed. Got %d sessions.", len(term.sessions))
+	}
+}
+
+func TestTerminalCommand(t *testing.T) {
+	g := newTestGui(t, newTestFake())
+
+	for _, c := range g.state.resources.containers {
+		g.openTerminal(c, docker.ExecOptions{Cmd: []string{"sh"}, Tty: true})
+	}
+
+	term := g.terminal()
+	if term.current != 1 {
+		t.Fatalf("Expected the last session is current. Got %d.", term.current)
+	}
+	term.command(g, 'n')
+	if term.current != 0 {
+		t.Errorf("Expected next tab wraps around. Got %d.", term.current)
+	}
+	term.command(g, 'p')
+	if term.current != 1 {
+		t.Errorf("Expected previous tab wraps around. Got %d.", term.current)
+	}
+}
@@ -0,0 +1,233 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/rivo/tview"
+	"github.com/skanehira/docui/common"
+	"github.com/skanehira/docui/docker"
+)
+
+// backupFileName return the default backup file name, e.g. "data-20190102-150405.tar.gz"
+func backupFileName(volume string, compress bool, now time.Time) string {
+	name := fmt.Sprintf("%s-%s.tar", volume, now.Format("20060102-150405"))
+	if compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// showVolumeFiles browse the volume through the helper container
+func (g *Gui) showVolumeFiles() {
+	volume := g.selectedVolume()
+	if volume == nil {
+		common.Logger.Errorf("cannot browse volume files: selected volume is null")
+		return
+	}
+
+	go func() {
+		id, err := g.docker.CreateVolumeHelper(context.Background(), volume.Name, true)
+		if err == nil {
+			// files are listed with find in the running helper, stopped helper sends the whole volume
+			if err := g.docker.StartContainer(id); err != nil {
+				common.Logger.Errorf("cannot start volume helper container %s", err)
+			}
+		}
+		g.app.QueueUpdateDraw(func() {
+			if err != nil {
+				common.Logger.Errorf("cannot create volume helper container %s", err)
+				g.message(err.Error(), "OK", "volumes", func() {})
+				return
+			}
+
+			g.showFiles(&fileBrowser{
+				id:       id,
+				label:    volume.Name,
+				root:     docker.VolumeHelperMount,
+				readOnly: true,
+				panel:    "volumes",
+				onClose: func() {
+					go func() {
+						g.docker.KillContainer(id, "KILL")
+						if err := g.docker.RemoveContainer(id); err != nil {
+							common.Logger.Errorf("cannot remove volume helper container %s", err)
+						}
+					}()
+				},
+			})
+		})
+	}()
+}
+
+func (g *Gui) backupVolumeForm() {
+	volume := g.selectedVolume()
+	if volume == nil {
+		common.Logger.Errorf("cannot backup volume: selected volume is null")
+		return
+	}
+
+	wd, _ := os.Getwd()
+
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle("Backup volume " + volume.Name)
+	form.AddInputField("Host path", filepath.Join(wd, backupFileName(volume.Name, true, time.Now())), inputWidth, nil, nil).
+		AddCheckbox("Gzip", true, func(checked bool) {
+			// keep the extension consistent with the compression
+			field := form.GetFormItemByLabel("Host path").(*tview.InputField)
+			path := strings.TrimSuffix(field.GetText(), ".gz")
+			if checked && strings.HasSuffix(path, ".tar") {
+				path += ".gz"
+			}
+			field.SetText(path)
+		}).
+		AddButton("Backup", func() {
+			path := form.GetFormItemByLabel("Host path").(*tview.InputField).GetText()
+			compress := form.GetFormItemByLabel("Gzip").(*tview.Checkbox).IsChecked()
+			g.closeAndSwitchPanel("form", "volumes")
+			g.backupVolume(volume.Name, path, compress)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "volumes")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 9), true).ShowPage("main")
+}
+
+func (g *Gui) backupVolume(volume, path string, compress bool) {
+	g.startProgressTask(fmt.Sprintf("backup volume %s to %s", volume, path), func(ctx context.Context, progress func(string)) error {
+		err := g.docker.BackupVolume(ctx, volume, path, compress, func(n int64) {
+			progress(common.ParseBytesToString(uint64(n)))
+		})
+		if err != nil {
+			common.Logger.Errorf("cannot backup volume %s", err)
+		}
+		return err
+	})
+}
+
+func (g *Gui) restoreVolumeForm() {
+	name := ""
+	if volume := g.selectedVolume(); volume != nil {
+		name = volume.Name
+	}
+
+	title := "Restore volume"
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+	form.AddInputField("Host path", "", inputWidth, nil, nil).
+		AddInputField("Volume", name, inputWidth, nil, nil).
+		AddButton("Restore", func() {
+			path := form.GetFormItemByLabel("Host path").(*tview.InputField).GetText()
+			volume := strings.TrimSpace(form.GetFormItemByLabel("Volume").(*tview.InputField).GetText())
+			if _, err := os.Stat(path); err != nil {
+				form.SetTitle(title + " [red]" + tview.Escape(err.Error()) + "[-]")
+				return
+			}
+			if volume == "" {
+				form.SetTitle(title + " [red]volume is required[-]")
+				return
+			}
+
+			g.closeAndSwitchPanel("form", "volumes")
+
+			// files of the existing volume are overwritten
+			if _, err := g.docker.InspectVolume(volume); err == nil {
+				g.confirm(fmt.Sprintf("Volume %s exists, its files are overwritten. Do you want to restore?", volume), "Restore", "volumes", func() {
+					g.restoreVolume(volume, path)
+				})
+				return
+			}
+			g.restoreVolume(volume, path)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "volumes")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 9), true).ShowPage("main")
+}
+
+func (g *Gui) restoreVolume(volume, path string) {
+	g.startProgressTask(fmt.Sprintf("restore %s to volume %s", path, volume), func(ctx context.Context, progress func(string)) error {
+		err := g.docker.RestoreVolume(ctx, volume, path, func(n int64) {
+			progress(common.ParseBytesToString(uint64(n)))
+		})
+		if err != nil {
+			common.Logger.Errorf("cannot restore volume %s", err)
+			return err
+		}
+		g.volumePanel().updateEntries(g)
+		return nil
+	})
+}
+
+func (g *Gui) cloneVolumeForm() {
+	volume := g.selectedVolume()
+	if volume == nil {
+		common.Logger.Errorf("cannot clone volume: selected volume is null")
+		return
+	}
+
+	title := "Clone volume " + volume.Name
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitleAlign(tview.AlignLeft)
+	form.SetTitle(title)
+	form.AddInputField("Name", volume.Name+"-clone", inputWidth, nil, nil).
+		AddButton("Clone", func() {
+			name := strings.TrimSpace(form.GetFormItemByLabel("Name").(*tview.InputField).GetText())
+			if name == "" {
+				form.SetTitle(title + " [red]name is required[-]")
+				return
+			}
+			g.closeAndSwitchPanel("form", "volumes")
+			g.confirmCloneVolume(volume.Name, name)
+		}).
+		AddButton("Cancel", func() {
+			g.closeAndSwitchPanel("form", "volumes")
+		})
+
+	g.pages.AddAndSwitchToPage("form", g.modal(form, 80, 7), true).ShowPage("main")
+}
+
+// confirmCloneVolume clone the volume, and confirm when running containers may write to it
+func (g *Gui) confirmCloneVolume(src, dst string) {
+	containers, err := g.docker.Containers(types.ContainerListOptions{})
+	if err != nil {
+		common.Logger.Errorf("cannot list containers %s", err)
+		g.message(err.Error(), "OK", "volumes", func() {})
+		return
+	}
+
+	writers := docker.VolumeWriters(containers, src)
+	if len(writers) == 0 {
+		g.cloneVolume(src, dst)
+		return
+	}
+
+	message := fmt.Sprintf("Running containers %s mount %s read-write, the clone may be inconsistent. Do you want to clone?",
+		strings.Join(writers, ", "), src)
+	g.confirm(message, "Clone", "volumes", func() {
+		g.cloneVolume(src, dst)
+	})
+}
+
+func (g *Gui) cloneVolume(src, dst string) {
+	g.startProgressTask(fmt.Sprintf("clone volume %s to %s", src, dst), func(ctx context.Context, progress func(string)) error {
+		if err := g.docker.CloneVolume(ctx, src, dst, progress); err != nil {
+			common.Logger.Errorf("cannot clone volume %s", err)
+			return err
+		}
+		g.volumePanel().updateEntries(g)
+		return nil
+	})
+}
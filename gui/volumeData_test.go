@@ -0,0 +1,45 @@
+package gui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestBackupFileName(t *testing.T) {
+	now := time.Date(2019, 1, 2, 15, 4, 5, 0, time.UTC)
+	if got := backupFileName("data", true, now); got != "data-20190102-150405.tar.gz" {
+		t.Errorf("Expected data-20190102-150405.tar.gz. Got %s.", got)
+	}
+	if got := backupFileName("data", false, now); got != "data-20190102-150405.tar" {
+		t.Errorf("Expected data-20190102-150405.tar. Got %s.", got)
+	}
+}
+
+func TestFileBrowserContainerPath(t *testing.T) {
+	b := &fileBrowser{root: "/volume"}
+	if got := b.containerPath("/"); got != "/volume" {
+		t.Errorf("Expected /volume. Got %s.", got)
+	}
+	if got := b.containerPath("/conf/app.ini"); got != "/volume/conf/app.ini" {
+		t.Errorf("Expected /volume/conf/app.ini. Got %s.", got)
+	}
+}
+
+func TestConfirmCloneVolume(t *testing.T) {
+	fake := newTestFake()
+	fake.ContainerItems[1].State = "running"
+	fake.ContainerItems[1].Mounts = []types.MountPoint{{Type: mount.TypeVolume, Name: "pgdata", RW: true}}
+	g := newTestGui(t, fake)
+
+	g.confirmCloneVolume("pgdata", "pgdata-clone")
+
+	if !g.pages.HasPage("modal") {
+		t.Error("Expected the confirmation for the volume mounted read-write.")
+	}
+	if calls := fake.CallsOf("CloneVolume"); len(calls) != 0 {
+		t.Errorf("Expected the clone waits for the confirmation. Got %v.", calls)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/skanehira/docui/common"
-	"github.com/skanehira/docui/docker"
 )
 
 var replacer = strings.NewReplacer("T", " ", "Z", "")
@@ -55,6 +54,14 @@ func (v *volumes) setKeybinding(g *Gui) {
 			g.removeVolume()
 		case 'c':
 			g.createVolumeForm()
+		case 'b':
+			g.showVolumeFiles()
+		case 'B':
+			g.backupVolumeForm()
+		case 'R':
+			g.restoreVolumeForm()
+		case 'C':
+			g.cloneVolumeForm()
 		}
 
 		return event
@@ -62,7 +69,7 @@ func (v *volumes) setKeybinding(g *Gui) {
 }
 
 func (v *volumes) entries(g *Gui) {
-	volumes, err := docker.Client.Volumes()
+	volumes, err := g.docker.Volumes()
 	if err != nil {
 		common.Logger.Error(err)
 		return
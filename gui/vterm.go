@@ -0,0 +1,714 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// parser states of vterm
+const (
+	vtGround = iota
+	vtEscape
+	vtCharset
+	vtCSI
+	vtString
+	vtStringEscape
+)
+
+// vtCell a cell of the terminal screen, r is 0 for the right half of a wide character
+type vtCell struct {
+	r     rune
+	style tcell.Style
+}
+
+// vterm minimal VT100/xterm compatible terminal emulator
+type vterm struct {
+	mu sync.Mutex
+
+	width, height int
+	cells         [][]vtCell
+	// mainCells main screen while the alternate screen is shown
+	mainCells [][]vtCell
+
+	x, y   int
+	style  tcell.Style
+	saved  [2]int
+	savedS tcell.Style
+	// wrapNext the next character is written to the next line
+	wrapNext bool
+	// scroll region, inclusive
+	top, bottom int
+
+	cursorHidden bool
+	appCursor    bool
+	// newline treat LF as CRLF, for the output without tty
+	newline bool
+
+	state   int
+	params  []byte
+	pending []byte
+	// replies responses to the queries of the application
+	replies []byte
+}
+
+func newVterm(width, height int) *vterm {
+	t := &vterm{}
+	t.setSize(width, height)
+	return t
+}
+
+func (t *vterm) blank() vtCell {
+	_, bg, _ := t.style.Decompose()
+	return vtCell{r: ' ', style: tcell.StyleDefault.Background(bg)}
+}
+
+func (t *vterm) newLine(width int) []vtCell {
+	line := make([]vtCell, width)
+	for i := range line {
+		line[i] = vtCell{r: ' ', style: tcell.StyleDefault}
+	}
+	return line
+}
+
+// size return the size of the screen
+func (t *vterm) size() (int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.width, t.height
+}
+
+// resize change the screen size, the bottom lines are kept when the height shrinks
+func (t *vterm) resize(width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setSize(width, height)
+}
+
+func (t *vterm) setSize(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	resizeCells := func(old [][]vtCell, shift int) [][]vtCell {
+		cells := make([][]vtCell, height)
+		for y := range cells {
+			cells[y] = t.newLine(width)
+			if y+shift < len(old) {
+				copy(cells[y], old[y+shift])
+			}
+		}
+		return cells
+	}
+
+	shift := 0
+	if t.y >= height {
+		shift = t.y - height + 1
+	}
+	t.cells = resizeCells(t.cells, shift)
+	if t.mainCells != nil {
+		t.mainCells = resizeCells(t.mainCells, 0)
+	}
+
+	t.width, t.height = width, height
+	t.y -= shift
+	t.x = min(t.x, width-1)
+	t.wrapNext = false
+	t.top, t.bottom = 0, height-1
+}
+
+func (t *vterm) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, b := range p {
+		t.input(b)
+	}
+	return len(p), nil
+}
+
+// applicationCursor report whether the cursor keys are in the application mode
+func (t *vterm) applicationCursor() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.appCursor
+}
+
+// takeReplies return the responses which should be sent to the application
+func (t *vterm) takeReplies() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	replies := t.replies
+	t.replies = nil
+	return replies
+}
+
+func (t *vterm) input(b byte) {
+	switch t.state {
+	case vtGround:
+		if len(t.pending) > 0 || b >= 0x80 {
+			t.pending = append(t.pending, b)
+			if utf8.FullRune(t.pending) {
+				r, _ := utf8.DecodeRune(t.pending)
+				t.pending = t.pending[:0]
+				t.put(r)
+			}
+			return
+		}
+		t.control(b)
+	case vtEscape:
+		t.escape(b)
+	case vtCharset:
+		// character set designation is ignored
+		t.state = vtGround
+	case vtCSI:
+		switch {
+		case b >= 0x30 && b <= 0x3f:
+			t.params = append(t.params, b)
+		case b >= 0x20 && b <= 0x2f:
+			// intermediate bytes are ignored
+		case b >= 0x40 && b <= 0x7e:
+			t.state = vtGround
+			t.csi(b)
+		case b == 0x18 || b == 0x1a:
+			t.state = vtGround
+		default:
+			t.control(b)
+		}
+	case vtString:
+		// OSC, DCS and so on are ignored until BEL or ST
+		switch b {
+		case 0x07:
+			t.state = vtGround
+		case 0x1b:
+			t.state = vtStringEscape
+		}
+	case vtStringEscape:
+		t.state = vtGround
+	}
+}
+
+func (t *vterm) control(b byte) {
+	switch b {
+	case 0x08:
+		if t.x > 0 {
+			t.x--
+		}
+		t.wrapNext = false
+	case 0x09:
+		t.x = min((t.x/8+1)*8, t.width-1)
+	case 0x0a, 0x0b, 0x0c:
+		t.lineFeed()
+		if t.newline {
+			t.x = 0
+		}
+	case 0x0d:
+		t.x = 0
+		t.wrapNext = false
+	case 0x1b:
+		t.state = vtEscape
+		t.params = t.params[:0]
+	default:
+		if b >= 0x20 && b < 0x7f {
+			t.put(rune(b))
+		}
+	}
+}
+
+func (t *vterm) escape(b byte) {
+	t.state = vtGround
+	switch b {
+	case '[':
+		t.state = vtCSI
+	case ']', 'P', 'X', '^', '_':
+		t.state = vtString
+	case '(', ')', '*', '+':
+		t.state = vtCharset
+	case '7':
+		t.saveCursor()
+	case '8':
+		t.restoreCursor()
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.x = 0
+		t.lineFeed()
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.reset()
+	}
+}
+
+// reset clear the screen and the modes
+func (t *vterm) reset() {
+	t.cells, t.mainCells = nil, nil
+	t.x, t.y = 0, 0
+	t.style, t.savedS = tcell.StyleDefault, tcell.StyleDefault
+	t.saved = [2]int{}
+	t.cursorHidden, t.appCursor = false, false
+	t.setSize(t.width, t.height)
+}
+
+func (t *vterm) put(r rune) {
+	width := runewidth.RuneWidth(r)
+	if width == 0 {
+		// combining characters are not supported
+		return
+	}
+
+	if t.wrapNext || (width == 2 && t.x == t.width-1) {
+		t.x = 0
+		t.lineFeed()
+		t.wrapNext = false
+	}
+
+	t.cells[t.y][t.x] = vtCell{r: r, style: t.style}
+	if width == 2 && t.x+1 < t.width {
+		t.cells[t.y][t.x+1] = vtCell{r: 0, style: t.style}
+	}
+
+	t.x += width
+	if t.x >= t.width {
+		t.x = t.width - 1
+		t.wrapNext = true
+	}
+}
+
+func (t *vterm) lineFeed() {
+	t.wrapNext = false
+	if t.y == t.bottom {
+		t.scrollUp(1)
+	} else if t.y < t.height-1 {
+		t.y++
+	}
+}
+
+func (t *vterm) reverseIndex() {
+	t.wrapNext = false
+	if t.y == t.top {
+		t.scrollDown(1)
+	} else if t.y > 0 {
+		t.y--
+	}
+}
+
+// scrollUp scroll the scroll region up by n lines
+func (t *vterm) scrollUp(n int) {
+	t.deleteLines(t.top, n)
+}
+
+// scrollDown scroll the scroll region down by n lines
+func (t *vterm) scrollDown(n int) {
+	t.insertLines(t.top, n)
+}
+
+// insertLines insert n blank lines at y in the scroll region
+func (t *vterm) insertLines(y, n int) {
+	n = min(n, t.bottom-y+1)
+	copy(t.cells[y+n:t.bottom+1], t.cells[y:t.bottom+1-n])
+	for i := y; i < y+n; i++ {
+		t.cells[i] = t.blankLine()
+	}
+}
+
+// deleteLines delete n lines at y in the scroll region
+func (t *vterm) deleteLines(y, n int) {
+	n = min(n, t.bottom-y+1)
+	copy(t.cells[y:t.bottom+1-n], t.cells[y+n:t.bottom+1])
+	for i := t.bottom + 1 - n; i <= t.bottom; i++ {
+		t.cells[i] = t.blankLine()
+	}
+}
+
+func (t *vterm) blankLine() []vtCell {
+	line := make([]vtCell, t.width)
+	blank := t.blank()
+	for i := range line {
+		line[i] = blank
+	}
+	return line
+}
+
+// erase fill cells of the line from x1 to x2 (exclusive) with blank
+func (t *vterm) erase(y, x1, x2 int) {
+	blank := t.blank()
+	for x := max(x1, 0); x < min(x2, t.width); x++ {
+		t.cells[y][x] = blank
+	}
+}
+
+func (t *vterm) saveCursor() {
+	t.saved = [2]int{t.x, t.y}
+	t.savedS = t.style
+}
+
+func (t *vterm) restoreCursor() {
+	t.x, t.y = min(t.saved[0], t.width-1), min(t.saved[1], t.height-1)
+	t.style = t.savedS
+	t.wrapNext = false
+}
+
+func (t *vterm) moveTo(x, y int) {
+	t.x = max(0, min(x, t.width-1))
+	t.y = max(0, min(y, t.height-1))
+	t.wrapNext = false
+}
+
+// csiParams parse parameters of the control sequence, and return the private marker
+func (t *vterm) csiParams() ([]int, byte) {
+	s := string(t.params)
+	var private byte
+	if s != "" && strings.IndexByte("?<=>", s[0]) >= 0 {
+		private, s = s[0], s[1:]
+	}
+
+	var params []int
+	if s == "" {
+		return params, private
+	}
+	for _, field := range strings.Split(strings.Replace(s, ":", ";", -1), ";") {
+		n, _ := strconv.Atoi(field)
+		params = append(params, n)
+	}
+	return params, private
+}
+
+func (t *vterm) csi(final byte) {
+	params, private := t.csiParams()
+	param := func(i, def int) int {
+		if i < len(params) && params[i] > 0 {
+			return params[i]
+		}
+		return def
+	}
+
+	if private == '?' {
+		if final == 'h' || final == 'l' {
+			for _, mode := range params {
+				t.setMode(mode, final == 'h')
+			}
+		}
+		return
+	}
+	if private != 0 {
+		return
+	}
+
+	switch final {
+	case '@':
+		n := min(param(0, 1), t.width-t.x)
+		line := t.cells[t.y]
+		copy(line[t.x+n:], line[t.x:])
+		t.erase(t.y, t.x, t.x+n)
+	case 'A':
+		t.moveTo(t.x, max(t.y-param(0, 1), min(t.top, t.y)))
+	case 'B', 'e':
+		t.moveTo(t.x, min(t.y+param(0, 1), max(t.bottom, t.y)))
+	case 'C', 'a':
+		t.moveTo(t.x+param(0, 1), t.y)
+	case 'D':
+		t.moveTo(t.x-param(0, 1), t.y)
+	case 'E':
+		t.moveTo(0, t.y+param(0, 1))
+	case 'F':
+		t.moveTo(0, t.y-param(0, 1))
+	case 'G', '`':
+		t.moveTo(param(0, 1)-1, t.y)
+	case 'd':
+		t.moveTo(t.x, param(0, 1)-1)
+	case 'H', 'f':
+		t.moveTo(param(1, 1)-1, param(0, 1)-1)
+	case 'J':
+		switch param(0, 0) {
+		case 0:
+			t.erase(t.y, t.x, t.width)
+			for y := t.y + 1; y < t.height; y++ {
+				t.erase(y, 0, t.width)
+			}
+		case 1:
+			t.erase(t.y, 0, t.x+1)
+			for y := 0; y < t.y; y++ {
+				t.erase(y, 0, t.width)
+			}
+		case 2, 3:
+			for y := 0; y < t.height; y++ {
+				t.erase(y, 0, t.width)
+			}
+		}
+	case 'K':
+		switch param(0, 0) {
+		case 0:
+			t.erase(t.y, t.x, t.width)
+		case 1:
+			t.erase(t.y, 0, t.x+1)
+		case 2:
+			t.erase(t.y, 0, t.width)
+		}
+	case 'L':
+		if t.y >= t.top && t.y <= t.bottom {
+			t.insertLines(t.y, param(0, 1))
+			t.x = 0
+		}
+	case 'M':
+		if t.y >= t.top && t.y <= t.bottom {
+			t.deleteLines(t.y, param(0, 1))
+			t.x = 0
+		}
+	case 'P':
+		n := min(param(0, 1), t.width-t.x)
+		line := t.cells[t.y]
+		copy(line[t.x:], line[t.x+n:])
+		t.erase(t.y, t.width-n, t.width)
+	case 'X':
+		t.erase(t.y, t.x, t.x+param(0, 1))
+	case 'S':
+		t.scrollUp(param(0, 1))
+	case 'T':
+		t.scrollDown(param(0, 1))
+	case 'm':
+		t.sgr(params)
+	case 'r':
+		top, bottom := param(0, 1)-1, param(1, t.height)-1
+		if top < bottom && bottom < t.height {
+			t.top, t.bottom = top, bottom
+			t.moveTo(0, 0)
+		}
+	case 's':
+		t.saveCursor()
+	case 'u':
+		t.restoreCursor()
+	case 'n':
+		switch param(0, 0) {
+		case 5:
+			t.replies = append(t.replies, "\x1b[0n"...)
+		case 6:
+			t.replies = append(t.replies, fmt.Sprintf("\x1b[%d;%dR", t.y+1, t.x+1)...)
+		}
+	case 'c':
+		t.replies = append(t.replies, "\x1b[?1;2c"...)
+	}
+}
+
+func (t *vterm) setMode(mode int, on bool) {
+	switch mode {
+	case 1:
+		t.appCursor = on
+	case 25:
+		t.cursorHidden = !on
+	case 47, 1047, 1049:
+		if on == (t.mainCells != nil) {
+			return
+		}
+		if on {
+			if mode == 1049 {
+				t.saveCursor()
+			}
+			t.mainCells = t.cells
+			t.cells = make([][]vtCell, t.height)
+			for y := range t.cells {
+				t.cells[y] = t.newLine(t.width)
+			}
+		} else {
+			t.cells, t.mainCells = t.mainCells, nil
+			if mode == 1049 {
+				t.restoreCursor()
+			}
+		}
+	}
+}
+
+// sgr set graphic rendition
+func (t *vterm) sgr(params []int) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+
+	for i := 0; i < len(params); i++ {
+		switch p := params[i]; {
+		case p == 0:
+			t.style = tcell.StyleDefault
+		case p == 1:
+			t.style = t.style.Bold(true)
+		case p == 2:
+			t.style = t.style.Dim(true)
+		case p == 3:
+			t.style = t.style.Italic(true)
+		case p == 4:
+			t.style = t.style.Underline(true)
+		case p == 5:
+			t.style = t.style.Blink(true)
+		case p == 7:
+			t.style = t.style.Reverse(true)
+		case p == 22:
+			t.style = t.style.Bold(false).Dim(false)
+		case p == 23:
+			t.style = t.style.Italic(false)
+		case p == 24:
+			t.style = t.style.Underline(false)
+		case p == 25:
+			t.style = t.style.Blink(false)
+		case p == 27:
+			t.style = t.style.Reverse(false)
+		case p >= 30 && p <= 37:
+			t.style = t.style.Foreground(tcell.PaletteColor(p - 30))
+		case p >= 90 && p <= 97:
+			t.style = t.style.Foreground(tcell.PaletteColor(p - 90 + 8))
+		case p == 39:
+			t.style = t.style.Foreground(tcell.ColorDefault)
+		case p >= 40 && p <= 47:
+			t.style = t.style.Background(tcell.PaletteColor(p - 40))
+		case p >= 100 && p <= 107:
+			t.style = t.style.Background(tcell.PaletteColor(p - 100 + 8))
+		case p == 49:
+			t.style = t.style.Background(tcell.ColorDefault)
+		case p == 38 || p == 48:
+			var color tcell.Color
+			color, i = extendedColor(params, i)
+			if p == 38 {
+				t.style = t.style.Foreground(color)
+			} else {
+				t.style = t.style.Background(color)
+			}
+		}
+	}
+}
+
+// extendedColor parse 256 colors "5;n" or true colors "2;r;g;b" after params[i],
+// and return the color and the index of the last parameter
+func extendedColor(params []int, i int) (tcell.Color, int) {
+	if i+1 >= len(params) {
+		return tcell.ColorDefault, i
+	}
+	switch params[i+1] {
+	case 5:
+		if i+2 < len(params) {
+			return tcell.PaletteColor(params[i+2]), i + 2
+		}
+	case 2:
+		if i+4 < len(params) {
+			return tcell.NewRGBColor(int32(params[i+2]), int32(params[i+3]), int32(params[i+4])), i + 4
+		}
+	}
+	return tcell.ColorDefault, len(params)
+}
+
+// draw the screen into the rectangle
+func (t *vterm) draw(screen tcell.Screen, x, y int, showCursor bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for row, line := range t.cells {
+		for col, cell := range line {
+			if cell.r == 0 {
+				continue
+			}
+			screen.SetContent(x+col, y+row, cell.r, nil, cell.style)
+		}
+	}
+
+	if showCursor && !t.cursorHidden {
+		screen.ShowCursor(x+t.x, y+t.y)
+	}
+}
+
+// text return the text of the screen, trailing spaces are trimmed
+func (t *vterm) text() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := make([]string, len(t.cells))
+	for y, line := range t.cells {
+		var b strings.Builder
+		for _, cell := range line {
+			if cell.r != 0 {
+				b.WriteRune(cell.r)
+			}
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// keyBytes convert the key event to the input of the terminal
+func keyBytes(event *tcell.EventKey, appCursor bool) []byte {
+	var b []byte
+	if event.Modifiers()&tcell.ModAlt != 0 {
+		b = append(b, 0x1b)
+	}
+
+	cursor := func(c byte) []byte {
+		if appCursor {
+			return append(b, 0x1b, 'O', c)
+		}
+		return append(b, 0x1b, '[', c)
+	}
+
+	switch key := event.Key(); key {
+	case tcell.KeyRune:
+		return append(b, string(event.Rune())...)
+	case tcell.KeyUp:
+		return cursor('A')
+	case tcell.KeyDown:
+		return cursor('B')
+	case tcell.KeyRight:
+		return cursor('C')
+	case tcell.KeyLeft:
+		return cursor('D')
+	case tcell.KeyHome:
+		return cursor('H')
+	case tcell.KeyEnd:
+		return cursor('F')
+	case tcell.KeyBacktab:
+		return append(b, "\x1b[Z"...)
+	case tcell.KeyF1, tcell.KeyF2, tcell.KeyF3, tcell.KeyF4:
+		return append(b, 0x1b, 'O', byte('P'+key-tcell.KeyF1))
+	default:
+		if seq, ok := tildeKeys[key]; ok {
+			return append(b, fmt.Sprintf("\x1b[%d~", seq)...)
+		}
+		// control keys are ASCII control codes, e.g. Ctrl+C is 0x03
+		if key < 0x80 {
+			return append(b, byte(key))
+		}
+	}
+	return nil
+}
+
+// tildeKeys keys sent as "ESC [ n ~"
+var tildeKeys = map[tcell.Key]int{
+	tcell.KeyInsert: 2,
+	tcell.KeyDelete: 3,
+	tcell.KeyPgUp:   5,
+	tcell.KeyPgDn:   6,
+	tcell.KeyF5:     15,
+	tcell.KeyF6:     17,
+	tcell.KeyF7:     18,
+	tcell.KeyF8:     19,
+	tcell.KeyF9:     20,
+	tcell.KeyF10:    21,
+	tcell.KeyF11:    23,
+	tcell.KeyF12:    24,
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
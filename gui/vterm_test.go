@@ -0,0 +1,94 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestVtermText(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"crlf", "foo\r\nbar", "foo\nbar"},
+		{"wrap", "abcdefghij", "abcdefgh\nij"},
+		{"scroll", "1\r\n2\r\n3\r\n4", "2\n3\n4"},
+		{"cursor position", "\x1b[2;3Hx", "\n  x"},
+		{"erase line", "abcdef\x1b[3D\x1b[K", "abc"},
+		{"clear screen", "abc\r\ndef\x1b[2J\x1b[Hx", "x"},
+		{"delete char", "abcdef\r\x1b[2P", "cdef"},
+		{"insert char", "abc\r\x1b[2@x", "x abc"},
+		{"colors are not printed", "\x1b[1;31mred\x1b[0m", "red"},
+		{"osc title", "\x1b]0;title\x07ok", "ok"},
+		{"utf-8", "日本", "日本"},
+		{"alternate screen", "main\x1b[?1049h\x1b[2Jalt\x1b[?1049l", "main"},
+		{"scroll region", "1\r\n2\r\n3\x1b[1;2r\x1b[2;1H\n", "2\n\n3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vt := newVterm(8, 3)
+			vt.Write([]byte(tt.input))
+			if got := vt.text(); got != tt.expect {
+				t.Errorf("Expected %q. Got %q.", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestVtermNewline(t *testing.T) {
+	vt := newVterm(8, 3)
+	vt.newline = true
+	vt.Write([]byte("foo\nbar"))
+	if got := vt.text(); got != "foo\nbar" {
+		t.Errorf("Expected LF as CRLF. Got %q.", got)
+	}
+}
+
+func TestVtermReplies(t *testing.T) {
+	vt := newVterm(8, 3)
+	vt.Write([]byte("ab\x1b[6n"))
+	if got := string(vt.takeReplies()); got != "\x1b[1;3R" {
+		t.Errorf("Expected cursor position report. Got %q.", got)
+	}
+	if got := vt.takeReplies(); len(got) != 0 {
+		t.Errorf("Expected replies are taken. Got %q.", got)
+	}
+}
+
+func TestVtermResize(t *testing.T) {
+	vt := newVterm(8, 3)
+	vt.Write([]byte("1\r\n2\r\n3"))
+	vt.resize(4, 2)
+	if got := vt.text(); got != "2\n3" {
+		t.Errorf("Expected the bottom lines are kept. Got %q.", got)
+	}
+	if w, h := vt.size(); w != 4 || h != 2 {
+		t.Errorf("Expected 4x2. Got %dx%d.", w, h)
+	}
+}
+
+func TestKeyBytes(t *testing.T) {
+	tests := []struct {
+		event     *tcell.EventKey
+		appCursor bool
+		expect    string
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), false, "a"},
+		{tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModAlt), false, "\x1ba"},
+		{tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl), false, "\x03"},
+		{tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), false, "\r"},
+		{tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), false, "\x1b[A"},
+		{tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), true, "\x1bOA"},
+		{tcell.NewEventKey(tcell.KeyDelete, 0, tcell.ModNone), false, "\x1b[3~"},
+		{tcell.NewEventKey(tcell.KeyF1, 0, tcell.ModNone), false, "\x1bOP"},
+	}
+
+	for _, tt := range tests {
+		if got := string(keyBytes(tt.event, tt.appCursor)); got != tt.expect {
+			t.Errorf("Expected %q. Got %q.", tt.expect, got)
+		}
+	}
+}
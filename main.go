@@ -23,6 +23,9 @@ var (
 	api      = flag.String("api", "1.39", "api version")
 	logFile  = flag.String("log", "", "log file path")
 	logLevel = flag.String("log-level", "info", "log level")
+	policy   = flag.String("policy", "", "cleanup policy json file path")
+	cleanup  = flag.Bool("cleanup", false, "remove resources matched by -policy without the ui and exit")
+	dryRun   = flag.Bool("dry-run", false, "list resources matched by -policy without removing them, with -cleanup")
 )
 
 func init() {
@@ -50,13 +53,18 @@ func init() {
 func run() int {
 	common.NewLogger(*logLevel, *logFile)
 
-	docker.NewDocker(docker.NewClientConfig(*endpoint, *cert, *key, *ca, *api))
-	if _, err := docker.Client.Info(context.TODO()); err != nil {
+	client := docker.NewDocker(docker.NewClientConfig(*endpoint, *cert, *key, *ca, *api))
+	if _, err := client.Info(context.TODO()); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
 
-	gui := gui.New()
+	if *cleanup {
+		return runCleanup(client, *policy, *dryRun, os.Stdout, os.Stderr)
+	}
+
+	gui := gui.New(client)
+	gui.SetCleanupPolicy(*policy)
 
 	if err := gui.Start(); err != nil {
 		common.Logger.Errorf("cannot start docui: %s", err)